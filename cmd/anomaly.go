@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/pfrederiksen/cost-blame/internal/anomaly"
-	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/output"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -20,21 +21,44 @@ var anomalyCmd = &cobra.Command{
 	Long: `Analyze historical cost data to identify anomalies using z-score analysis.
 Compares current costs against historical baseline (mean and standard deviation).
 
+Methods:
+  zscore   baseline is every earlier day
+  weekday  baseline is earlier days on the same day of the week
+  stl      baseline removes trend and day-of-week seasonality first
+
+Hourly mode (--granularity HOURLY) scores the latest complete hours against
+the same hours of the day over the last 14 days at most. The current, still
+accruing hour is never scored.
+
+Statistics:
+  stddev   mean and standard deviation
+  mad      median and median absolute deviation, robust to past spikes
+  iqr      median and interquartile range fencing
+
 Example:
-  cost-blame anomaly --historical-days 30 --threshold 2.0 --group-by service`,
+  cost-blame anomaly --historical-days 30 --threshold 2.0 --group-by service
+  cost-blame anomaly --method weekday --historical-days 56
+  cost-blame anomaly --statistic mad --anomalies-only
+  cost-blame anomaly --granularity HOURLY --recent-hours 3`,
 	RunE: runAnomaly,
 }
 
 func init() {
 	rootCmd.AddCommand(anomalyCmd)
 
-	anomalyCmd.Flags().String("group-by", "service", "Group by: service or linked_account")
-	anomalyCmd.Flags().Int("historical-days", 30, "Number of days of historical data to analyze")
+	anomalyCmd.Flags().String("group-by", "service", "Group by: service, linked_account, region, usage_type, tag, or two joined by a comma")
+	anomalyCmd.Flags().String("tag-key", "", "Tag key to group by (with --group-by tag, or as a second level)")
+	anomalyCmd.Flags().Int("historical-days", 30, "Number of days of historical data to analyze (hourly: at most 14, the default)")
+	anomalyCmd.Flags().String("granularity", "DAILY", "Granularity: DAILY or HOURLY")
+	anomalyCmd.Flags().Int("recent-hours", 1, "Number of latest complete hours scored together in hourly mode")
 	anomalyCmd.Flags().Float64("threshold", 2.0, "Z-score threshold for anomaly detection")
 	anomalyCmd.Flags().Int("min-data-points", 7, "Minimum data points required")
+	anomalyCmd.Flags().String("method", anomaly.MethodZScore, "Baseline method: zscore, weekday or stl")
+	anomalyCmd.Flags().String("statistic", anomaly.StatStdDev, "Baseline statistic: stddev, mad or iqr")
 	anomalyCmd.Flags().Int("top", 20, "Number of results to show")
 	anomalyCmd.Flags().Bool("anomalies-only", false, "Show only detected anomalies")
 	anomalyCmd.Flags().Bool("json", false, "Output as JSON")
+	addMetricFlag(anomalyCmd)
 }
 
 func runAnomaly(cmd *cobra.Command, args []string) error {
@@ -43,32 +67,61 @@ func runAnomaly(cmd *cobra.Command, args []string) error {
 
 	// Parse flags
 	groupBy, _ := cmd.Flags().GetString("group-by")
+	tagKey, _ := cmd.Flags().GetString("tag-key")
 	historicalDays, _ := cmd.Flags().GetInt("historical-days")
 	threshold, _ := cmd.Flags().GetFloat64("threshold")
 	minDataPoints, _ := cmd.Flags().GetInt("min-data-points")
+	method, _ := cmd.Flags().GetString("method")
+	statistic, _ := cmd.Flags().GetString("statistic")
+	granularity, _ := cmd.Flags().GetString("granularity")
+	recentHours, _ := cmd.Flags().GetInt("recent-hours")
 	topN, _ := cmd.Flags().GetInt("top")
 	anomaliesOnly, _ := cmd.Flags().GetBool("anomalies-only")
 	asJSON, _ := cmd.Flags().GetBool("json")
 
-	// Create AWS clients
-	clients, err := awsx.New(ctx, awsx.Options{
-		Profile: viper.GetString("profile"),
-		Region:  viper.GetString("region"),
-	})
+	metric, err := metricFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS clients: %w", err)
+		return err
 	}
 
-	// Detect anomalies
-	log.Info("analyzing historical cost data for anomalies...",
-		zap.Int("historical_days", historicalDays),
-		zap.Float64("z_score_threshold", threshold))
+	// Hourly mode defaults to all the hourly history Cost Explorer keeps
+	if strings.EqualFold(granularity, anomaly.GranularityHourly) && !cmd.Flags().Changed("historical-days") {
+		historicalDays = 0
+	}
+
+	loc, err := reportLocation()
+	if err != nil {
+		return err
+	}
 
-	results, err := anomaly.Detect(ctx, clients.CostExplorer, groupBy, anomaly.DetectorConfig{
+	config := anomaly.DetectorConfig{
 		HistoricalDays:  historicalDays,
 		ZScoreThreshold: threshold,
 		MinDataPoints:   minDataPoints,
-	})
+		TagKey:          tagKey,
+		Metric:          metric,
+		Method:          method,
+		Statistic:       statistic,
+		Granularity:     granularity,
+		RecentHours:     recentHours,
+		Location:        loc,
+	}
+
+	src, err := newCostSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Detect anomalies
+	log.Info("analyzing historical cost data for anomalies...",
+		zap.Int("historical_days", historicalDays),
+		zap.String("granularity", granularity),
+		zap.Float64("z_score_threshold", threshold),
+		zap.String("metric", metric),
+		zap.String("method", method),
+		zap.String("statistic", statistic))
+
+	results, err := anomaly.Detect(ctx, src, groupBy, config)
 	if err != nil {
 		return fmt.Errorf("anomaly detection failed: %w", err)
 	}
@@ -93,9 +146,17 @@ func runAnomaly(cmd *cobra.Command, args []string) error {
 		zap.Int("total_results", len(results)),
 		zap.Int("anomalies", countAnomalies(results)))
 
+	// Scored hours are reported in the reporting timezone; scored days stay
+	// the UTC days Cost Explorer bucketed them in
+	for i := range results {
+		if results[i].Method == anomaly.MethodHourOfDay {
+			results[i].Period = results[i].Period.In(loc)
+		}
+	}
+
 	// Output results
 	if asJSON {
-		return printAnomaliesJSON(results)
+		return printAnomaliesJSON(results, metric, loc)
 	}
 	return printAnomaliesTable(results)
 }
@@ -106,8 +167,26 @@ func printAnomaliesTable(anomalies []anomaly.Anomaly) error {
 		return nil
 	}
 
+	metric := anomalies[0].Metric
+	statistic := anomalies[0].Statistic
+	fmt.Printf("Metric: %s, Method: %s, Statistic: %s\n", metric, anomalies[0].Method, statistic)
+	if anomalies[0].Method == anomaly.MethodHourOfDay {
+		fmt.Printf("Scored hours from %s\n", anomalies[0].Period.Format("2006-01-02 15:04 MST"))
+	} else {
+		fmt.Println("Days are UTC days, as bucketed by AWS")
+	}
+
+	// Robust spreads are scaled to be comparable to a standard deviation
+	centerLabel, spreadLabel := "Mean", "Std Dev"
+	switch statistic {
+	case anomaly.StatMAD:
+		centerLabel, spreadLabel = "Median", "MAD (scaled)"
+	case anomaly.StatIQR:
+		centerLabel, spreadLabel = "Median", "IQR (scaled)"
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Key", "Current", "Mean", "Std Dev", "Z-Score", "Deviation %", "Severity"})
+	table.SetHeader([]string{"Key", "Current", centerLabel, spreadLabel, "Z-Score", "Deviation %", "Severity"})
 	table.SetBorder(true)
 	table.SetAutoWrapText(false)
 
@@ -119,9 +198,9 @@ func printAnomaliesTable(anomalies []anomaly.Anomaly) error {
 
 		table.Append([]string{
 			a.Key,
-			fmt.Sprintf("$%.2f", a.CurrentCost),
-			fmt.Sprintf("$%.2f", a.HistoricalMean),
-			fmt.Sprintf("$%.2f", a.HistoricalStdDev),
+			cost.FormatAmount(metric, a.CurrentCost),
+			cost.FormatAmount(metric, a.HistoricalMean),
+			cost.FormatAmount(metric, a.HistoricalStdDev),
 			fmt.Sprintf("%.2f", a.ZScore),
 			fmt.Sprintf("%.1f%%", a.PercentDeviation),
 			severity,
@@ -132,8 +211,10 @@ func printAnomaliesTable(anomalies []anomaly.Anomaly) error {
 	return nil
 }
 
-func printAnomaliesJSON(anomalies []anomaly.Anomaly) error {
+func printAnomaliesJSON(anomalies []anomaly.Anomaly, metric string, loc *time.Location) error {
 	return output.PrintJSON(os.Stdout, map[string]interface{}{
+		"metric":    metric,
+		"timezone":  loc.String(),
 		"anomalies": anomalies,
 		"count":     len(anomalies),
 	})
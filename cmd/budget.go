@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pfrederiksen/cost-blame/internal/budget"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/export"
+	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Exit codes returned by budget so pipelines can gate on the outcome
+const (
+	exitBudgetWarn   = 2
+	exitBudgetBreach = 3
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Evaluate monthly budgets from the config file",
+	Long: `Evaluate month-to-date and projected month-end spend against the budgets
+defined under "budgets" in the config file. Each budget can be scoped to a
+service, account, tag value, or any combination.
+
+Exit codes: 0 when all budgets are OK, 2 when any budget is WARN,
+3 when any budget is BREACH, 1 on errors.
+
+Example config (~/.cost-blame.yaml):
+  budgets:
+    - name: ec2-prod
+      amount: 5000
+      service: Amazon Elastic Compute Cloud - Compute
+      account: "123456789012"
+    - name: data-team
+      amount: 12000
+      warn_at: 0.9
+      tag_key: team
+      tag_value: data
+      metric: AmortizedCost
+
+Example:
+  cost-blame budget --slack-webhook $SLACK_WEBHOOK`,
+	RunE: runBudget,
+}
+
+func init() {
+	rootCmd.AddCommand(budgetCmd)
+
+	budgetCmd.Flags().StringSlice("name", nil, "Only evaluate these budgets (comma-separated)")
+	budgetCmd.Flags().Bool("json", false, "Output as JSON")
+	budgetCmd.Flags().String("slack-webhook", "", "Send breached budgets to Slack webhook URL")
+}
+
+func runBudget(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	log := getLogger()
+
+	// Parse flags
+	names, _ := cmd.Flags().GetStringSlice("name")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	slackWebhook, _ := cmd.Flags().GetString("slack-webhook")
+
+	var defs []budget.Definition
+	if err := viper.UnmarshalKey("budgets", &defs); err != nil {
+		return fmt.Errorf("invalid budgets in config: %w", err)
+	}
+	if len(defs) == 0 {
+		return fmt.Errorf("no budgets defined; add a \"budgets\" list to the config file")
+	}
+	defs, err := filterBudgets(defs, names)
+	if err != nil {
+		return err
+	}
+
+	// Budgets without their own metric use the configured default
+	for i := range defs {
+		if defs[i].Metric == "" {
+			defs[i].Metric = viper.GetString("metric")
+		}
+	}
+
+	src, err := newCostSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info("evaluating budgets...", zap.Int("count", len(defs)))
+	today, err := reportToday()
+	if err != nil {
+		return err
+	}
+	results, err := budget.Evaluate(ctx, src, defs, today)
+	if err != nil {
+		return fmt.Errorf("budget evaluation failed: %w", err)
+	}
+
+	worst := budget.Worst(results)
+
+	if slackWebhook != "" && worst == budget.StatusBreach {
+		if err := export.SendBudgetsToSlack(slackWebhook, results); err != nil {
+			log.Warn("failed to send to Slack", zap.Error(err))
+		} else {
+			log.Info("sent budget breach alert to Slack")
+		}
+	}
+
+	// Output results
+	if asJSON {
+		err = output.PrintJSON(os.Stdout, map[string]interface{}{
+			"status":  worst,
+			"budgets": results,
+		})
+	} else {
+		err = printBudgetTable(results)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The report is already printed; only the exit code is left to set
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	switch worst {
+	case budget.StatusBreach:
+		return &ExitError{Code: exitBudgetBreach, Err: fmt.Errorf("budget status: %s", worst)}
+	case budget.StatusWarn:
+		return &ExitError{Code: exitBudgetWarn, Err: fmt.Errorf("budget status: %s", worst)}
+	}
+	return nil
+}
+
+// filterBudgets keeps the budgets named, or all of them when no names are
+// given. Names matching no budget are an error listing the defined ones.
+func filterBudgets(defs []budget.Definition, names []string) ([]budget.Definition, error) {
+	if len(names) == 0 {
+		return defs, nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[n] = true
+	}
+
+	var filtered []budget.Definition
+	defined := make([]string, len(defs))
+	for i, d := range defs {
+		defined[i] = d.Name
+		if wanted[d.Name] {
+			filtered = append(filtered, d)
+			delete(wanted, d.Name)
+		}
+	}
+
+	if len(wanted) > 0 {
+		var unknown []string
+		for _, n := range names {
+			if wanted[n] {
+				unknown = append(unknown, n)
+				delete(wanted, n)
+			}
+		}
+		return nil, fmt.Errorf("unknown budgets: %s (defined: %s)", strings.Join(unknown, ", "), strings.Join(defined, ", "))
+	}
+	return filtered, nil
+}
+
+func printBudgetTable(results []budget.Result) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Budget", "Scope", "Amount", "Month to Date", "Projected", "Status", "Reason"})
+	table.SetBorder(true)
+	table.SetAutoWrapText(false)
+
+	for _, r := range results {
+		table.Append([]string{
+			r.Name,
+			r.Scope,
+			cost.FormatAmount(r.Metric, r.Amount),
+			fmt.Sprintf("%s (%.0f%%)", cost.FormatAmount(r.Metric, r.Actual), r.PercentUsed),
+			fmt.Sprintf("%s (%.0f%%)", cost.FormatAmount(r.Metric, r.Projected), r.PercentProjected),
+			string(r.Status),
+			r.Reason,
+		})
+	}
+
+	table.Render()
+	return nil
+}
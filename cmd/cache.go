@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pfrederiksen/cost-blame/internal/cache"
+	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the local Cost Explorer response cache",
+	Long: `Cost Explorer responses are cached under ~/.cache/cost-blame so repeated
+runs over the same period do not pay for identical API calls. Closed days
+never expire; periods that include today expire after a short TTL.
+
+Example:
+  cost-blame cache info
+  cost-blame cache prune
+  cost-blame cache clear`,
+}
+
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "List cached responses",
+	RunE:  runCacheInfo,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired cache entries",
+	RunE:  runCachePrune,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cache entries",
+	RunE:  runCacheClear,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheInfoCmd, cachePruneCmd, cacheClearCmd)
+
+	cacheInfoCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+// openCache returns the cache at the configured directory. The namespace is
+// only needed for lookups, so management commands leave it empty.
+func openCache(namespace string) (*cache.Cache, error) {
+	dir := viper.GetString("cache_dir")
+	if dir == "" {
+		var err error
+		dir, err = cache.DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cache.New(dir, namespace, viper.GetDuration("cache_ttl")), nil
+}
+
+func runCacheInfo(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	c, err := openCache("")
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	return output.PrintCacheEntries(entries, c.Dir(), asJSON)
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	c, err := openCache("")
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.Prune()
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+
+	fmt.Printf("Removed %d expired entries from %s\n", removed, c.Dir())
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	c, err := openCache("")
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.Clear()
+	if err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+
+	fmt.Printf("Removed %d entries from %s\n", removed, c.Dir())
+	return nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+	"github.com/pfrederiksen/cost-blame/internal/changepoint"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var changepointsCmd = &cobra.Command{
+	Use:   "changepoints",
+	Short: "Find the days on which each group's cost level shifted",
+	Long: `Segment each group's daily cost into runs of constant level with PELT
+changepoint detection, and report when every lasting shift started, the
+levels before and after it, and its monthly run-rate impact.
+
+Unlike anomaly, a shift keeps being reported after it becomes the new baseline.
+
+Example:
+  cost-blame changepoints --history-days 90 --min-impact 500
+  cost-blame changepoints --group-by linked_account --penalty 2`,
+	RunE: runChangepoints,
+}
+
+func init() {
+	rootCmd.AddCommand(changepointsCmd)
+
+	changepointsCmd.Flags().String("group-by", "service", "Group by: service, linked_account, region, usage_type, tag, or two joined by a comma")
+	changepointsCmd.Flags().String("tag-key", "", "Tag key to group by (with --group-by tag, or as a second level)")
+	changepointsCmd.Flags().Int("history-days", 90, "Number of days of history to segment")
+	changepointsCmd.Flags().Int("min-segment", 7, "Minimum number of days at a level")
+	changepointsCmd.Flags().Float64("penalty", 1.0, "Changepoint penalty multiplier (higher finds fewer shifts)")
+	changepointsCmd.Flags().Float64("min-impact", 0, "Minimum absolute monthly impact to report")
+	changepointsCmd.Flags().Int("top", 20, "Number of results to show")
+	changepointsCmd.Flags().Bool("json", false, "Output as JSON")
+	addMetricFlag(changepointsCmd)
+}
+
+func runChangepoints(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	log := getLogger()
+
+	// Parse flags
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	tagKey, _ := cmd.Flags().GetString("tag-key")
+	historyDays, _ := cmd.Flags().GetInt("history-days")
+	minSegment, _ := cmd.Flags().GetInt("min-segment")
+	penalty, _ := cmd.Flags().GetFloat64("penalty")
+	minImpact, _ := cmd.Flags().GetFloat64("min-impact")
+	topN, _ := cmd.Flags().GetInt("top")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	metric, err := metricFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	if minSegment < 1 {
+		return fmt.Errorf("--min-segment must be at least 1")
+	}
+	if penalty <= 0 {
+		return fmt.Errorf("--penalty must be positive")
+	}
+
+	today, err := reportToday()
+	if err != nil {
+		return err
+	}
+
+	config := changepoint.Config{
+		Today:          today,
+		HistoryDays:    historyDays,
+		MinSegmentDays: minSegment,
+		Penalty:        penalty,
+		MinImpact:      minImpact,
+		Metric:         metric,
+	}
+
+	groupDefs, err := cost.GroupDefinitions(groupBy, tagKey)
+	if err != nil {
+		return err
+	}
+
+	src, err := newCostSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	start, end := config.FetchRange()
+	log.Info("fetching daily cost history...",
+		zap.Time("start", start),
+		zap.Time("end", end),
+		zap.String("metric", metric))
+
+	series, err := anomaly.FetchDaily(ctx, src, groupDefs, start, end, metric)
+	if err != nil {
+		return err
+	}
+
+	shifts := changepoint.Detect(series, config)
+
+	// Limit results
+	if topN > 0 && len(shifts) > topN {
+		shifts = shifts[:topN]
+	}
+
+	log.Debug("changepoint detection complete", zap.Int("shifts", len(shifts)))
+
+	// Output results
+	if asJSON {
+		return output.PrintJSON(os.Stdout, map[string]interface{}{
+			"metric": metric,
+			"shifts": shifts,
+			"count":  len(shifts),
+		})
+	}
+	return printShiftsTable(shifts, metric)
+}
+
+func printShiftsTable(shifts []changepoint.Shift, metric string) error {
+	if len(shifts) == 0 {
+		fmt.Println("No level shifts detected")
+		return nil
+	}
+
+	fmt.Printf("Metric: %s\n", metric)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Key", "Shift Date", "Before/Day", "After/Day", "Change %", "Monthly Impact"})
+	table.SetBorder(true)
+	table.SetAutoWrapText(false)
+
+	for _, s := range shifts {
+		pctStr := fmt.Sprintf("%.1f%%", s.PercentChange)
+		if s.PercentChange >= 9999 {
+			pctStr = "NEW"
+		}
+
+		table.Append([]string{
+			s.Key,
+			s.Date.Format("2006-01-02"),
+			cost.FormatAmount(metric, s.Before),
+			cost.FormatAmount(metric, s.After),
+			pctStr,
+			cost.FormatAmount(metric, s.MonthlyImpact),
+		})
+	}
+
+	table.Render()
+	return nil
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/budget"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/forecast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// fixtureSource serves a fixed daily series per key for any request range
+type fixtureSource struct {
+	daily map[string][]float64 // oldest first; last entry is yesterday
+}
+
+func (f fixtureSource) GetCosts(ctx context.Context, req cost.CostRequest) ([]cost.TimeBucket, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var buckets []cost.TimeBucket
+	for day := req.Start.Truncate(24 * time.Hour); day.Before(req.End); day = day.AddDate(0, 0, 1) {
+		bucket := cost.TimeBucket{Start: day, End: day.AddDate(0, 0, 1)}
+		for key, series := range f.daily {
+			i := len(series) - int(today.Sub(day).Hours()/24)
+			if i < 0 || i >= len(series) {
+				continue
+			}
+			metrics := make(map[string]float64)
+			for _, m := range req.Metrics {
+				metrics[m] = series[i]
+			}
+			bucket.Groups = append(bucket.Groups, cost.GroupCost{Keys: []string{key}, Metrics: metrics})
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// resetFlags restores every flag to its default, since cobra keeps parsed
+// values between executions of the same command tree
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// runCommand executes the root command with a fixture cost source and
+// returns what it printed to stdout
+func runCommand(t *testing.T, src cost.Source, args ...string) string {
+	t.Helper()
+
+	orig := newCostSource
+	newCostSource = func(ctx context.Context) (cost.Source, error) { return src, nil }
+	defer func() { newCostSource = orig }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(args)
+	runErr := rootCmd.Execute()
+
+	w.Close()
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+
+	if runErr != nil {
+		t.Fatalf("%v failed: %v", args, runErr)
+	}
+	return buf.String()
+}
+
+func fixtureSeries() fixtureSource {
+	ec2 := make([]float64, 14)
+	s3 := make([]float64, 14)
+	for i := range ec2 {
+		ec2[i] = 100
+		s3[i] = 10
+		if i >= 7 {
+			ec2[i] = 300 // EC2 triples in the most recent week
+		}
+	}
+	ec2[13] = 900
+	return fixtureSource{daily: map[string][]float64{"AmazonEC2": ec2, "AmazonS3": s3}}
+}
+
+func TestSpikeCommand_EndToEnd(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "spike", "--last", "7d", "--json", "--top", "1")
+
+	if !strings.Contains(out, `"Key": "AmazonEC2"`) {
+		t.Errorf("expected EC2 to be the top spike, got:\n%s", out)
+	}
+	if strings.Contains(out, "AmazonS3") {
+		t.Errorf("--top 1 should limit output to one delta, got:\n%s", out)
+	}
+}
+
+func TestBlameCommand_Metric(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "blame", "--last", "7d", "--tag-key", "team", "--metric", "amortizedcost", "--json")
+
+	if !strings.Contains(out, `"metric": "AmortizedCost"`) || !strings.Contains(out, `"Metric": "AmortizedCost"`) {
+		t.Errorf("expected output labeled with AmortizedCost, got:\n%s", out)
+	}
+}
+
+func TestAnomalyCommand_EndToEnd(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "anomaly", "--historical-days", "14", "--anomalies-only", "--json")
+
+	if !strings.Contains(out, `"Key": "AmazonEC2"`) || !strings.Contains(out, `"IsAnomaly": true`) {
+		t.Errorf("expected EC2 anomaly, got:\n%s", out)
+	}
+}
+
+func TestCacheCommands(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	c, err := openCache("111111111111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -7)
+	c.Wrap(fixtureSeries()).GetCosts(context.Background(), cost.CostRequest{Start: end.AddDate(0, 0, -7), End: end})
+
+	out := runCommand(t, fixtureSeries(), "cache", "info")
+	if !strings.Contains(out, "111111111111") || !strings.Contains(out, "never") {
+		t.Errorf("cache info should list the closed-period entry, got:\n%s", out)
+	}
+
+	out = runCommand(t, fixtureSeries(), "cache", "clear")
+	if !strings.Contains(out, "Removed 1 entries") {
+		t.Errorf("unexpected cache clear output:\n%s", out)
+	}
+}
+
+// fakeForecastClient answers every Cost Explorer forecast with $1000
+type fakeForecastClient struct{}
+
+func (fakeForecastClient) GetCostForecast(ctx context.Context, params *costexplorer.GetCostForecastInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostForecastOutput, error) {
+	return &costexplorer.GetCostForecastOutput{Total: &cetypes.MetricValue{Amount: aws.String("1000")}}, nil
+}
+
+func TestForecastCommand_CompareCE(t *testing.T) {
+	orig := newForecastClient
+	newForecastClient = func(ctx context.Context) (forecast.ForecastAPI, error) { return fakeForecastClient{}, nil }
+	defer func() { newForecastClient = orig }()
+
+	out := runCommand(t, fixtureSeries(), "forecast", "--compare-ce", "--json", "--top", "1")
+
+	for _, want := range []string{`"Key": "Total"`, `"Key": "AmazonEC2"`, `"Period": "quarter"`, `"CostExplorer": {`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("forecast output missing %s, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `"Key": "AmazonS3"`) {
+		t.Errorf("--top 1 should keep only the largest group, got:\n%s", out)
+	}
+}
+
+func TestForecastCommand_CompareCEConfidence(t *testing.T) {
+	resetFlags(rootCmd)
+	rootCmd.SetArgs([]string{"forecast", "--compare-ce", "--confidence", "0.5"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "between 0.51 and 0.99") {
+		t.Errorf("forecast --compare-ce --confidence 0.5 error = %v, want the accepted range", err)
+	}
+	resetFlags(rootCmd)
+}
+
+func TestBudgetCommand_ExitCodes(t *testing.T) {
+	// fixtureSeries spends about $310/day
+	tests := []struct {
+		amount float64
+		code   int
+	}{
+		{1e9, 0},
+		{1, exitBudgetBreach},
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	stdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout }()
+
+	for _, tt := range tests {
+		viper.Set("budgets", []map[string]interface{}{{"name": "all", "amount": tt.amount}})
+
+		orig := newCostSource
+		newCostSource = func(ctx context.Context) (cost.Source, error) { return fixtureSeries(), nil }
+
+		rootCmd.SetArgs([]string{"budget", "--json"})
+		err := rootCmd.Execute()
+		newCostSource = orig
+
+		var exitErr *ExitError
+		switch {
+		case tt.code == 0 && err != nil:
+			t.Errorf("amount %v: unexpected error %v", tt.amount, err)
+		case tt.code != 0 && (!errors.As(err, &exitErr) || exitErr.Code != tt.code):
+			t.Errorf("amount %v: error = %v, want exit code %d", tt.amount, err, tt.code)
+		}
+	}
+	viper.Set("budgets", nil)
+}
+
+func TestFilterBudgets(t *testing.T) {
+	defs := []budget.Definition{{Name: "all"}, {Name: "platform"}, {Name: "data"}}
+
+	got, err := filterBudgets(defs, []string{"data", "all"})
+	if err != nil || len(got) != 2 || got[0].Name != "all" || got[1].Name != "data" {
+		t.Errorf("filterBudgets(data, all) = %v, %v, want all and data", got, err)
+	}
+
+	_, err = filterBudgets(defs, []string{"platfrom", "all"})
+	if err == nil || err.Error() != "unknown budgets: platfrom (defined: all, platform, data)" {
+		t.Errorf("filterBudgets(platfrom) error = %v, want the unknown and defined names", err)
+	}
+}
+
+func TestCostScope(t *testing.T) {
+	clients := &awsx.Clients{Config: aws.Config{Region: "us-east-1"}, Account: "111111111111"}
+
+	tests := []struct {
+		name     string
+		targets  []awsx.Target
+		account  string
+		regions  string
+		accounts string
+	}{
+		{
+			name:     "own account and region",
+			targets:  awsx.Targets(nil, nil),
+			account:  "111111111111",
+			regions:  "us-east-1",
+			accounts: "111111111111",
+		},
+		{
+			name:     "member accounts and regions",
+			targets:  awsx.Targets([]string{"222222222222", "333333333333"}, []string{"us-west-2", "eu-west-1"}),
+			account:  "111111111111",
+			regions:  "us-west-2,eu-west-1",
+			accounts: "222222222222,333333333333",
+		},
+		{
+			name:     "unknown caller account",
+			targets:  awsx.Targets(nil, []string{"us-west-2"}),
+			regions:  "us-west-2",
+			accounts: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients.Account = tt.account
+			regions, accounts := costScope(tt.targets, clients)
+			if strings.Join(regions, ",") != tt.regions || strings.Join(accounts, ",") != tt.accounts {
+				t.Errorf("costScope() = %v, %v, want %s and %s", regions, accounts, tt.regions, tt.accounts)
+			}
+		})
+	}
+}
+
+func TestAnomalyCommand_Statistic(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "anomaly", "--historical-days", "14", "--statistic", "mad", "--json=false")
+
+	if !strings.Contains(out, "Statistic: mad") || !strings.Contains(out, "MAD (SCALED)") {
+		t.Errorf("expected table labeled with the MAD statistic, got:\n%s", out)
+	}
+}
+
+func TestChangepointsCommand_EndToEnd(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "changepoints", "--history-days", "14", "--min-segment", "3", "--json")
+
+	shiftDay := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -7).Format("2006-01-02")
+	if !strings.Contains(out, `"Key": "AmazonEC2"`) || !strings.Contains(out, shiftDay) {
+		t.Errorf("expected EC2 shift on %s, got:\n%s", shiftDay, out)
+	}
+	if strings.Contains(out, "AmazonS3") {
+		t.Errorf("flat S3 series should have no shifts, got:\n%s", out)
+	}
+}
+
+func TestSpikeCommand_AbsoluteWindow(t *testing.T) {
+	// The fixture's EC2 cost triples in the most recent week
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -7).Format("2006-01-02")
+	to := today.AddDate(0, 0, -2).Format("2006-01-02")
+
+	out := runCommand(t, fixtureSeries(), "spike", "--from", from, "--to", to,
+		"--compare-from", today.AddDate(0, 0, -14).Format("2006-01-02"),
+		"--compare-to", today.AddDate(0, 0, -9).Format("2006-01-02"), "--json")
+
+	if !strings.Contains(out, `"CurrentCost": 1800`) || !strings.Contains(out, `"PriorCost": 600`) {
+		t.Errorf("expected six days at $300 vs six days at $100 for EC2, got:\n%s", out)
+	}
+}
+
+func TestSpikeCommand_CompareWoW(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "spike", "--last", "3d", "--compare", "wow", "--json")
+
+	// The last three days (300, 300, 900) against the same days a week earlier
+	if !strings.Contains(out, `"CurrentCost": 1500`) || !strings.Contains(out, `"PriorCost": 300`) {
+		t.Errorf("expected EC2 $1500 against $300 a week earlier, got:\n%s", out)
+	}
+}
+
+func TestSpikeCommand_Timezone(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "spike", "--last", "7d", "--timezone", "America/Los_Angeles", "--json")
+
+	if !strings.Contains(out, `"timezone": "America/Los_Angeles"`) || !strings.Contains(out, `"bucketing": "UTC days, as bucketed by AWS`) {
+		t.Errorf("expected the period labeled with the timezone and UTC bucketing, got:\n%s", out)
+	}
+}
+
+func TestSpikeCommand_Series(t *testing.T) {
+	out := runCommand(t, fixtureSeries(), "spike", "--last", "7d", "--series", "--top", "1", "--json=false")
+
+	// Six days at $300 and yesterday at $900 after a week at $100
+	if !strings.Contains(out, "▂▂▂▂▂▂▂│▃▃▃▃▃▃█") {
+		t.Errorf("expected an EC2 sparkline of a sustained ramp and one bad day, got:\n%s", out)
+	}
+}
+
+func TestInvestigateCommand_NoSpikes(t *testing.T) {
+	// No delta reaches the threshold, so no resources are searched
+	out := runCommand(t, fixtureSeries(), "investigate", "--last", "7d", "--group-by", "service", "--threshold", "100000", "--markdown")
+
+	if !strings.Contains(out, "# Cost investigation") || !strings.Contains(out, "No cost changes found") {
+		t.Errorf("expected an empty Markdown report, got:\n%s", out)
+	}
+}
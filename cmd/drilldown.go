@@ -3,10 +3,15 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/inventory"
 	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -18,10 +23,34 @@ var drilldownCmd = &cobra.Command{
 	Long: `Drill down from a service-level cost spike to identify specific resources
 that may be responsible for the cost change.
 
-Supported services: AmazonEC2, AmazonRDS (with fallbacks via Tagging API for others)
+SERVICE is a service as spike --group-by service prints it, such as
+"Amazon Elastic Compute Cloud - Compute" or "EC2 - Other", a product code
+such as AmazonEC2, or a short name such as ec2. EC2, RDS, Lambda, S3,
+CloudFront, ECS and EKS have dedicated finders; other supported services
+are searched through the Tagging API. Unknown names list the supported ones.
+
+--accounts and --all-accounts search member accounts through a role assumed
+in each of them (--role-template, default
+arn:aws:iam::{account}:role/CostBlameReadOnly). --regions searches several
+regions, or every enabled region with "all". Accounts and regions that can't
+be searched are reported and skipped.
+
+Each resource is shown with its cost in the current and prior periods from
+Cost Explorer's resource-level data, which covers the last 14 days and must
+be enabled in the Cost Explorer settings. Resources are ordered by delta;
+billed resources that no longer exist are marked billing-only, and resources
+without cost inventory-only. --no-costs skips the lookup.
+
+--creators looks up who created each resource in CloudTrail event history,
+which covers the last 90 days, and flags resources created in the current
+period. Lookups are rate limited by CloudTrail, so at most 50 resources are
+looked up per account and region.
 
 Example:
-  cost-blame drilldown AmazonEC2 --last 48h --region us-west-2 --tag-key team`,
+  cost-blame drilldown AmazonEC2 --last 48h --region us-west-2 --tag-key team
+  cost-blame drilldown "EC2 - Other" --regions all
+  cost-blame drilldown AmazonRDS --last 7d --creators
+  cost-blame drilldown AmazonEC2 --all-accounts --role-template 'arn:aws:iam::{account}:role/Audit'`,
 	Args: cobra.ExactArgs(1),
 	RunE: runDrilldown,
 }
@@ -30,53 +59,321 @@ func init() {
 	rootCmd.AddCommand(drilldownCmd)
 
 	drilldownCmd.Flags().String("last", "48h", "Time window (48h, 7d, 30d)")
-	drilldownCmd.Flags().String("account", "", "Filter to specific account ID")
+	addSearchFlags(drilldownCmd)
 	drilldownCmd.Flags().String("tag-key", "", "Filter by tag key")
+	drilldownCmd.Flags().Bool("no-costs", false, "Skip resource-level costs from Cost Explorer")
+	drilldownCmd.Flags().Bool("creators", false, "Look up who created each resource in CloudTrail")
+	addMetricFlag(drilldownCmd)
 	drilldownCmd.Flags().Bool("json", false, "Output as JSON")
+
+	viper.BindPFlag("role_template", drilldownCmd.Flags().Lookup("role-template"))
 }
 
+// maxInventoryWorkers bounds how many accounts and regions are searched at
+// once
+const maxInventoryWorkers = 8
+
+// maxCreatorLookups bounds how many resources are looked up in CloudTrail
+// per account and region, at about two lookups a second
+const maxCreatorLookups = 50
+
+// cloudTrailGlobalRegion is where CloudTrail records the calls of global
+// services such as CloudFront
+const cloudTrailGlobalRegion = "us-east-1"
+
 func runDrilldown(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	log := getLogger()
 
 	service := args[0]
+	svc, err := inventory.LookupService(service)
+	if err != nil {
+		return err
+	}
 
 	// Parse flags
 	region := viper.GetString("region")
 	tagKey, _ := cmd.Flags().GetString("tag-key")
 	asJSON, _ := cmd.Flags().GetBool("json")
+	roleTemplate := roleTemplateFlag(cmd)
+	last, _ := cmd.Flags().GetString("last")
+	noCosts, _ := cmd.Flags().GetBool("no-costs")
+	creators, _ := cmd.Flags().GetBool("creators")
+
+	metric, err := metricFlag(cmd)
+	if err != nil {
+		return err
+	}
+	loc, err := reportLocation()
+	if err != nil {
+		return err
+	}
+	window, err := timewin.Build(timewin.Spec{Last: last, Location: loc})
+	if err != nil {
+		return fmt.Errorf("invalid time window: %w", err)
+	}
 
 	log.Info("drilling down into service",
 		zap.String("service", service),
 		zap.String("region", region))
 
-	// Create AWS clients
+	clients, err := inventoryClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err := searchTargets(ctx, cmd, clients)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu        sync.Mutex
+		resources []inventory.Resource
+	)
+	failures := clients.ForEach(ctx, targets, roleTemplate, maxInventoryWorkers,
+		func(ctx context.Context, target awsx.Target, clients *awsx.Clients) error {
+			found, err := findResources(ctx, clients, service, tagKey)
+			mu.Lock()
+			resources = append(resources, found...)
+			mu.Unlock()
+			return err
+		})
+
+	var errs []error
+	for _, f := range failures {
+		log.Warn("partial results due to error",
+			zap.String("account", f.Account),
+			zap.String("region", f.Region),
+			zap.Error(f.Err))
+		errs = append(errs, f)
+	}
+
+	resources = inventory.Merge(resources)
+
+	log.Debug("found resources", zap.Int("count", len(resources)))
+
+	if !noCosts {
+		costs, err := resourceCosts(ctx, clients, svc, metric, window, targets, timewin.Today(time.Now(), loc))
+		if err != nil {
+			log.Warn("resource costs unavailable", zap.Error(err))
+			errs = append(errs, fmt.Errorf("resource costs: %w", err))
+		} else {
+			resources = inventory.AttachCosts(resources, costs)
+		}
+	}
+
+	if creators {
+		log.Info("looking up resource creators in CloudTrail...")
+		errs = append(errs, annotateCreators(ctx, clients, resources, roleTemplate, window.CurrentStart)...)
+	}
+
+	// Output results
+	return output.PrintResources(resources, service, asJSON, errs...)
+}
+
+// addSearchFlags adds the flags choosing the accounts and regions searched
+// for resources
+func addSearchFlags(cmd *cobra.Command) {
+	cmd.Flags().String("account", "", "Filter to specific account ID")
+	cmd.Flags().StringSlice("accounts", nil, "Search these member accounts (comma-separated)")
+	cmd.Flags().Bool("all-accounts", false, "Search every active account in the organization")
+	cmd.Flags().StringSlice("regions", nil, "Search these regions (comma-separated), or all enabled regions with \"all\" (default: --region)")
+	cmd.Flags().String("role-template", awsx.DefaultRoleTemplate, "Role assumed in each account; {account} is replaced by the account ID")
+}
+
+// inventoryClients creates clients for the configured profile and region,
+// labeled with the caller's own account when it can be resolved
+func inventoryClients(ctx context.Context) (*awsx.Clients, error) {
 	clients, err := awsx.New(ctx, awsx.Options{
 		Profile: viper.GetString("profile"),
-		Region:  region,
+		Region:  viper.GetString("region"),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create AWS clients: %w", err)
-	}
-
-	// Find resources
-	finder := inventory.NewFinder(
-		clients.Tagging,
-		clients.EC2,
-		clients.RDS,
-		clients.Lambda,
-		clients.S3,
-		clients.CloudFront,
-		clients.ECS,
-		clients.EKS,
-	)
+		return nil, fmt.Errorf("failed to create AWS clients: %w", err)
+	}
+
+	// The caller's own account needs no role
+	if self, err := clients.CallerAccount(ctx); err == nil {
+		clients.Account = self
+	} else {
+		getLogger().Debug("could not resolve caller account", zap.Error(err))
+	}
+	return clients, nil
+}
+
+// searchTargets resolves the search flags into the account and region
+// pairs to search
+func searchTargets(ctx context.Context, cmd *cobra.Command, clients *awsx.Clients) ([]awsx.Target, error) {
+	log := getLogger()
+
+	account, _ := cmd.Flags().GetString("account")
+	accounts, _ := cmd.Flags().GetStringSlice("accounts")
+	allAccounts, _ := cmd.Flags().GetBool("all-accounts")
+	regions, _ := cmd.Flags().GetStringSlice("regions")
+
+	if account != "" {
+		accounts = append(accounts, account)
+	}
+
+	var err error
+	if allAccounts {
+		log.Info("fetching all accounts from organization...")
+		accounts, err = clients.ListAccounts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization accounts: %w", err)
+		}
+	}
+
+	if len(regions) == 1 && strings.EqualFold(regions[0], "all") {
+		regions, err = clients.ListRegions(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targets := awsx.Targets(accounts, regions)
+	if len(targets) > 1 {
+		log.Info("searching accounts and regions",
+			zap.Int("accounts", len(accounts)),
+			zap.Int("regions", len(regions)),
+			zap.String("role_template", roleTemplateFlag(cmd)))
+	}
+	return targets, nil
+}
+
+// findResources searches the account and region of the clients and labels
+// every resource with the account
+func findResources(ctx context.Context, clients *awsx.Clients, service, tagKey string) ([]inventory.Resource, error) {
+	region := clients.Config.Region
+	finder := inventory.NewFinderFromConfig(clients.Config)
 	resources, err := finder.FindByService(ctx, service, region, tagKey)
+	for i := range resources {
+		if resources[i].Account == "" {
+			resources[i].Account = clients.Account
+		}
+	}
+	return resources, err
+}
+
+// annotateCreators looks up the creators of the resources found, in the
+// account and region of each, flagging those created since the current
+// period started. Billing-only resources weren't found, so they are
+// skipped. Resources are looked up in order, so with costs the largest
+// deltas come first.
+func annotateCreators(ctx context.Context, clients *awsx.Clients, resources []inventory.Resource, roleTemplate string, since time.Time) []error {
+	places := make(map[awsx.Target][]int)
+	var order []awsx.Target
+	for i, r := range resources {
+		if r.Billing == inventory.BillingOnly {
+			continue
+		}
+		region := r.Region
+		if region == "global" {
+			region = cloudTrailGlobalRegion
+		}
+		place := awsx.Target{Account: r.Account, Region: region}
+		if _, ok := places[place]; !ok {
+			order = append(order, place)
+		}
+		places[place] = append(places[place], i)
+	}
+
+	// Each place writes only its own resources, so no lock is needed
+	failures := clients.ForEach(ctx, order, roleTemplate, maxInventoryWorkers,
+		func(ctx context.Context, place awsx.Target, clients *awsx.Clients) error {
+			found := make([]inventory.Resource, len(places[place]))
+			for j, i := range places[place] {
+				found[j] = resources[i]
+			}
+			err := inventory.NewCreatorFinder(clients.CloudTrail).Annotate(ctx, found, since, maxCreatorLookups)
+			for j, i := range places[place] {
+				resources[i].Creator = found[j].Creator
+			}
+			return err
+		})
+
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = fmt.Errorf("creators: %w", f)
+	}
+	return errs
+}
+
+// roleTemplateFlag returns --role-template when set, otherwise the
+// role_template config key or the default template
+func roleTemplateFlag(cmd *cobra.Command) string {
+	if cmd.Flags().Changed("role-template") {
+		template, _ := cmd.Flags().GetString("role-template")
+		return template
+	}
+	if template := viper.GetString("role_template"); template != "" {
+		return template
+	}
+	return awsx.DefaultRoleTemplate
+}
+
+// resourceCosts looks up the cost of every billed resource of the service
+// in the accounts and regions searched, keyed by the resource ID Cost
+// Explorer reports
+func resourceCosts(ctx context.Context, clients *awsx.Clients, svc *inventory.Service, metric string, window *timewin.Window, targets []awsx.Target, today time.Time) (map[string]inventory.Cost, error) {
+	regions, accounts := costScope(targets, clients)
+	deltas, err := cost.QueryResources(ctx, cost.NewCostExplorerResourceSource(clients.CostExplorer), cost.ResourceParams{
+		Window:   window,
+		Services: svc.CostServices(),
+		Regions:  regions,
+		Accounts: accounts,
+		Metric:   metric,
+		Today:    today,
+	})
 	if err != nil {
-		log.Warn("partial results due to error", zap.Error(err))
+		return nil, err
 	}
 
-	log.Debug("found resources", zap.Int("count", len(resources)))
+	costs := make(map[string]inventory.Cost, len(deltas))
+	for _, d := range deltas {
+		costs[d.Key] = inventory.Cost{
+			Metric:  d.Metric,
+			Current: d.CurrentCost,
+			Prior:   d.PriorCost,
+			Delta:   d.AbsoluteDelta,
+		}
+	}
+	return costs, nil
+}
 
-	// Output results
-	return output.PrintResources(resources, service, asJSON)
+// costScope returns the regions and accounts of the targets, so billed
+// resources outside the search aren't reported as billing-only. Targets
+// without a region or account are searched in the clients' own; when the
+// caller's account is unknown, costs aren't limited by account.
+func costScope(targets []awsx.Target, clients *awsx.Clients) (regions, accounts []string) {
+	seenRegions := make(map[string]bool)
+	seenAccounts := make(map[string]bool)
+	allAccounts := false
+	for _, t := range targets {
+		region, account := t.Region, t.Account
+		if region == "" {
+			region = clients.Config.Region
+		}
+		if account == "" {
+			account = clients.Account
+		}
+
+		if region != "" && !seenRegions[region] {
+			seenRegions[region] = true
+			regions = append(regions, region)
+		}
+		switch {
+		case account == "":
+			allAccounts = true
+		case !seenAccounts[account]:
+			seenAccounts[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+
+	if allAccounts {
+		accounts = nil
+	}
+	return regions, accounts
 }
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/forecast"
+	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Project month-end and quarter-end cost from historical daily spend",
+	Long: `Fit a linear trend plus day-of-week seasonality to each group's daily
+cost and project it to the end of the current month and quarter, with
+prediction intervals. Optionally compare against Cost Explorer's own forecast.
+
+Example:
+  cost-blame forecast --group-by service --top 10
+  cost-blame forecast --compare-ce --confidence 0.9`,
+	RunE: runForecast,
+}
+
+// newForecastClient returns the Cost Explorer client used by --compare-ce.
+// Tests swap in fixtures here.
+var newForecastClient = func(ctx context.Context) (forecast.ForecastAPI, error) {
+	clients, err := awsx.New(ctx, awsx.Options{
+		Profile: viper.GetString("profile"),
+		Region:  viper.GetString("region"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS clients: %w", err)
+	}
+	return clients.CostExplorer, nil
+}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+
+	forecastCmd.Flags().String("group-by", "service", "Group by: service, linked_account, region, usage_type, tag, or two joined by a comma")
+	forecastCmd.Flags().String("tag-key", "", "Tag key to group by (with --group-by tag, or as a second level)")
+	forecastCmd.Flags().Int("history-days", 90, "Number of days of history to fit the model on")
+	forecastCmd.Flags().Float64("confidence", 0.8, "Prediction interval level (0.51-0.99 with --compare-ce)")
+	forecastCmd.Flags().Bool("compare-ce", false, "Also fetch Cost Explorer's forecast for each row (one API call per row and period)")
+	forecastCmd.Flags().Int("top", 10, "Number of groups to show besides the total")
+	forecastCmd.Flags().Bool("json", false, "Output as JSON")
+	addMetricFlag(forecastCmd)
+}
+
+func runForecast(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	log := getLogger()
+
+	// Parse flags
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	tagKey, _ := cmd.Flags().GetString("tag-key")
+	historyDays, _ := cmd.Flags().GetInt("history-days")
+	confidence, _ := cmd.Flags().GetFloat64("confidence")
+	compareCE, _ := cmd.Flags().GetBool("compare-ce")
+	topN, _ := cmd.Flags().GetInt("top")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	metric, err := metricFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Cost Explorer only accepts prediction interval levels of 51-99%
+	if compareCE && (math.Round(confidence*100) < 51 || math.Round(confidence*100) > 99) {
+		return fmt.Errorf("--confidence %g: --compare-ce needs a level between 0.51 and 0.99", confidence)
+	}
+
+	today, err := reportToday()
+	if err != nil {
+		return err
+	}
+
+	config := forecast.Config{
+		Today:       today,
+		HistoryDays: historyDays,
+		Confidence:  confidence,
+		Metric:      metric,
+	}
+
+	groupDefs, err := cost.GroupDefinitions(groupBy, tagKey)
+	if err != nil {
+		return err
+	}
+
+	src, err := newCostSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	start, end := config.FetchRange()
+	log.Info("fetching daily cost history...",
+		zap.Time("start", start),
+		zap.Time("end", end),
+		zap.String("metric", metric))
+
+	series, err := anomaly.FetchDaily(ctx, src, groupDefs, start, end, metric)
+	if err != nil {
+		return err
+	}
+
+	forecasts, err := forecast.Project(series, config)
+	if err != nil {
+		return fmt.Errorf("forecast failed: %w", err)
+	}
+
+	// Keep the total plus the top N groups
+	if topN > 0 && len(forecasts) > topN+1 {
+		forecasts = forecasts[:topN+1]
+	}
+
+	if compareCE {
+		if viper.GetString("cur_path") != "" {
+			log.Warn("--compare-ce needs Cost Explorer and is ignored with --cur-path")
+		} else {
+			client, err := newForecastClient(ctx)
+			if err != nil {
+				return err
+			}
+
+			log.Info("fetching Cost Explorer forecasts...", zap.Int("rows", len(forecasts)))
+			forecaster := forecast.NewCostExplorerForecaster(client)
+			for i := range forecasts {
+				if err := forecaster.Compare(ctx, &forecasts[i], groupDefs, end, confidence); err != nil {
+					// Cost Explorer refuses to forecast groups with sparse history
+					log.Warn("Cost Explorer forecast unavailable", zap.String("key", forecasts[i].Key), zap.Error(err))
+				}
+			}
+		}
+	}
+
+	log.Debug("forecast complete", zap.Int("rows", len(forecasts)))
+
+	// Output results
+	if asJSON {
+		return output.PrintJSON(os.Stdout, map[string]interface{}{
+			"metric":     metric,
+			"confidence": confidence,
+			"forecasts":  forecasts,
+		})
+	}
+	return printForecastTable(forecasts, metric, confidence, compareCE)
+}
+
+func printForecastTable(forecasts []forecast.Forecast, metric string, confidence float64, compareCE bool) error {
+	if len(forecasts) == 0 {
+		fmt.Println("No cost history to forecast")
+		return nil
+	}
+
+	fmt.Printf("Metric: %s, %.0f%% prediction intervals\n", metric, confidence*100)
+
+	header := []string{"Key", "Trend/Day", "Month to Date", "Month End", "Month Range"}
+	if compareCE {
+		header = append(header, "CE Month End")
+	}
+	header = append(header, "Quarter End", "Quarter Range")
+	if compareCE {
+		header = append(header, "CE Quarter End")
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetBorder(true)
+	table.SetAutoWrapText(false)
+
+	amount := func(v float64) string { return cost.FormatAmount(metric, v) }
+	ceAmount := func(est *forecast.Estimate) string {
+		if est == nil {
+			return "-"
+		}
+		return amount(est.Total)
+	}
+
+	for _, fc := range forecasts {
+		month, quarter := fc.Projections[0], fc.Projections[1]
+
+		row := []string{
+			fc.Key,
+			fmt.Sprintf("%+.2f", fc.DailyTrend),
+			amount(month.Actual),
+			amount(month.Total),
+			amount(month.Lower) + " – " + amount(month.Upper),
+		}
+		if compareCE {
+			row = append(row, ceAmount(month.CostExplorer))
+		}
+		row = append(row, amount(quarter.Total), amount(quarter.Lower)+" – "+amount(quarter.Upper))
+		if compareCE {
+			row = append(row, ceAmount(quarter.CostExplorer))
+		}
+		table.Append(row)
+	}
+
+	table.Render()
+	return nil
+}
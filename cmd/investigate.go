@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+	"github.com/pfrederiksen/cost-blame/internal/investigate"
+	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var investigateCmd = &cobra.Command{
+	Use:   "investigate",
+	Short: "Find the spikes between two periods and the resources behind them",
+	Long: `Run spike and drilldown in one step: compare the current and prior periods,
+take the top deltas above the threshold, and search the resources of each
+spiking service where the spike happened.
+
+--group-by must include service. Its other dimension narrows the search: a
+region searches that region, a linked account searches that account through
+the role from --role-template, and a usage type searches the region in its
+prefix and keeps only the resource types it bills for. Results are nested by
+service and region.
+
+Example:
+  cost-blame investigate --last 7d --threshold 100
+  cost-blame investigate --group-by service,usage_type --top 3 --markdown
+  cost-blame investigate --group-by service,linked_account --json`,
+	RunE: runInvestigate,
+}
+
+func init() {
+	rootCmd.AddCommand(investigateCmd)
+
+	addWindowFlags(investigateCmd, "7d")
+	investigateCmd.Flags().Float64("threshold", 0, "Minimum USD delta to investigate")
+	investigateCmd.Flags().String("group-by", "service,region", "Group by service and one of region, linked_account, usage_type")
+	investigateCmd.Flags().StringSlice("accounts", nil, "Filter to specific account IDs (comma-separated)")
+	investigateCmd.Flags().Int("top", 5, "Number of spikes to investigate")
+	investigateCmd.Flags().String("tag-key", "", "Filter resources by tag key")
+	investigateCmd.Flags().String("role-template", awsx.DefaultRoleTemplate, "Role assumed in member accounts; {account} is replaced by the account ID")
+	investigateCmd.Flags().Bool("json", false, "Output as JSON")
+	investigateCmd.Flags().Bool("markdown", false, "Output as Markdown")
+	investigateCmd.MarkFlagsMutuallyExclusive("json", "markdown")
+	addMetricFlag(investigateCmd)
+}
+
+func runInvestigate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	log := getLogger()
+
+	threshold, _ := cmd.Flags().GetFloat64("threshold")
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	accounts, _ := cmd.Flags().GetStringSlice("accounts")
+	topN, _ := cmd.Flags().GetInt("top")
+	tagKey, _ := cmd.Flags().GetString("tag-key")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	asMarkdown, _ := cmd.Flags().GetBool("markdown")
+
+	window, err := windowFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	metric, err := metricFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	src, err := newCostSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info("querying cost data...")
+	deltas, err := cost.Query(ctx, src, cost.QueryParams{
+		Window:     window,
+		GroupBy:    groupBy,
+		AccountIDs: accounts,
+		Metric:     metric,
+	})
+	if err != nil {
+		return fmt.Errorf("cost query failed: %w", err)
+	}
+
+	targets, err := investigate.Targets(deltas, groupBy, threshold, topN)
+	if err != nil {
+		return err
+	}
+
+	var findings []investigate.Finding
+	if len(targets) > 0 {
+		findings, err = findTargets(ctx, targets, tagKey, roleTemplateFlag(cmd))
+		if err != nil {
+			return err
+		}
+	}
+
+	return output.PrintInvestigation(investigate.NewReport(metric, findings), window, asJSON, asMarkdown)
+}
+
+// findTargets searches the resources of every target, grouping targets by
+// the account and region they are searched in
+func findTargets(ctx context.Context, targets []investigate.Target, tagKey, roleTemplate string) ([]investigate.Finding, error) {
+	log := getLogger()
+
+	clients, err := inventoryClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]investigate.Finding, len(targets))
+	places := make(map[awsx.Target][]int)
+	var order []awsx.Target
+	for i, t := range targets {
+		// Spikes without a region are searched in the default one; global
+		// services keep their Cost Explorer region as the label
+		region := t.Region
+		switch region {
+		case "":
+			t.Region = clients.Config.Region
+		case "global", "NoRegion":
+			region = ""
+		}
+		findings[i].Target = t
+
+		place := awsx.Target{Account: t.Account, Region: region}
+		if _, ok := places[place]; !ok {
+			order = append(order, place)
+		}
+		places[place] = append(places[place], i)
+	}
+
+	log.Info("searching resources",
+		zap.Int("spikes", len(targets)),
+		zap.Int("places", len(order)))
+
+	failures := clients.ForEach(ctx, order, roleTemplate, maxInventoryWorkers,
+		func(ctx context.Context, place awsx.Target, clients *awsx.Clients) error {
+			for _, i := range places[place] {
+				f := &findings[i]
+				if _, err := inventory.LookupService(f.Service); err != nil {
+					f.Err = fmt.Errorf("no resource inventory for %s", f.Service)
+					continue
+				}
+				found, err := findResources(ctx, clients, f.Service, tagKey)
+				f.Resources = investigate.Narrow(found, f.Target)
+				f.Err = err
+			}
+			return nil
+		})
+
+	// Accounts whose role can't be assumed fail every spike searched there
+	for _, failure := range failures {
+		log.Warn("could not search for resources",
+			zap.String("account", failure.Account),
+			zap.String("region", failure.Region),
+			zap.Error(failure.Err))
+		for _, i := range places[failure.Target] {
+			findings[i].Err = failure
+		}
+	}
+
+	return findings, nil
+}
@@ -4,12 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/pfrederiksen/cost-blame/internal/awsx"
 	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/output"
-	"github.com/pfrederiksen/cost-blame/internal/timewin"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -27,13 +24,14 @@ Example:
 func init() {
 	rootCmd.AddCommand(newSpendCmd)
 
-	newSpendCmd.Flags().String("last", "30d", "Time window (48h, 7d, 30d)")
+	addWindowFlags(newSpendCmd, "30d")
 	newSpendCmd.Flags().String("granularity", "DAILY", "Granularity: DAILY or HOURLY")
 	newSpendCmd.Flags().Float64("min-current", 50, "Minimum current spend to consider")
 	newSpendCmd.Flags().String("group-by", "service", "Group by: service, linked_account, region, usage_type")
 	newSpendCmd.Flags().String("tag-key", "", "Optional tag dimension to group by")
 	newSpendCmd.Flags().Int("top", 20, "Number of results to show")
 	newSpendCmd.Flags().Bool("json", false, "Output as JSON")
+	addMetricFlag(newSpendCmd)
 }
 
 func runNewSpend(cmd *cobra.Command, args []string) error {
@@ -41,7 +39,6 @@ func runNewSpend(cmd *cobra.Command, args []string) error {
 	log := getLogger()
 
 	// Parse flags
-	lastWindow, _ := cmd.Flags().GetString("last")
 	granularity, _ := cmd.Flags().GetString("granularity")
 	minCurrent, _ := cmd.Flags().GetFloat64("min-current")
 	groupBy, _ := cmd.Flags().GetString("group-by")
@@ -50,28 +47,32 @@ func runNewSpend(cmd *cobra.Command, args []string) error {
 	asJSON, _ := cmd.Flags().GetBool("json")
 
 	// Parse time window
-	window, err := timewin.Parse(lastWindow)
+	window, err := windowFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("invalid time window: %w", err)
+		return err
 	}
 
-	// Create AWS clients
-	clients, err := awsx.New(ctx, awsx.Options{
-		Profile: viper.GetString("profile"),
-		Region:  viper.GetString("region"),
-	})
+	metric, err := metricFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS clients: %w", err)
+		return err
 	}
 
-	// Query cost data
-	log.Info("querying for new spenders...")
-	deltas, err := cost.Query(ctx, clients.CostExplorer, cost.QueryParams{
+	params := cost.QueryParams{
 		Window:      window,
 		Granularity: granularity,
 		GroupBy:     groupBy,
 		TagKey:      tagKey,
-	})
+		Metric:      metric,
+	}
+
+	src, err := newCostSource(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Query cost data
+	log.Info("querying for new spenders...")
+	deltas, err := cost.Query(ctx, src, params)
 	if err != nil {
 		return fmt.Errorf("cost query failed: %w", err)
 	}
@@ -87,5 +88,5 @@ func runNewSpend(cmd *cobra.Command, args []string) error {
 	log.Debug("found new spenders", zap.Int("count", len(newSpenders)))
 
 	// Output results
-	return output.PrintDeltas(newSpenders, 0, topN, asJSON, window.IncludesToday())
+	return output.PrintDeltas(newSpenders, 0, topN, asJSON, window)
 }
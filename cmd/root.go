@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -29,10 +30,30 @@ and drill down to specific resources causing cost changes.`,
 	},
 }
 
+// ExitError is returned by commands that report their outcome through a
+// specific exit code, such as budget
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -44,9 +65,15 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging")
 	rootCmd.PersistentFlags().String("profile", "", "AWS profile")
 	rootCmd.PersistentFlags().String("region", "us-east-1", "AWS region")
+	rootCmd.PersistentFlags().String("cur-path", "", "Read costs from local CUR files (CSV, CSV.gz, Parquet) instead of Cost Explorer")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Always call Cost Explorer instead of reading cached responses")
+	rootCmd.PersistentFlags().String("timezone", "", "IANA timezone for \"today\" and reported times, e.g. America/Los_Angeles (default: UTC)")
 
 	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	viper.BindPFlag("region", rootCmd.PersistentFlags().Lookup("region"))
+	viper.BindPFlag("cur_path", rootCmd.PersistentFlags().Lookup("cur-path"))
+	viper.BindPFlag("no_cache", rootCmd.PersistentFlags().Lookup("no-cache"))
+	viper.BindPFlag("timezone", rootCmd.PersistentFlags().Lookup("timezone"))
 }
 
 func initConfig() {
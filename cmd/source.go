@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/cur"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// newCostSource returns the cost backend for this run: local CUR files when
+// --cur-path is set, otherwise Cost Explorer behind the on-disk cache unless
+// --no-cache is set. Tests swap in fixtures here.
+var newCostSource = func(ctx context.Context) (cost.Source, error) {
+	if path := viper.GetString("cur_path"); path != "" {
+		ds, err := loadCUR(path)
+		if err != nil {
+			return nil, err
+		}
+		return ds, nil
+	}
+
+	clients, err := awsx.New(ctx, awsx.Options{
+		Profile: viper.GetString("profile"),
+		Region:  viper.GetString("region"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS clients: %w", err)
+	}
+
+	src := cost.NewCostExplorerSource(clients.CostExplorer)
+	if viper.GetBool("no_cache") {
+		return src, nil
+	}
+
+	// Key the cache by account so profiles never see each other's data
+	namespace, err := clients.CallerAccount(ctx)
+	if err != nil {
+		getLogger().Debug("caching disabled", zap.Error(err))
+		return src, nil
+	}
+
+	c, err := openCache(namespace)
+	if err != nil {
+		getLogger().Debug("caching disabled", zap.Error(err))
+		return src, nil
+	}
+
+	getLogger().Debug("using response cache", zap.String("dir", c.Dir()), zap.String("account", namespace))
+	return c.Wrap(src), nil
+}
+
+// addMetricFlag registers the --metric flag on a cost command
+func addMetricFlag(cmd *cobra.Command) {
+	cmd.Flags().String("metric", "", "Cost metric: "+strings.Join(cost.Metrics, ", ")+" (default UnblendedCost, or metric from config)")
+}
+
+// metricFlag returns the validated --metric value, falling back to the
+// metric config key
+func metricFlag(cmd *cobra.Command) (string, error) {
+	metric, _ := cmd.Flags().GetString("metric")
+	if metric == "" {
+		metric = viper.GetString("metric")
+	}
+	return cost.ParseMetric(metric)
+}
+
+// loadCUR loads the Cost and Usage Report files below path
+func loadCUR(path string) (*cur.Dataset, error) {
+	log := getLogger()
+
+	log.Info("loading CUR files...", zap.String("path", path))
+	ds, err := cur.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CUR data: %w", err)
+	}
+
+	log.Debug("loaded CUR data",
+		zap.Int("files", len(ds.Files)),
+		zap.Int("line_items", len(ds.Items)))
+	return ds, nil
+}
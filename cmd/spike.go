@@ -10,7 +10,6 @@ import (
 	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/export"
 	"github.com/pfrederiksen/cost-blame/internal/output"
-	"github.com/pfrederiksen/cost-blame/internal/timewin"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -22,15 +21,21 @@ var spikeCmd = &cobra.Command{
 	Long: `Compare cost data between current and prior periods of equal length.
 Identify services, accounts, or resources with the largest cost increases.
 
+The current period is a relative window (--last), an absolute date range
+(--from/--to) or a calendar period (--period). --compare-from/--compare-to
+replace the default prior period.
+
 Example:
-  cost-blame spike --last 7d --threshold 100 --group-by service --top 10`,
+  cost-blame spike --last 7d --threshold 100 --group-by service --top 10
+  cost-blame spike --period last-month
+  cost-blame spike --from 2026-09-01 --to 2026-09-30 --compare-from 2025-09-01 --compare-to 2025-09-30`,
 	RunE: runSpike,
 }
 
 func init() {
 	rootCmd.AddCommand(spikeCmd)
 
-	spikeCmd.Flags().String("last", "7d", "Time window (48h, 7d, 30d)")
+	addWindowFlags(spikeCmd, "7d")
 	spikeCmd.Flags().String("granularity", "DAILY", "Granularity: DAILY or HOURLY")
 	spikeCmd.Flags().Float64("threshold", 0, "Minimum USD delta to report")
 	spikeCmd.Flags().String("group-by", "service", "Group by: service, linked_account, region, usage_type")
@@ -41,6 +46,9 @@ func init() {
 	spikeCmd.Flags().Bool("json", false, "Output as JSON")
 	spikeCmd.Flags().String("csv", "", "Export to CSV file (path)")
 	spikeCmd.Flags().String("slack-webhook", "", "Send alerts to Slack webhook URL")
+	spikeCmd.Flags().Bool("explain-rate", false, "Split each delta into usage volume, unit rate and usage-type mix effects")
+	spikeCmd.Flags().Bool("series", false, "Keep the cost of every day (or hour): sparklines in tables, arrays in JSON, one column per date in CSV")
+	addMetricFlag(spikeCmd)
 }
 
 func runSpike(cmd *cobra.Command, args []string) error {
@@ -48,7 +56,6 @@ func runSpike(cmd *cobra.Command, args []string) error {
 	log := getLogger()
 
 	// Parse flags
-	lastWindow, _ := cmd.Flags().GetString("last")
 	granularity, _ := cmd.Flags().GetString("granularity")
 	threshold, _ := cmd.Flags().GetFloat64("threshold")
 	groupBy, _ := cmd.Flags().GetString("group-by")
@@ -59,11 +66,18 @@ func runSpike(cmd *cobra.Command, args []string) error {
 	asJSON, _ := cmd.Flags().GetBool("json")
 	csvPath, _ := cmd.Flags().GetString("csv")
 	slackWebhook, _ := cmd.Flags().GetString("slack-webhook")
+	explainRate, _ := cmd.Flags().GetBool("explain-rate")
+	series, _ := cmd.Flags().GetBool("series")
 
 	// Parse time window
-	window, err := timewin.Parse(lastWindow)
+	window, err := windowFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("invalid time window: %w", err)
+		return err
+	}
+
+	metric, err := metricFlag(cmd)
+	if err != nil {
+		return err
 	}
 
 	log.Debug("parsed time window",
@@ -72,36 +86,46 @@ func runSpike(cmd *cobra.Command, args []string) error {
 		zap.Time("prior_start", window.PriorStart),
 		zap.Time("prior_end", window.PriorEnd))
 
-	// Create AWS clients
-	clients, err := awsx.New(ctx, awsx.Options{
-		Profile: viper.GetString("profile"),
-		Region:  viper.GetString("region"),
-	})
+	params := cost.QueryParams{
+		Window:      window,
+		Granularity: granularity,
+		GroupBy:     groupBy,
+		TagKey:      tagKey,
+		AccountIDs:  accounts,
+		Metric:      metric,
+		ExplainRate: explainRate,
+		Series:      series,
+	}
+
+	src, err := newCostSource(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS clients: %w", err)
+		return err
 	}
 
 	// Resolve account IDs if --all-accounts is specified
-	if allAccounts {
+	// (CUR exports already cover every account the payer can see)
+	if allAccounts && viper.GetString("cur_path") == "" {
+		clients, err := awsx.New(ctx, awsx.Options{
+			Profile: viper.GetString("profile"),
+			Region:  viper.GetString("region"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create AWS clients: %w", err)
+		}
+
 		log.Info("fetching all accounts from organization...")
-		accounts, err = clients.ListAccounts(ctx)
+		params.AccountIDs, err = clients.ListAccounts(ctx)
 		if err != nil {
 			log.Warn("failed to list accounts, proceeding without filter", zap.Error(err))
-			accounts = nil
+			params.AccountIDs = nil
 		} else {
-			log.Info("found accounts", zap.Int("count", len(accounts)))
+			log.Info("found accounts", zap.Int("count", len(params.AccountIDs)))
 		}
 	}
 
 	// Query cost data
 	log.Info("querying cost data...")
-	deltas, err := cost.Query(ctx, clients.CostExplorer, cost.QueryParams{
-		Window:      window,
-		Granularity: granularity,
-		GroupBy:     groupBy,
-		TagKey:      tagKey,
-		AccountIDs:  accounts,
-	})
+	deltas, err := cost.Query(ctx, src, params)
 	if err != nil {
 		return fmt.Errorf("cost query failed: %w", err)
 	}
@@ -114,7 +138,7 @@ func runSpike(cmd *cobra.Command, args []string) error {
 		}
 		defer f.Close()
 
-		if err := export.WriteCSV(f, deltas); err != nil {
+		if err := export.WriteCSV(f, deltas, window); err != nil {
 			return fmt.Errorf("failed to write CSV: %w", err)
 		}
 		log.Info("exported to CSV", zap.String("path", csvPath))
@@ -122,7 +146,7 @@ func runSpike(cmd *cobra.Command, args []string) error {
 
 	// Send to Slack if webhook provided
 	if slackWebhook != "" {
-		if err := export.SendToSlack(slackWebhook, deltas, topN); err != nil {
+		if err := export.SendToSlack(slackWebhook, deltas, topN, window); err != nil {
 			log.Warn("failed to send to Slack", zap.Error(err))
 		} else {
 			log.Info("sent alert to Slack")
@@ -130,5 +154,5 @@ func runSpike(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output results to console
-	return output.PrintDeltas(deltas, threshold, topN, asJSON, window.IncludesToday())
+	return output.PrintDeltas(deltas, threshold, topN, asJSON, window)
 }
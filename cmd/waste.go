@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/awsx"
+	"github.com/pfrederiksen/cost-blame/internal/export"
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+	"github.com/pfrederiksen/cost-blame/internal/output"
+	"github.com/pfrederiksen/cost-blame/internal/waste"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var wasteCmd = &cobra.Command{
+	Use:   "waste",
+	Short: "Find idle and orphaned resources that cost money for nothing",
+	Long: `List resources that are paid for but not used, with an estimated monthly
+cost from a bundled table of us-east-1 list prices and a reason code:
+
+  unattached-volume   EBS volume not attached to any instance
+  stopped-instance    stopped instance whose EBS volumes are still billed
+  unassociated-eip    Elastic IP not associated with anything
+  orphaned-snapshot   snapshot older than --snapshot-age whose volume is gone
+  idle-nat-gateway    NAT gateway no route table sends traffic through
+  idle-load-balancer  load balancer without registered targets
+  empty-ecs-cluster   ECS cluster without services or running tasks
+  empty-eks-cluster   EKS cluster without node groups or Fargate profiles
+
+Accounts and regions are chosen as in drilldown. --csv writes the items to
+a file, or to standard output instead of the table with "-".
+
+Example:
+  cost-blame waste --regions all
+  cost-blame waste --all-accounts --min-cost 10 --csv waste.csv
+  cost-blame waste --snapshot-age 180 --json`,
+	RunE: runWaste,
+}
+
+// wasteServices are the services whose resources the waste rules check
+var wasteServices = []string{"ec2", "ecs", "eks"}
+
+func init() {
+	rootCmd.AddCommand(wasteCmd)
+
+	addSearchFlags(wasteCmd)
+	wasteCmd.Flags().Int("snapshot-age", int(waste.DefaultSnapshotAge.Hours()/24), "Minimum age in days of orphaned snapshots")
+	wasteCmd.Flags().Float64("min-cost", 0, "Minimum estimated monthly cost in USD")
+	wasteCmd.Flags().Bool("json", false, "Output as JSON")
+	wasteCmd.Flags().String("csv", "", "Export to CSV file (path), or \"-\" for standard output")
+}
+
+func runWaste(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	log := getLogger()
+
+	snapshotAge, _ := cmd.Flags().GetInt("snapshot-age")
+	minCost, _ := cmd.Flags().GetFloat64("min-cost")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	csvPath, _ := cmd.Flags().GetString("csv")
+
+	if asJSON && csvPath == "-" {
+		return fmt.Errorf("--json and --csv - both write to standard output; give --csv a file path")
+	}
+	if snapshotAge < 0 {
+		return fmt.Errorf("invalid snapshot age %d: must not be negative", snapshotAge)
+	}
+	opts := waste.Options{
+		Now:         time.Now(),
+		SnapshotAge: time.Duration(snapshotAge) * 24 * time.Hour,
+	}
+
+	clients, err := inventoryClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err := searchTargets(ctx, cmd, clients)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu    sync.Mutex
+		items []waste.Item
+	)
+	failures := clients.ForEach(ctx, targets, roleTemplateFlag(cmd), maxInventoryWorkers,
+		func(ctx context.Context, target awsx.Target, clients *awsx.Clients) error {
+			found, err := findWaste(ctx, clients, opts)
+			mu.Lock()
+			items = append(items, found...)
+			mu.Unlock()
+			return err
+		})
+
+	var errs []error
+	for _, f := range failures {
+		log.Warn("partial results due to error",
+			zap.String("account", f.Account),
+			zap.String("region", f.Region),
+			zap.Error(f.Err))
+		errs = append(errs, f)
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if item.MonthlyCost >= minCost {
+			kept = append(kept, item)
+		}
+	}
+	items = kept
+	waste.Sort(items)
+
+	log.Debug("found waste", zap.Int("count", len(items)))
+
+	if csvPath != "" {
+		if err := writeWasteCSV(csvPath, items); err != nil {
+			return err
+		}
+		if csvPath == "-" {
+			return nil
+		}
+		log.Info("exported to CSV", zap.String("path", csvPath))
+	}
+
+	return output.PrintWaste(items, asJSON, errs...)
+}
+
+// findWaste checks the resources of the waste services in the account and
+// region of the clients
+func findWaste(ctx context.Context, clients *awsx.Clients, opts waste.Options) ([]waste.Item, error) {
+	var (
+		resources []inventory.Resource
+		errs      []error
+	)
+	for _, service := range wasteServices {
+		found, err := findResources(ctx, clients, service, "")
+		resources = append(resources, found...)
+		if err != nil {
+			errs = append(errs, err)
+			opts.Partial = true
+		}
+	}
+	return waste.Find(resources, opts), errors.Join(errs...)
+}
+
+// writeWasteCSV writes waste items to a file, or to standard output for "-"
+func writeWasteCSV(path string, items []waste.Item) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create CSV file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := export.WriteWasteCSV(w, items); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// addWindowFlags registers the flags that select the current and prior
+// periods of a delta command
+func addWindowFlags(cmd *cobra.Command, defaultLast string) {
+	cmd.Flags().String("last", defaultLast, "Time window (48h, 7d, 30d)")
+	cmd.Flags().String("from", "", "First day of the current period (YYYY-MM-DD)")
+	cmd.Flags().String("to", "", "Last day of the current period, inclusive (default: yesterday)")
+	cmd.Flags().String("period", "", "Calendar period: last-month, mtd, qtd or ytd")
+	cmd.Flags().String("compare-from", "", "First day of the prior period to compare against (YYYY-MM-DD)")
+	cmd.Flags().String("compare-to", "", "Last day of the prior period, inclusive")
+	cmd.Flags().String("compare", "", "Calendar-aligned prior period: wow, mom, yoy or same-weekday")
+}
+
+// windowFlag builds the time window from the window flags. The --last
+// default only applies when neither --from/--to nor --period is given.
+func windowFlag(cmd *cobra.Command) (*timewin.Window, error) {
+	var spec timewin.Spec
+	spec.Last, _ = cmd.Flags().GetString("last")
+	spec.From, _ = cmd.Flags().GetString("from")
+	spec.To, _ = cmd.Flags().GetString("to")
+	spec.Period, _ = cmd.Flags().GetString("period")
+	spec.CompareFrom, _ = cmd.Flags().GetString("compare-from")
+	spec.CompareTo, _ = cmd.Flags().GetString("compare-to")
+	spec.Compare, _ = cmd.Flags().GetString("compare")
+
+	loc, err := reportLocation()
+	if err != nil {
+		return nil, err
+	}
+	spec.Location = loc
+	if granularity, err := cmd.Flags().GetString("granularity"); err == nil {
+		spec.Hourly = strings.EqualFold(granularity, "HOURLY")
+	}
+
+	if (spec.From != "" || spec.To != "" || spec.Period != "") && !cmd.Flags().Changed("last") {
+		spec.Last = ""
+	}
+
+	window, err := timewin.Build(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time window: %w", err)
+	}
+
+	if window.Uneven() {
+		getLogger().Info("periods differ in length; comparing per-day costs",
+			zap.Float64("current_days", window.CurrentDays()),
+			zap.Float64("prior_days", window.PriorDays()))
+	}
+	return window, nil
+}
+
+// reportLocation returns the timezone set with --timezone or the timezone
+// config key, UTC when neither is set
+func reportLocation() (*time.Location, error) {
+	name := viper.GetString("timezone")
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// reportToday returns the UTC day holding today's date in the reporting
+// timezone, for commands that end at "yesterday"
+func reportToday() (time.Time, error) {
+	loc, err := reportLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return timewin.Today(time.Now(), loc), nil
+}
@@ -5,11 +5,10 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
-	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/timewin"
 )
 
@@ -17,144 +16,203 @@ import (
 type Anomaly struct {
 	Key              string
 	CurrentCost      float64
-	HistoricalMean   float64
-	HistoricalStdDev float64
+	HistoricalMean   float64 // expected cost: the mean, or the median for robust statistics
+	HistoricalStdDev float64 // spread the z-score is measured in, scaled to a standard deviation
 	ZScore           float64
 	PercentDeviation float64
 	IsAnomaly        bool
-	Severity         string // LOW, MEDIUM, HIGH, CRITICAL
+	Severity         string    // LOW, MEDIUM, HIGH, CRITICAL
+	Metric           string    // Cost Explorer metric the costs are measured in
+	Method           string    // baseline method the score was computed with
+	Statistic        string    // stddev, mad or iqr
+	Period           time.Time // start of the scored day, or of the scored hours in hourly mode
 }
 
 // DetectorConfig holds configuration for anomaly detection
 type DetectorConfig struct {
-	HistoricalDays int     // Number of days of historical data to analyze
-	ZScoreThreshold float64 // Z-score threshold for anomaly (default: 2.0)
-	MinDataPoints  int     // Minimum data points required
+	HistoricalDays  int            // Number of days of historical data to analyze
+	ZScoreThreshold float64        // Z-score threshold for anomaly (default: 2.0)
+	MinDataPoints   int            // Minimum data points required
+	TagKey          string         // Tag to group by, alone or as a second level
+	Metric          string         // Cost Explorer metric (default: UnblendedCost)
+	Method          string         // Baseline method: zscore, weekday or stl (default: zscore)
+	Statistic       string         // Baseline statistic: stddev, mad or iqr (default: stddev)
+	Granularity     string         // DAILY or HOURLY (default: DAILY)
+	RecentHours     int            // Hours scored together in hourly mode (default: 1)
+	Now             time.Time      // Evaluation time (default: current time)
+	Location        *time.Location // Reporting timezone that decides which day is "yesterday" (default: UTC)
 }
 
-// Detect identifies cost anomalies using statistical analysis
-func Detect(ctx context.Context, client *costexplorer.Client, groupBy string, config DetectorConfig) ([]Anomaly, error) {
-	// Set defaults
-	if config.HistoricalDays == 0 {
-		config.HistoricalDays = 30
-	}
-	if config.ZScoreThreshold == 0 {
-		config.ZScoreThreshold = 2.0
+// Detect identifies cost anomalies using statistical analysis. groupBy takes
+// the same names as cost.GroupDefinitions, so each account's EC2 or each
+// team's tag value is scored separately.
+func Detect(ctx context.Context, src cost.Source, groupBy string, config DetectorConfig) ([]Anomaly, error) {
+	config = config.withDefaults()
+
+	metric, err := cost.ParseMetric(config.Metric)
+	if err != nil {
+		return nil, err
 	}
-	if config.MinDataPoints == 0 {
-		config.MinDataPoints = 7
+	config.Metric = metric
+
+	method, err := ParseMethod(config.Method)
+	if err != nil {
+		return nil, err
 	}
+	config.Method = method
 
-	// Query historical cost data (last N days)
-	endDate := time.Now().UTC().Truncate(24 * time.Hour)
-	startDate := endDate.Add(-time.Duration(config.HistoricalDays) * 24 * time.Hour)
-
-	// Build group definition
-	var groupDef types.GroupDefinition
-	switch groupBy {
-	case "service":
-		groupDef = types.GroupDefinition{
-			Type: types.GroupDefinitionType("DIMENSION"),
-			Key:  aws.String("SERVICE"),
-		}
-	case "linked_account":
-		groupDef = types.GroupDefinition{
-			Type: types.GroupDefinitionType("DIMENSION"),
-			Key:  aws.String("LINKED_ACCOUNT"),
-		}
-	default:
-		return nil, fmt.Errorf("unsupported group-by for anomaly detection: %s", groupBy)
+	statistic, err := ParseStatistic(config.Statistic)
+	if err != nil {
+		return nil, err
 	}
+	config.Statistic = statistic
 
-	input := &costexplorer.GetCostAndUsageInput{
-		TimePeriod: &types.DateInterval{
-			Start: aws.String(timewin.FormatCE(startDate)),
-			End:   aws.String(timewin.FormatCE(endDate)),
-		},
-		Granularity: types.GranularityDaily,
-		Metrics:     []string{"UnblendedCost"},
-		GroupBy:     []types.GroupDefinition{groupDef},
+	groupDefs, err := cost.GroupDefinitions(groupBy, config.TagKey)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fetch all historical data
-	historicalData := make(map[string][]float64)
-	var nextToken *string
+	switch config.Granularity {
+	case GranularityDaily:
+	case GranularityHourly:
+		return detectHourly(ctx, src, groupDefs, config)
+	default:
+		return nil, fmt.Errorf("unsupported granularity: %s (expected DAILY or HOURLY)", config.Granularity)
+	}
+	if method != MethodZScore && config.Granularity == GranularityDaily && config.HistoricalDays < minSeasonalDays {
+		return nil, fmt.Errorf("method %s needs at least %d historical days, got %d", method, minSeasonalDays, config.HistoricalDays)
+	}
 
-	for {
-		if nextToken != nil {
-			input.NextPageToken = nextToken
-		}
+	// Query historical cost data (last N days)
+	startDate, endDate := config.historicalRange()
 
-		output, err := client.GetCostAndUsage(ctx, input)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch historical costs: %w", err)
-		}
+	series, err := FetchDaily(ctx, src, groupDefs, startDate, endDate, config.Metric)
+	if err != nil {
+		return nil, err
+	}
 
-		// Process each time period
-		for _, result := range output.ResultsByTime {
-			for _, group := range result.Groups {
-				key := group.Keys[0]
-				if len(group.Metrics) > 0 {
-					if unblended, ok := group.Metrics["UnblendedCost"]; ok && unblended.Amount != nil {
-						cost := parseFloat(aws.ToString(unblended.Amount))
-						historicalData[key] = append(historicalData[key], cost)
-					}
-				}
-			}
-		}
+	return analyze(series, startDate, endDate, config), nil
+}
 
-		nextToken = output.NextPageToken
-		if nextToken == nil {
-			break
+func (c DetectorConfig) withDefaults() DetectorConfig {
+	c.Granularity = strings.ToUpper(c.Granularity)
+	if c.Granularity == "" {
+		c.Granularity = GranularityDaily
+	}
+	if c.HistoricalDays == 0 {
+		c.HistoricalDays = 30
+		if c.Granularity == GranularityHourly {
+			c.HistoricalDays = maxHourlyDays
 		}
 	}
+	if c.ZScoreThreshold == 0 {
+		c.ZScoreThreshold = 2.0
+	}
+	if c.MinDataPoints == 0 {
+		c.MinDataPoints = 7
+	}
+	if c.Metric == "" {
+		c.Metric = cost.DefaultMetric
+	}
+	if c.Method == "" {
+		c.Method = MethodZScore
+	}
+	if c.Statistic == "" {
+		c.Statistic = StatStdDev
+	}
+	if c.RecentHours == 0 {
+		c.RecentHours = 1
+	}
+	if c.Now.IsZero() {
+		c.Now = time.Now().UTC()
+	}
+	return c
+}
+
+// historicalRange returns the [start, end) range of whole UTC days to analyze,
+// ending before today in the reporting timezone
+func (c DetectorConfig) historicalRange() (time.Time, time.Time) {
+	endDate := timewin.Today(c.Now, c.Location)
+	startDate := endDate.Add(-time.Duration(c.HistoricalDays) * 24 * time.Hour)
+	return startDate, endDate
+}
 
-	// Compute anomalies
+// analyze scores the most recent data point of each series against a
+// baseline built from the rest. The seasonal methods work on the calendar
+// days in [start, end), so the last day is scored even when it had no cost.
+func analyze(series map[string][]Point, start, end time.Time, config DetectorConfig) []Anomaly {
 	var anomalies []Anomaly
-	for key, costs := range historicalData {
-		if len(costs) < config.MinDataPoints {
+	for key, points := range series {
+		if len(points) < config.MinDataPoints {
 			continue
 		}
 
-		// Current cost is the most recent data point
-		currentCost := costs[len(costs)-1]
+		var currentCost, mean, stdDev float64
+		period := end.AddDate(0, 0, -1)
+		switch config.Method {
+		case MethodWeekday:
+			daily := Align(points, start, end)
+			currentCost = daily[len(daily)-1]
+			mean, stdDev = weekdayBaseline(daily, config.Statistic)
+		case MethodSTL:
+			daily := Align(points, start, end)
+			currentCost = daily[len(daily)-1]
+			mean, stdDev = stlBaseline(daily, config.Statistic)
+		default:
+			// Current cost is the most recent data point; the baseline is all the others
+			currentCost = points[len(points)-1].Value
+			period = points[len(points)-1].Date
+			historical := make([]float64, len(points)-1)
+			for i, p := range points[:len(points)-1] {
+				historical[i] = p.Value
+			}
+			mean, stdDev = summarize(historical, config.Statistic)
+		}
 
-		// Historical baseline is all but the last data point
-		historical := costs[:len(costs)-1]
-		mean, stdDev := computeStats(historical)
+		anomalies = append(anomalies, score(key, currentCost, mean, stdDev, period, config))
+	}
 
-		// Calculate z-score
-		zScore := 0.0
-		if stdDev > 0 {
-			zScore = (currentCost - mean) / stdDev
-		}
+	sortAnomalies(anomalies)
+	return anomalies
+}
 
-		percentDeviation := 0.0
-		if mean > 0 {
-			percentDeviation = ((currentCost - mean) / mean) * 100
-		}
+// score builds the anomaly record of one key from its current cost and baseline
+func score(key string, currentCost, mean, stdDev float64, period time.Time, config DetectorConfig) Anomaly {
+	// Calculate z-score
+	zScore := 0.0
+	if stdDev > 0 {
+		zScore = (currentCost - mean) / stdDev
+	}
 
-		isAnomaly := math.Abs(zScore) >= config.ZScoreThreshold
-		severity := getSeverity(zScore)
+	percentDeviation := 0.0
+	if mean > 0 {
+		percentDeviation = ((currentCost - mean) / mean) * 100
+	}
 
-		anomalies = append(anomalies, Anomaly{
-			Key:              key,
-			CurrentCost:      currentCost,
-			HistoricalMean:   mean,
-			HistoricalStdDev: stdDev,
-			ZScore:           zScore,
-			PercentDeviation: percentDeviation,
-			IsAnomaly:        isAnomaly,
-			Severity:         severity,
-		})
+	isAnomaly := math.Abs(zScore) >= config.ZScoreThreshold
+	severity := getSeverity(zScore)
+
+	return Anomaly{
+		Key:              key,
+		CurrentCost:      currentCost,
+		HistoricalMean:   mean,
+		HistoricalStdDev: stdDev,
+		ZScore:           zScore,
+		PercentDeviation: percentDeviation,
+		IsAnomaly:        isAnomaly,
+		Severity:         severity,
+		Metric:           config.Metric,
+		Method:           config.Method,
+		Statistic:        config.Statistic,
+		Period:           period,
 	}
+}
 
-	// Sort by z-score (absolute value, descending)
+// sortAnomalies orders anomalies by absolute z-score, descending
+func sortAnomalies(anomalies []Anomaly) {
 	sort.Slice(anomalies, func(i, j int) bool {
 		return math.Abs(anomalies[i].ZScore) > math.Abs(anomalies[j].ZScore)
 	})
-
-	return anomalies, nil
 }
 
 func computeStats(values []float64) (mean, stdDev float64) {
@@ -194,9 +252,3 @@ func getSeverity(zScore float64) string {
 		return "LOW"
 	}
 }
-
-func parseFloat(s string) float64 {
-	var f float64
-	fmt.Sscanf(s, "%f", &f)
-	return f
-}
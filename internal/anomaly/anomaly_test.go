@@ -1,8 +1,12 @@
 package anomaly
 
 import (
+	"context"
 	"math"
 	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
 )
 
 func TestComputeStats(t *testing.T) {
@@ -80,25 +84,123 @@ func TestGetSeverity(t *testing.T) {
 	}
 }
 
-func TestParseFloat(t *testing.T) {
-	tests := []struct {
-		name  string
-		input string
-		want  float64
-	}{
-		{"integer", "42", 42.0},
-		{"float", "123.45", 123.45},
-		{"zero", "0", 0.0},
-		{"negative", "-50.5", -50.5},
-		{"invalid", "abc", 0.0},
+// dailyPoints turns values into consecutive daily points starting at start
+func dailyPoints(start time.Time, values ...float64) []Point {
+	points := make([]Point, len(values))
+	for i, v := range values {
+		points[i] = Point{Date: start.AddDate(0, 0, i), Value: v}
 	}
+	return points
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := parseFloat(tt.input)
-			if got != tt.want {
-				t.Errorf("parseFloat(%q) = %v, want %v", tt.input, got, tt.want)
-			}
+func TestAnalyze(t *testing.T) {
+	start := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	data := map[string][]Point{
+		"spiky":  dailyPoints(start, 10, 11, 9, 10, 10, 11, 9, 50),
+		"steady": dailyPoints(start, 10, 11, 9, 10, 10, 11, 9, 10),
+		"short":  dailyPoints(start.AddDate(0, 0, 6), 10, 100),
+	}
+
+	results := analyze(data, start, start.AddDate(0, 0, 8), DetectorConfig{ZScoreThreshold: 2.0, MinDataPoints: 7, Method: MethodZScore})
+	if len(results) != 2 {
+		t.Fatalf("analyze() returned %d results, want 2 (short series skipped)", len(results))
+	}
+	if results[0].Key != "spiky" || !results[0].IsAnomaly {
+		t.Errorf("expected spiky to be the top anomaly, got %+v", results[0])
+	}
+	if results[1].IsAnomaly {
+		t.Errorf("steady series should not be anomalous: %+v", results[1])
+	}
+}
+
+// fakeSource returns canned buckets and records the last request
+type fakeSource struct {
+	buckets []cost.TimeBucket
+	req     cost.CostRequest
+}
+
+func (f *fakeSource) GetCosts(ctx context.Context, req cost.CostRequest) ([]cost.TimeBucket, error) {
+	f.req = req
+	return f.buckets, nil
+}
+
+func TestDetect(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	src := &fakeSource{}
+	for i := 10; i >= 1; i-- {
+		amount := 100.0 + float64(i%2)
+		if i == 1 {
+			amount = 500 // yesterday spikes
+		}
+		src.buckets = append(src.buckets, cost.TimeBucket{
+			Start: today.AddDate(0, 0, -i),
+			End:   today.AddDate(0, 0, -i+1),
+			Groups: []cost.GroupCost{
+				{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"UnblendedCost": amount}},
+			},
 		})
 	}
+
+	results, err := Detect(context.Background(), src, "service", DetectorConfig{HistoricalDays: 30})
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Detect() returned %d results, want 1", len(results))
+	}
+	if results[0].CurrentCost != 500 || !results[0].IsAnomaly {
+		t.Errorf("expected yesterday's spike to be flagged, got %+v", results[0])
+	}
+
+	if !src.req.End.Equal(today) || !src.req.Start.Equal(today.AddDate(0, 0, -30)) {
+		t.Errorf("unexpected request range: %v - %v", src.req.Start, src.req.End)
+	}
+
+	if results[0].Metric != "UnblendedCost" || src.req.Metrics[0] != "UnblendedCost" {
+		t.Errorf("expected default metric UnblendedCost, got %q / %v", results[0].Metric, src.req.Metrics)
+	}
+
+	// Other metrics are requested and read by name
+	if results, _ := Detect(context.Background(), src, "service", DetectorConfig{Metric: "AmortizedCost"}); len(results) != 0 {
+		t.Errorf("no AmortizedCost data should yield no results, got %+v", results)
+	}
+	if src.req.Metrics[0] != "AmortizedCost" {
+		t.Errorf("requested metrics = %v, want [AmortizedCost]", src.req.Metrics)
+	}
+
+	if _, err := Detect(context.Background(), src, "instance_type", DetectorConfig{}); err == nil {
+		t.Error("Detect() should reject unsupported group-by")
+	}
+	if _, err := Detect(context.Background(), src, "tag", DetectorConfig{}); err == nil {
+		t.Error("Detect() should require a tag key to group by tag")
+	}
+
+	// Tags are requested as a grouping of their own
+	if _, err := Detect(context.Background(), src, "tag", DetectorConfig{TagKey: "team"}); err != nil {
+		t.Fatalf("Detect() by tag error = %v", err)
+	}
+	if len(src.req.GroupBy) != 1 || *src.req.GroupBy[0].Key != "team" {
+		t.Errorf("expected a single team tag grouping, got %+v", src.req.GroupBy)
+	}
+	if _, err := Detect(context.Background(), src, "service", DetectorConfig{Metric: "Bogus"}); err == nil {
+		t.Error("Detect() should reject unknown metrics")
+	}
+	if _, err := Detect(context.Background(), src, "service", DetectorConfig{Method: "prophet"}); err == nil {
+		t.Error("Detect() should reject unknown methods")
+	}
+	if _, err := Detect(context.Background(), src, "service", DetectorConfig{HistoricalDays: 10, Method: MethodWeekday}); err == nil {
+		t.Error("Detect() should require two weeks of history for seasonal methods")
+	}
+}
+
+func TestHistoricalRange_Location(t *testing.T) {
+	// 19:00 PDT on October 15, already October 16 in UTC
+	now := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	config := DetectorConfig{HistoricalDays: 30, Now: now, Location: time.FixedZone("PDT", -7*3600)}
+
+	start, end := config.historicalRange()
+	wantEnd := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	if !end.Equal(wantEnd) || !start.Equal(wantEnd.AddDate(0, 0, -30)) {
+		t.Errorf("historicalRange() = %v - %v, want 30 UTC days ending %v", start, end, wantEnd)
+	}
 }
@@ -0,0 +1,129 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// Granularities accepted by DetectorConfig.Granularity
+const (
+	GranularityDaily  = "DAILY"
+	GranularityHourly = "HOURLY"
+)
+
+// MethodHourOfDay is the method reported for hourly results, which are
+// always scored against the same hours of the day on earlier days
+const MethodHourOfDay = "hour-of-day"
+
+// maxHourlyDays is how far back Cost Explorer keeps hourly data
+const maxHourlyDays = 14
+
+// minHourlyBaselineDays is the fewest earlier days an hour-of-day baseline
+// needs
+const minHourlyBaselineDays = 3
+
+// detectHourly scores the most recent complete hours of each series against
+// the same hours on earlier days
+func detectHourly(ctx context.Context, src cost.Source, groupDefs []types.GroupDefinition, config DetectorConfig) ([]Anomaly, error) {
+	if config.HistoricalDays > maxHourlyDays {
+		return nil, fmt.Errorf("hourly data only covers the last %d days, got %d historical days", maxHourlyDays, config.HistoricalDays)
+	}
+	if config.Method != MethodZScore {
+		return nil, fmt.Errorf("method %s only applies to daily data; hourly mode always uses an hour-of-day baseline", config.Method)
+	}
+	if config.RecentHours < 1 || config.RecentHours > 24 {
+		return nil, fmt.Errorf("recent hours must be between 1 and 24, got %d", config.RecentHours)
+	}
+	config.Method = MethodHourOfDay
+
+	start, end := config.hourlyRange()
+
+	series, err := FetchHourly(ctx, src, groupDefs, start, end, config.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hourly cost data lags by several hours; trailing hours nobody has
+	// data for yet are not scored as drops
+	end = lastReportedHour(series, end)
+
+	return analyzeHourly(series, start, end, config), nil
+}
+
+// hourlyRange returns the [start, end) range of whole hours to analyze. The
+// current hour is still accruing cost, so the range ends where it begins.
+func (c DetectorConfig) hourlyRange() (time.Time, time.Time) {
+	end := c.Now.Truncate(time.Hour)
+	start := end.Add(-time.Duration(c.HistoricalDays) * 24 * time.Hour)
+
+	// Cost Explorer rejects hourly requests starting more than 14 days ago
+	if earliest := c.Now.Add(-maxHourlyDays * 24 * time.Hour).Truncate(time.Hour).Add(time.Hour); start.Before(earliest) {
+		start = earliest
+	}
+	return start, end
+}
+
+// lastReportedHour returns the end of the latest hour any series has cost
+// for, or end when that is later
+func lastReportedHour(series map[string][]Point, end time.Time) time.Time {
+	var latest time.Time
+	for _, points := range series {
+		for _, p := range points {
+			if p.Date.After(latest) {
+				latest = p.Date
+			}
+		}
+	}
+	if latest.IsZero() {
+		return end
+	}
+	if reported := latest.Add(time.Hour); reported.Before(end) {
+		return reported
+	}
+	return end
+}
+
+// analyzeHourly sums the last RecentHours hours before end for each series
+// and scores them against the same hours of the day on every earlier day
+func analyzeHourly(series map[string][]Point, start, end time.Time, config DetectorConfig) []Anomaly {
+	window := config.RecentHours
+
+	var anomalies []Anomaly
+	for key, points := range series {
+		if len(points) < config.MinDataPoints {
+			continue
+		}
+
+		hourly := alignStep(points, start, end, time.Hour)
+		if len(hourly) < window+24*minHourlyBaselineDays {
+			continue
+		}
+
+		n := len(hourly)
+		currentCost := sum(hourly[n-window:])
+
+		var baseline []float64
+		for last := n - 24; last-window >= 0; last -= 24 {
+			baseline = append(baseline, sum(hourly[last-window:last]))
+		}
+		mean, stdDev := summarize(baseline, config.Statistic)
+
+		period := end.Add(-time.Duration(window) * time.Hour)
+		anomalies = append(anomalies, score(key, currentCost, mean, stdDev, period, config))
+	}
+
+	sortAnomalies(anomalies)
+	return anomalies
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,125 @@
+package anomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// hourlyBuckets returns one bucket per hour in [start, end) with busy
+// business hours and quiet nights; override replaces individual hours
+func hourlyBuckets(start, end time.Time, override map[time.Time]float64) []cost.TimeBucket {
+	var buckets []cost.TimeBucket
+	for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+		amount := 2.0 + float64(hour.Day()%3)*0.1
+		if hour.Hour() >= 9 && hour.Hour() < 17 {
+			amount = 10 + float64(hour.Day()%3)*0.5
+		}
+		if v, ok := override[hour]; ok {
+			amount = v
+		}
+		buckets = append(buckets, cost.TimeBucket{
+			Start:  hour,
+			End:    hour.Add(time.Hour),
+			Groups: []cost.GroupCost{{Keys: []string{"AWSLambda"}, Metrics: map[string]float64{"UnblendedCost": amount}}},
+		})
+	}
+	return buckets
+}
+
+func TestDetectHourly(t *testing.T) {
+	now := time.Date(2024, 9, 15, 10, 30, 0, 0, time.UTC)
+	lastHour := time.Date(2024, 9, 15, 9, 0, 0, 0, time.UTC)
+	start := now.Add(-14 * 24 * time.Hour).Truncate(time.Hour).Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		dataEnd     time.Time // first hour without data
+		override    map[time.Time]float64
+		recentHours int
+		wantPeriod  time.Time
+		wantAnomaly bool
+	}{
+		{
+			name:        "runaway hour",
+			dataEnd:     lastHour.Add(time.Hour),
+			override:    map[time.Time]float64{lastHour: 80},
+			wantPeriod:  lastHour,
+			wantAnomaly: true,
+		},
+		{
+			// 9am is busy every day, so it is normal against earlier 9ams
+			name:        "ordinary business hour",
+			dataEnd:     lastHour.Add(time.Hour),
+			wantPeriod:  lastHour,
+			wantAnomaly: false,
+		},
+		{
+			name:        "lagging data scores the latest reported hour",
+			dataEnd:     lastHour.Add(-2 * time.Hour),
+			override:    map[time.Time]float64{lastHour.Add(-3 * time.Hour): 40},
+			wantPeriod:  lastHour.Add(-3 * time.Hour),
+			wantAnomaly: true,
+		},
+		{
+			name:        "recent hours are summed",
+			dataEnd:     lastHour.Add(time.Hour),
+			override:    map[time.Time]float64{lastHour.Add(-time.Hour): 30, lastHour: 30},
+			recentHours: 2,
+			wantPeriod:  lastHour.Add(-time.Hour),
+			wantAnomaly: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &fakeSource{buckets: hourlyBuckets(start, tt.dataEnd, tt.override)}
+
+			results, err := Detect(context.Background(), src, "service", DetectorConfig{
+				Granularity: "hourly",
+				RecentHours: tt.recentHours,
+				Now:         now,
+			})
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+
+			// The partial 10:00 hour is never requested
+			if !src.req.End.Equal(now.Truncate(time.Hour)) || !src.req.Start.Equal(start) || src.req.Granularity != "HOURLY" {
+				t.Errorf("unexpected request: %v - %v %s", src.req.Start, src.req.End, src.req.Granularity)
+			}
+
+			if len(results) != 1 {
+				t.Fatalf("Detect() returned %d results, want 1", len(results))
+			}
+			a := results[0]
+			if !a.Period.Equal(tt.wantPeriod) || a.IsAnomaly != tt.wantAnomaly || a.Method != MethodHourOfDay {
+				t.Errorf("got period %v anomaly %v method %s (z=%.2f), want %v %v",
+					a.Period, a.IsAnomaly, a.Method, a.ZScore, tt.wantPeriod, tt.wantAnomaly)
+			}
+		})
+	}
+}
+
+func TestDetectHourly_Validation(t *testing.T) {
+	src := &fakeSource{}
+	tests := []struct {
+		name   string
+		config DetectorConfig
+	}{
+		{"beyond hourly retention", DetectorConfig{Granularity: GranularityHourly, HistoricalDays: 30}},
+		{"daily-only method", DetectorConfig{Granularity: GranularityHourly, Method: MethodWeekday}},
+		{"window over a day", DetectorConfig{Granularity: GranularityHourly, RecentHours: 25}},
+		{"unknown granularity", DetectorConfig{Granularity: "MINUTELY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Detect(context.Background(), src, "service", tt.config); err == nil {
+				t.Error("Detect() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Baseline methods selectable through DetectorConfig.Method
+const (
+	// MethodZScore scores the latest day against the mean and standard
+	// deviation of every earlier day
+	MethodZScore = "zscore"
+	// MethodWeekday scores the latest day against earlier days falling on
+	// the same day of the week
+	MethodWeekday = "weekday"
+	// MethodSTL removes a moving-average trend and day-of-week seasonal
+	// component, then scores the latest day against the remaining residuals
+	MethodSTL = "stl"
+)
+
+// Methods lists the supported baseline methods
+var Methods = []string{MethodZScore, MethodWeekday, MethodSTL}
+
+// minSeasonalDays is the shortest window the seasonal methods accept: two
+// full weeks of history plus the day being scored
+const minSeasonalDays = 15
+
+// ParseMethod validates a method name case-insensitively. An empty name
+// yields MethodZScore.
+func ParseMethod(name string) (string, error) {
+	if name == "" {
+		return MethodZScore, nil
+	}
+	for _, m := range Methods {
+		if strings.EqualFold(name, m) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported method: %s (expected one of %s)", name, strings.Join(Methods, ", "))
+}
+
+// weekdayBaseline returns the center and spread of the days in history that
+// share a weekday with the last slot of daily
+func weekdayBaseline(daily []float64, statistic string) (center, spread float64) {
+	var sameDay []float64
+	for i := len(daily) - 1 - 7; i >= 0; i -= 7 {
+		sameDay = append(sameDay, daily[i])
+	}
+	return summarize(sameDay, statistic)
+}
+
+// stlBaseline decomposes all but the last slot of daily into trend, weekly
+// seasonal and residual components. It returns the expected value of the last
+// slot (recent level plus its seasonal offset) and the spread of the residuals.
+// Robust statistics also replace the means used for the seasonal offsets and
+// the level with medians.
+func stlBaseline(daily []float64, statistic string) (expected, spread float64) {
+	history := daily[:len(daily)-1]
+	trend := movingAverage(history, 7)
+
+	// Seasonal offsets are the typical detrended value per weekday, centered on zero
+	var detrended [7][]float64
+	for i, v := range history {
+		detrended[i%7] = append(detrended[i%7], v-trend[i])
+	}
+	var seasonal [7]float64
+	center := 0.0
+	for w := range seasonal {
+		seasonal[w] = location(detrended[w], statistic)
+		center += seasonal[w] / 7
+	}
+	for w := range seasonal {
+		seasonal[w] -= center
+	}
+
+	residuals := make([]float64, len(history))
+	for i, v := range history {
+		residuals[i] = v - trend[i] - seasonal[i%7]
+	}
+	_, spread = summarize(residuals, statistic)
+
+	// The level is the typical deseasonalized value of the most recent week
+	recent := make([]float64, 7)
+	for i := range recent {
+		day := len(history) - 7 + i
+		recent[i] = history[day] - seasonal[day%7]
+	}
+	level := location(recent, statistic)
+
+	expected = math.Max(0, level+seasonal[(len(daily)-1)%7])
+	return expected, spread
+}
+
+// movingAverage returns the centered moving average of values over window
+// slots, shrinking the window at the edges
+func movingAverage(values []float64, window int) []float64 {
+	half := window / 2
+	avg := make([]float64, len(values))
+	for i := range values {
+		lo, hi := i-half, i+half+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(values) {
+			hi = len(values)
+		}
+		sum := 0.0
+		for _, v := range values[lo:hi] {
+			sum += v
+		}
+		avg[i] = sum / float64(hi-lo)
+	}
+	return avg
+}
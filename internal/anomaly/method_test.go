@@ -0,0 +1,99 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+)
+
+// weeklySeries returns four weeks starting on a Sunday with busy weekdays
+// around 100 and quiet weekends around 10; last replaces the final day,
+// which is a Saturday
+func weeklySeries(last float64) (map[string][]Point, time.Time, time.Time) {
+	start := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC) // Sunday
+	var values []float64
+	for i := 0; i < 28; i++ {
+		day := start.AddDate(0, 0, i)
+		v := 100.0 + float64(i%3)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			v = 10 + float64(i%2)
+		}
+		values = append(values, v)
+	}
+	values[27] = last
+	return map[string][]Point{"AmazonEC2": dailyPoints(start, values...)}, start, start.AddDate(0, 0, 28)
+}
+
+func TestAnalyze_Methods(t *testing.T) {
+	tests := []struct {
+		name   string
+		last   float64
+		method string
+		want   bool
+	}{
+		// A weekend jump to weekday levels hides inside the all-days spread
+		{"zscore misses weekend spike", 60, MethodZScore, false},
+		{"weekday flags weekend spike", 60, MethodWeekday, true},
+		{"stl flags weekend spike", 60, MethodSTL, true},
+
+		// An ordinary quiet Saturday is not an anomaly once seasonality is modeled
+		{"weekday accepts quiet weekend", 10, MethodWeekday, false},
+		{"stl accepts quiet weekend", 10, MethodSTL, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, start, end := weeklySeries(tt.last)
+			results := analyze(series, start, end, DetectorConfig{ZScoreThreshold: 2.0, MinDataPoints: 7, Method: tt.method})
+			if len(results) != 1 {
+				t.Fatalf("analyze() returned %d results, want 1", len(results))
+			}
+			if results[0].IsAnomaly != tt.want {
+				t.Errorf("IsAnomaly = %v, want %v (z=%.2f, baseline %.2f ± %.2f)",
+					results[0].IsAnomaly, tt.want, results[0].ZScore, results[0].HistoricalMean, results[0].HistoricalStdDev)
+			}
+			if results[0].Method != tt.method {
+				t.Errorf("Method = %q, want %q", results[0].Method, tt.method)
+			}
+		})
+	}
+}
+
+func TestAnalyze_SeasonalScoresMissingLastDay(t *testing.T) {
+	series, start, end := weeklySeries(0)
+	series["AmazonEC2"] = series["AmazonEC2"][:27] // no cost at all on the last day
+
+	results := analyze(series, start, end, DetectorConfig{ZScoreThreshold: 2.0, MinDataPoints: 7, Method: MethodWeekday})
+	if len(results) != 1 || results[0].CurrentCost != 0 || !results[0].IsAnomaly {
+		t.Errorf("expected the missing Saturday to be scored as a drop, got %+v", results)
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", MethodZScore, false},
+		{"Weekday", MethodWeekday, false},
+		{"STL", MethodSTL, false},
+		{"ewma", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMethod(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMethod(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	got := movingAverage([]float64{1, 2, 3, 4, 5}, 3)
+	want := []float64{1.5, 2, 3, 4, 4.5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("movingAverage()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
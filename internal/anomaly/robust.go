@@ -0,0 +1,108 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Baseline statistics selectable through DetectorConfig.Statistic
+const (
+	// StatStdDev uses the mean and standard deviation
+	StatStdDev = "stddev"
+	// StatMAD uses the median and the median absolute deviation, so a few
+	// past spikes barely move the baseline
+	StatMAD = "mad"
+	// StatIQR uses the median and the interquartile range; a threshold of z
+	// fences values further than z·IQR/1.349 from the median
+	StatIQR = "iqr"
+)
+
+// Statistics lists the supported baseline statistics
+var Statistics = []string{StatStdDev, StatMAD, StatIQR}
+
+// Scale factors that make robust spreads comparable to a standard deviation
+// for normally distributed data, so the same threshold applies to all statistics
+const (
+	madScale    = 1.4826
+	iqrScale    = 1.349
+	meanADScale = 1.2533
+)
+
+// ParseStatistic validates a statistic name case-insensitively. An empty name
+// yields StatStdDev.
+func ParseStatistic(name string) (string, error) {
+	if name == "" {
+		return StatStdDev, nil
+	}
+	for _, s := range Statistics {
+		if strings.EqualFold(name, s) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported statistic: %s (expected one of %s)", name, strings.Join(Statistics, ", "))
+}
+
+// summarize returns the center and spread of values under statistic. Robust
+// spreads fall back to the scaled mean absolute deviation from the median
+// when more than half the values are identical (e.g. mostly zero-cost days).
+func summarize(values []float64, statistic string) (center, spread float64) {
+	switch statistic {
+	case StatMAD, StatIQR:
+		if len(values) == 0 {
+			return 0, 0
+		}
+		center = median(values)
+		if statistic == StatMAD {
+			spread = madScale * median(absDeviations(values, center))
+		} else {
+			spread = (quantile(values, 0.75) - quantile(values, 0.25)) / iqrScale
+		}
+		if spread == 0 {
+			mean, _ := computeStats(absDeviations(values, center))
+			spread = meanADScale * mean
+		}
+		return center, spread
+	default:
+		return computeStats(values)
+	}
+}
+
+// location returns the center of values under statistic
+func location(values []float64, statistic string) float64 {
+	switch statistic {
+	case StatMAD, StatIQR:
+		return median(values)
+	default:
+		mean, _ := computeStats(values)
+		return mean
+	}
+}
+
+func absDeviations(values []float64, center float64) []float64 {
+	devs := make([]float64, len(values))
+	for i, v := range values {
+		devs[i] = math.Abs(v - center)
+	}
+	return devs
+}
+
+func median(values []float64) float64 {
+	return quantile(values, 0.5)
+}
+
+// quantile returns the q-th quantile of values by linear interpolation
+// between order statistics
+func quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
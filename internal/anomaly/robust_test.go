@@ -0,0 +1,103 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		statistic  string
+		wantCenter float64
+		wantSpread float64
+	}{
+		{"stddev", []float64{10, 20, 30, 40, 50}, StatStdDev, 30, 14.1421},
+		{"mad", []float64{10, 20, 30, 40, 50}, StatMAD, 30, 1.4826 * 10},
+		{"iqr", []float64{10, 20, 30, 40, 50}, StatIQR, 30, 20 / 1.349},
+		{"mad ignores outlier", []float64{10, 11, 9, 10, 1000}, StatMAD, 10, 1.4826},
+		{"mad falls back on ties", []float64{0, 0, 0, 0, 10}, StatMAD, 0, 1.2533 * 2},
+		{"empty", nil, StatMAD, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			center, spread := summarize(tt.values, tt.statistic)
+			if math.Abs(center-tt.wantCenter) > 0.0001 || math.Abs(spread-tt.wantSpread) > 0.0001 {
+				t.Errorf("summarize() = %v, %v, want %v, %v", center, spread, tt.wantCenter, tt.wantSpread)
+			}
+		})
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 1},
+		{0.25, 1.75},
+		{0.5, 2.5},
+		{1, 4},
+	}
+
+	for _, tt := range tests {
+		if got := quantile(values, tt.q); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if values[0] != 4 {
+		t.Error("quantile() must not reorder its input")
+	}
+}
+
+func TestAnalyze_RobustAfterPastSpike(t *testing.T) {
+	// A huge spike two weeks ago inflates the standard deviation enough to
+	// hide today's real spike; median and MAD are not affected by it
+	start := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	var values []float64
+	for i := 0; i < 30; i++ {
+		values = append(values, 100+float64(i%3))
+	}
+	values[15] = 5000
+	values[29] = 400
+	series := map[string][]Point{"AmazonEC2": dailyPoints(start, values...)}
+
+	tests := []struct {
+		statistic string
+		want      bool
+	}{
+		{StatStdDev, false},
+		{StatMAD, true},
+		{StatIQR, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.statistic, func(t *testing.T) {
+			results := analyze(series, start, start.AddDate(0, 0, 30), DetectorConfig{
+				ZScoreThreshold: 2.0, MinDataPoints: 7, Method: MethodZScore, Statistic: tt.statistic,
+			})
+			if len(results) != 1 || results[0].IsAnomaly != tt.want {
+				t.Fatalf("IsAnomaly = %v, want %v: %+v", results[0].IsAnomaly, tt.want, results)
+			}
+			if results[0].Statistic != tt.statistic {
+				t.Errorf("Statistic = %q, want %q", results[0].Statistic, tt.statistic)
+			}
+		})
+	}
+}
+
+func TestParseStatistic(t *testing.T) {
+	if got, err := ParseStatistic(""); err != nil || got != StatStdDev {
+		t.Errorf("ParseStatistic(\"\") = %q, %v", got, err)
+	}
+	if got, err := ParseStatistic("MAD"); err != nil || got != StatMAD {
+		t.Errorf("ParseStatistic(\"MAD\") = %q, %v", got, err)
+	}
+	if _, err := ParseStatistic("trimmed"); err == nil {
+		t.Error("ParseStatistic() should reject unknown statistics")
+	}
+}
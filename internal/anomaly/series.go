@@ -0,0 +1,75 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// Point is one day's or hour's cost for a group key
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// FetchDaily returns the daily series of every group key over [start, end),
+// oldest first. Keys are built with cost.GroupKey from all groupings. Days on
+// which a key had no cost are omitted.
+func FetchDaily(ctx context.Context, src cost.Source, groupDefs []types.GroupDefinition, start, end time.Time, metric string) (map[string][]Point, error) {
+	return fetchSeries(ctx, src, groupDefs, start, end, metric, types.GranularityDaily)
+}
+
+// FetchHourly is FetchDaily at hourly granularity. Cost Explorer only keeps
+// hourly data for the last 14 days.
+func FetchHourly(ctx context.Context, src cost.Source, groupDefs []types.GroupDefinition, start, end time.Time, metric string) (map[string][]Point, error) {
+	return fetchSeries(ctx, src, groupDefs, start, end, metric, types.GranularityHourly)
+}
+
+func fetchSeries(ctx context.Context, src cost.Source, groupDefs []types.GroupDefinition, start, end time.Time, metric string, gran types.Granularity) (map[string][]Point, error) {
+	buckets, err := src.GetCosts(ctx, cost.CostRequest{
+		Start:       start,
+		End:         end,
+		Granularity: gran,
+		Metrics:     []string{metric},
+		GroupBy:     groupDefs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch historical costs: %w", err)
+	}
+
+	series := make(map[string][]Point)
+	for _, bucket := range buckets {
+		for _, group := range bucket.Groups {
+			if len(group.Keys) == 0 {
+				continue
+			}
+			key := cost.GroupKey(group.Keys)
+			if amount, ok := group.Metrics[metric]; ok {
+				series[key] = append(series[key], Point{Date: bucket.Start, Value: amount})
+			}
+		}
+	}
+
+	return series, nil
+}
+
+// Align spreads points over one slot per day in [start, end); missing days are zero
+func Align(points []Point, start, end time.Time) []float64 {
+	return alignStep(points, start, end, 24*time.Hour)
+}
+
+// alignStep spreads points over one slot per step in [start, end)
+func alignStep(points []Point, start, end time.Time, step time.Duration) []float64 {
+	slots := make([]float64, int(math.Round(float64(end.Sub(start))/float64(step))))
+	for _, p := range points {
+		i := int(math.Round(float64(p.Date.Sub(start)) / float64(step)))
+		if i >= 0 && i < len(slots) {
+			slots[i] += p.Value
+		}
+	}
+	return slots
+}
@@ -0,0 +1,62 @@
+package anomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+func TestFetchDaily(t *testing.T) {
+	day := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	src := &fakeSource{buckets: []cost.TimeBucket{
+		{Start: day, Groups: []cost.GroupCost{
+			{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"AmortizedCost": 10}},
+			{Keys: []string{"AmazonS3"}, Metrics: map[string]float64{"AmortizedCost": 1}},
+		}},
+		{Start: day.AddDate(0, 0, 1), Groups: []cost.GroupCost{
+			{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"AmortizedCost": 12}},
+		}},
+	}}
+
+	groupDefs, _ := cost.GroupDefinitions("linked_account", "")
+	series, err := FetchDaily(context.Background(), src, groupDefs, day, day.AddDate(0, 0, 2), "AmortizedCost")
+	if err != nil {
+		t.Fatalf("FetchDaily() error = %v", err)
+	}
+
+	if len(series["AmazonEC2"]) != 2 || series["AmazonEC2"][1].Value != 12 || !series["AmazonEC2"][1].Date.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("unexpected EC2 series: %+v", series["AmazonEC2"])
+	}
+	if len(series["AmazonS3"]) != 1 {
+		t.Errorf("days without cost should be omitted, got %+v", series["AmazonS3"])
+	}
+	if *src.req.GroupBy[0].Key != "LINKED_ACCOUNT" || src.req.Metrics[0] != "AmortizedCost" {
+		t.Errorf("unexpected request: %+v", src.req)
+	}
+}
+
+func TestFetchDaily_CompositeKeys(t *testing.T) {
+	day := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	src := &fakeSource{buckets: []cost.TimeBucket{
+		{Start: day, Groups: []cost.GroupCost{
+			{Keys: []string{"AmazonEC2", "111111111111"}, Metrics: map[string]float64{"UnblendedCost": 10}},
+			{Keys: []string{"AmazonEC2", "222222222222"}, Metrics: map[string]float64{"UnblendedCost": 20}},
+		}},
+	}}
+
+	groupDefs, _ := cost.GroupDefinitions("service,linked_account", "")
+	series, err := FetchDaily(context.Background(), src, groupDefs, day, day.AddDate(0, 0, 1), "UnblendedCost")
+	if err != nil {
+		t.Fatalf("FetchDaily() error = %v", err)
+	}
+
+	// Each account's EC2 is its own series rather than being summed
+	if len(series) != 2 || series["AmazonEC2 | 222222222222"][0].Value != 20 {
+		t.Errorf("expected one series per service and account, got %+v", series)
+	}
+	if len(src.req.GroupBy) != 2 {
+		t.Errorf("expected two groupings in request, got %+v", src.req.GroupBy)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // ListAccounts retrieves all active accounts in the organization
@@ -42,3 +43,13 @@ func (c *Clients) GetAccountName(ctx context.Context, accountID string) (string,
 
 	return aws.ToString(output.Account.Name), nil
 }
+
+// CallerAccount returns the account ID of the current credentials
+func (c *Clients) CallerAccount(ctx context.Context) (string, error) {
+	output, err := c.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	return aws.ToString(output.Account), nil
+}
@@ -0,0 +1,51 @@
+package awsx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+)
+
+// DefaultRoleTemplate is the role assumed in member accounts when no
+// template is configured
+const DefaultRoleTemplate = "arn:aws:iam::{account}:role/CostBlameReadOnly"
+
+// accountPlaceholder is replaced by the account ID in role templates
+const accountPlaceholder = "{account}"
+
+// RoleARN fills the {account} placeholder of a role ARN template
+func RoleARN(template, account string) (string, error) {
+	if !strings.Contains(template, accountPlaceholder) {
+		return "", fmt.Errorf("role template %q has no %s placeholder", template, accountPlaceholder)
+	}
+	if len(account) != 12 || strings.Trim(account, "0123456789") != "" {
+		return "", fmt.Errorf("invalid account ID %q", account)
+	}
+	return strings.ReplaceAll(template, accountPlaceholder, account), nil
+}
+
+// ForAccount returns clients acting in account through the role from the
+// template, assumed with the current credentials. The role is assumed on
+// the first API call and refreshed before it expires. Clients already in
+// account are returned as they are.
+func (c *Clients) ForAccount(account, template string) (*Clients, error) {
+	if account == c.Account {
+		return c, nil
+	}
+
+	roleARN, err := RoleARN(template, account)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := c.Config.Copy()
+	cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(c.STS, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = "cost-blame"
+	}))
+
+	clients := newFromConfig(cfg)
+	clients.Account = account
+	return clients, nil
+}
@@ -0,0 +1,55 @@
+package awsx
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestRoleARN(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		account  string
+		want     string
+		wantErr  bool
+	}{
+		{"default template", DefaultRoleTemplate, "123456789012", "arn:aws:iam::123456789012:role/CostBlameReadOnly", false},
+		{"custom path", "arn:aws:iam::{account}:role/audit/{account}-reader", "210987654321", "arn:aws:iam::210987654321:role/audit/210987654321-reader", false},
+		{"no placeholder", "arn:aws:iam::123456789012:role/Fixed", "123456789012", "", true},
+		{"short account", DefaultRoleTemplate, "12345", "", true},
+		{"non-numeric account", DefaultRoleTemplate, "abcd12345678", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RoleARN(tt.template, tt.account)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoleARN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RoleARN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForAccount(t *testing.T) {
+	base := newFromConfig(aws.Config{Region: "us-east-1"})
+	base.Account = "111111111111"
+
+	if c, err := base.ForAccount("111111111111", DefaultRoleTemplate); err != nil || c != base {
+		t.Errorf("ForAccount() of the caller's account should return the same clients, got %p, %v", c, err)
+	}
+
+	member, err := base.ForAccount("222222222222", DefaultRoleTemplate)
+	if err != nil {
+		t.Fatalf("ForAccount() error = %v", err)
+	}
+	if member.Account != "222222222222" || member.EC2 == nil || member.Config.Credentials == nil {
+		t.Errorf("expected member clients with assumed-role credentials, got %+v", member)
+	}
+	if base.Config.Credentials != nil {
+		t.Error("ForAccount() must not change the caller's credentials")
+	}
+}
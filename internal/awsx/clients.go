@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
@@ -16,12 +17,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // Clients holds AWS SDK v2 service clients
 type Clients struct {
 	CostExplorer  *costexplorer.Client
 	Organizations *organizations.Client
+	CloudTrail    *cloudtrail.Client
 	Tagging       *resourcegroupstaggingapi.Client
 	EC2           *ec2.Client
 	RDS           *rds.Client
@@ -30,13 +33,20 @@ type Clients struct {
 	CloudFront    *cloudfront.Client
 	ECS           *ecs.Client
 	EKS           *eks.Client
+	STS           *sts.Client
 	Config        aws.Config
+	Account       string // account the clients act in, when known
 }
 
 // Options for AWS client configuration
 type Options struct {
 	Profile string
 	Region  string
+
+	// Account, when set, makes the clients act in that account by assuming
+	// the role from RoleTemplate (default: DefaultRoleTemplate)
+	Account      string
+	RoleTemplate string
 }
 
 // New creates AWS clients with the given options
@@ -56,9 +66,24 @@ func New(ctx context.Context, opts Options) (*Clients, error) {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	clients := newFromConfig(cfg)
+	if opts.Account == "" {
+		return clients, nil
+	}
+
+	template := opts.RoleTemplate
+	if template == "" {
+		template = DefaultRoleTemplate
+	}
+	return clients.ForAccount(opts.Account, template)
+}
+
+// newFromConfig creates every service client from one configuration
+func newFromConfig(cfg aws.Config) *Clients {
 	return &Clients{
 		CostExplorer:  costexplorer.NewFromConfig(cfg),
 		Organizations: organizations.NewFromConfig(cfg),
+		CloudTrail:    cloudtrail.NewFromConfig(cfg),
 		Tagging:       resourcegroupstaggingapi.NewFromConfig(cfg),
 		EC2:           ec2.NewFromConfig(cfg),
 		RDS:           rds.NewFromConfig(cfg),
@@ -67,6 +92,7 @@ func New(ctx context.Context, opts Options) (*Clients, error) {
 		CloudFront:    cloudfront.NewFromConfig(cfg),
 		ECS:           ecs.NewFromConfig(cfg),
 		EKS:           eks.NewFromConfig(cfg),
+		STS:           sts.NewFromConfig(cfg),
 		Config:        cfg,
-	}, nil
+	}
 }
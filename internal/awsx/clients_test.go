@@ -67,6 +67,9 @@ func TestNew(t *testing.T) {
 				if clients.Organizations == nil {
 					t.Error("Organizations client is nil")
 				}
+				if clients.CloudTrail == nil {
+					t.Error("CloudTrail client is nil")
+				}
 				if clients.Tagging == nil {
 					t.Error("Tagging client is nil")
 				}
@@ -0,0 +1,158 @@
+package awsx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// Target is one account and region to run against. Empty fields keep the
+// account or region of the clients.
+type Target struct {
+	Account string
+	Region  string
+}
+
+// Targets returns every combination of accounts and regions; either list
+// may be empty to keep the clients' own
+func Targets(accounts, regions []string) []Target {
+	if len(accounts) == 0 {
+		accounts = []string{""}
+	}
+	if len(regions) == 0 {
+		regions = []string{""}
+	}
+
+	targets := make([]Target, 0, len(accounts)*len(regions))
+	for _, account := range accounts {
+		for _, region := range regions {
+			targets = append(targets, Target{Account: account, Region: region})
+		}
+	}
+	return targets
+}
+
+// TargetError is the failure of one target of ForEach
+type TargetError struct {
+	Target
+	Err error
+}
+
+func (e *TargetError) Error() string {
+	switch {
+	case e.Account != "" && e.Region != "":
+		return fmt.Sprintf("account %s, region %s: %v", e.Account, e.Region, e.Err)
+	case e.Account != "":
+		return fmt.Sprintf("account %s: %v", e.Account, e.Err)
+	case e.Region != "":
+		return fmt.Sprintf("region %s: %v", e.Region, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// ForEach runs fn with clients for every target, at most concurrency at a
+// time. Member accounts are reached through the role from the template.
+// Targets that fail, including accounts whose role can't be assumed, don't
+// stop the others; their errors are returned in target order.
+func (c *Clients) ForEach(ctx context.Context, targets []Target, template string, concurrency int, fn func(ctx context.Context, target Target, clients *Clients) error) []*TargetError {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Each account's role is assumed once and its credentials shared by
+	// every region, so STS is called once per account
+	accounts := make(map[string]*accountClients)
+	for _, target := range targets {
+		if target.Account != "" && accounts[target.Account] == nil {
+			accounts[target.Account] = &accountClients{}
+		}
+	}
+
+	// Each target writes only its own slot, so no lock is needed
+	failures := make([]*TargetError, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := c.run(ctx, target, accounts[target.Account], template, fn); err != nil {
+				failures[i] = &TargetError{Target: target, Err: err}
+			}
+		}(i, target)
+	}
+	wg.Wait()
+
+	var errs []*TargetError
+	for _, e := range failures {
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// accountClients are the clients of one account, created on first use
+type accountClients struct {
+	once    sync.Once
+	clients *Clients
+	err     error
+}
+
+// run calls fn with clients for one target, reusing the account's clients
+// when it has any
+func (c *Clients) run(ctx context.Context, target Target, account *accountClients, template string, fn func(ctx context.Context, target Target, clients *Clients) error) error {
+	clients := c
+	if account != nil {
+		account.once.Do(func() {
+			account.clients, account.err = c.ForAccount(target.Account, template)
+		})
+		if account.err != nil {
+			return account.err
+		}
+		clients = account.clients
+	}
+	return fn(ctx, target, clients.ForRegion(target.Region))
+}
+
+// ForRegion returns clients for another region with the same credentials.
+// An empty region keeps the current one.
+func (c *Clients) ForRegion(region string) *Clients {
+	if region == "" || region == c.Config.Region {
+		return c
+	}
+
+	cfg := c.Config.Copy()
+	cfg.Region = region
+	clients := newFromConfig(cfg)
+	clients.Account = c.Account
+	return clients
+}
+
+// ListRegions returns the regions enabled for the account, sorted by name
+func (c *Clients) ListRegions(ctx context.Context) ([]string, error) {
+	output, err := c.EC2.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+
+	regions := make([]string, 0, len(output.Regions))
+	for _, r := range output.Regions {
+		regions = append(regions, aws.ToString(r.RegionName))
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
@@ -0,0 +1,130 @@
+package awsx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts []string
+		regions  []string
+		want     int
+	}{
+		{"own account and region", nil, nil, 1},
+		{"regions only", nil, []string{"us-east-1", "eu-west-1"}, 2},
+		{"accounts only", []string{"111111111111", "222222222222", "333333333333"}, nil, 3},
+		{"both", []string{"111111111111", "222222222222"}, []string{"us-east-1", "eu-west-1"}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Targets(tt.accounts, tt.regions); len(got) != tt.want {
+				t.Errorf("Targets() = %v, want %d targets", got, tt.want)
+			}
+		})
+	}
+
+	got := Targets([]string{"111111111111"}, []string{"eu-west-1"})
+	if got[0] != (Target{Account: "111111111111", Region: "eu-west-1"}) {
+		t.Errorf("Targets() = %v", got)
+	}
+}
+
+func TestForRegion(t *testing.T) {
+	base := newFromConfig(aws.Config{Region: "us-east-1"})
+	base.Account = "111111111111"
+
+	if base.ForRegion("") != base || base.ForRegion("us-east-1") != base {
+		t.Error("ForRegion() of the current region should return the same clients")
+	}
+
+	west := base.ForRegion("us-west-2")
+	if west.Config.Region != "us-west-2" || west.Account != base.Account || west.EC2 == nil {
+		t.Errorf("unexpected regional clients: region %q, account %q", west.Config.Region, west.Account)
+	}
+	if base.Config.Region != "us-east-1" {
+		t.Error("ForRegion() must not change the original clients")
+	}
+}
+
+func TestForEach(t *testing.T) {
+	base := newFromConfig(aws.Config{Region: "us-east-1"})
+	targets := append(Targets([]string{"111111111111", "222222222222"}, []string{"us-east-1", "eu-west-1"}),
+		Target{Account: "bad"}, Target{Region: "ap-south-1"})
+
+	var (
+		mu      sync.Mutex
+		seen    = make(map[Target]string)
+		running int32
+		peak    int32
+	)
+	errs := base.ForEach(context.Background(), targets, DefaultRoleTemplate, 2,
+		func(ctx context.Context, target Target, clients *Clients) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			seen[target] = clients.Account + "/" + clients.Config.Region
+			mu.Unlock()
+
+			if target.Region == "ap-south-1" {
+				return errors.New("opt-in region disabled")
+			}
+			return nil
+		})
+
+	if len(errs) != 2 || errs[0].Account != "bad" || errs[1].Region != "ap-south-1" {
+		t.Fatalf("expected the bad account and the failing region in target order, got %v", errs)
+	}
+	if got := errs[1].Error(); got != "region ap-south-1: opt-in region disabled" {
+		t.Errorf("TargetError.Error() = %q", got)
+	}
+	if len(seen) != 5 || seen[Target{Account: "222222222222", Region: "eu-west-1"}] != "222222222222/eu-west-1" {
+		t.Errorf("fn saw %v, want clients for each valid target", seen)
+	}
+	if peak > 2 {
+		t.Errorf("%d targets ran at once, want at most 2", peak)
+	}
+}
+
+func TestForEach_SharesAccountCredentials(t *testing.T) {
+	base := newFromConfig(aws.Config{Region: "us-east-1"})
+	targets := Targets([]string{"111111111111", "222222222222"}, []string{"us-east-1", "eu-west-1", "ap-south-1"})
+
+	var (
+		mu    sync.Mutex
+		creds = make(map[string]map[aws.CredentialsProvider]bool)
+	)
+	base.ForEach(context.Background(), targets, DefaultRoleTemplate, 4,
+		func(ctx context.Context, target Target, clients *Clients) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if creds[target.Account] == nil {
+				creds[target.Account] = make(map[aws.CredentialsProvider]bool)
+			}
+			creds[target.Account][clients.Config.Credentials] = true
+			return nil
+		})
+
+	// One assumed role per account, whatever the number of regions
+	for _, account := range []string{"111111111111", "222222222222"} {
+		if n := len(creds[account]); n != 1 {
+			t.Errorf("account %s used %d credential providers, want 1", account, n)
+		}
+	}
+}
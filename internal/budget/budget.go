@@ -0,0 +1,233 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/forecast"
+)
+
+// DefaultWarnAt is the fraction of a budget at which month-to-date spend warns
+const DefaultWarnAt = 0.8
+
+// historyDays is how much daily history the month-end projection is fitted on
+const historyDays = 60
+
+// Status is the outcome of evaluating a budget
+type Status string
+
+// Budget statuses, from best to worst
+const (
+	StatusOK     Status = "OK"
+	StatusWarn   Status = "WARN"
+	StatusBreach Status = "BREACH"
+)
+
+// Definition is a monthly budget read from the config file. Service, Account
+// and the tag filter may be combined; a budget with none covers all spend.
+type Definition struct {
+	Name     string  `mapstructure:"name"`
+	Amount   float64 `mapstructure:"amount"`
+	WarnAt   float64 `mapstructure:"warn_at"` // fraction of Amount (default: 0.8)
+	Service  string  `mapstructure:"service"`
+	Account  string  `mapstructure:"account"`
+	TagKey   string  `mapstructure:"tag_key"`
+	TagValue string  `mapstructure:"tag_value"`
+	Metric   string  `mapstructure:"metric"` // default: UnblendedCost
+}
+
+// Result is the evaluation of one budget for the current month
+type Result struct {
+	Definition
+	Scope            string  // human-readable filter description
+	Actual           float64 // month-to-date spend over closed days
+	Projected        float64 // expected month-end spend
+	PercentUsed      float64 // Actual as a percentage of Amount
+	PercentProjected float64 // Projected as a percentage of Amount
+	Status           Status
+	Reason           string
+}
+
+// Validate checks that a definition is complete
+func (d Definition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("budget is missing a name")
+	}
+	if d.Amount <= 0 {
+		return fmt.Errorf("budget %s: amount must be positive", d.Name)
+	}
+	if d.WarnAt < 0 || d.WarnAt > 1 {
+		return fmt.Errorf("budget %s: warn_at must be between 0 and 1", d.Name)
+	}
+	if (d.TagKey == "") != (d.TagValue == "") {
+		return fmt.Errorf("budget %s: tag_key and tag_value must be set together", d.Name)
+	}
+	if _, err := cost.ParseMetric(d.Metric); err != nil {
+		return fmt.Errorf("budget %s: %w", d.Name, err)
+	}
+	return nil
+}
+
+// Evaluate computes month-to-date and projected month-end spend for every
+// budget. today is the first day without final data.
+//
+// A budget is BREACH once month-to-date spend reaches its amount, WARN when
+// month-to-date spend reaches WarnAt or the projection exceeds the amount,
+// and OK otherwise.
+func Evaluate(ctx context.Context, src cost.Source, defs []Definition, today time.Time) ([]Result, error) {
+	var results []Result
+	for _, def := range defs {
+		if err := def.Validate(); err != nil {
+			return nil, err
+		}
+
+		result, err := evaluate(ctx, src, def, today)
+		if err != nil {
+			return nil, fmt.Errorf("budget %s: %w", def.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func evaluate(ctx context.Context, src cost.Source, def Definition, today time.Time) (Result, error) {
+	def.Metric, _ = cost.ParseMetric(def.Metric)
+	if def.WarnAt == 0 {
+		def.WarnAt = DefaultWarnAt
+	}
+
+	config := forecast.Config{HistoryDays: historyDays, Metric: def.Metric, Today: today}
+	start, end := config.FetchRange()
+
+	buckets, err := src.GetCosts(ctx, cost.CostRequest{
+		Start:       start,
+		End:         end,
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{def.Metric},
+		Filter:      def.filter(),
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to fetch costs: %w", err)
+	}
+
+	// Collapse groups into one daily series
+	var points []anomaly.Point
+	for _, bucket := range buckets {
+		total := 0.0
+		for _, group := range bucket.Groups {
+			total += group.Metrics[def.Metric]
+		}
+		points = append(points, anomaly.Point{Date: bucket.Start, Value: total})
+	}
+
+	result := Result{Definition: def, Scope: def.scope()}
+
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range points {
+		if !p.Date.Before(monthStart) {
+			result.Actual += p.Value
+		}
+	}
+
+	forecasts, err := forecast.Project(map[string][]anomaly.Point{def.Name: points}, config)
+	if err == nil {
+		result.Projected = forecasts[0].Projections[0].Total
+	} else {
+		// Too little history for a model: extrapolate the month-to-date run rate
+		result.Projected = runRate(result.Actual, monthStart, today)
+	}
+
+	result.PercentUsed = result.Actual / def.Amount * 100
+	result.PercentProjected = result.Projected / def.Amount * 100
+	result.Status, result.Reason = classify(def, result.Actual, result.Projected)
+
+	return result, nil
+}
+
+func classify(def Definition, actual, projected float64) (Status, string) {
+	switch {
+	case actual >= def.Amount:
+		return StatusBreach, "month-to-date spend exceeds budget"
+	case actual >= def.WarnAt*def.Amount:
+		return StatusWarn, fmt.Sprintf("month-to-date spend is over %.0f%% of budget", def.WarnAt*100)
+	case projected > def.Amount:
+		return StatusWarn, "projected to exceed budget by month end"
+	default:
+		return StatusOK, ""
+	}
+}
+
+func runRate(actual float64, monthStart, today time.Time) float64 {
+	elapsed := today.Sub(monthStart).Hours() / 24
+	if elapsed <= 0 {
+		return 0
+	}
+	days := monthStart.AddDate(0, 1, 0).Sub(monthStart).Hours() / 24
+	return actual / elapsed * days
+}
+
+// filter builds the Cost Explorer filter for the budget's scope
+func (d Definition) filter() *types.Expression {
+	var exprs []types.Expression
+	if d.Service != "" {
+		exprs = append(exprs, types.Expression{Dimensions: &types.DimensionValues{
+			Key: types.DimensionService, Values: []string{d.Service},
+		}})
+	}
+	if d.Account != "" {
+		exprs = append(exprs, types.Expression{Dimensions: &types.DimensionValues{
+			Key: types.DimensionLinkedAccount, Values: []string{d.Account},
+		}})
+	}
+	if d.TagKey != "" {
+		exprs = append(exprs, types.Expression{Tags: &types.TagValues{
+			Key: aws.String(d.TagKey), Values: []string{d.TagValue},
+		}})
+	}
+
+	switch len(exprs) {
+	case 0:
+		return nil
+	case 1:
+		return &exprs[0]
+	default:
+		return &types.Expression{And: exprs}
+	}
+}
+
+func (d Definition) scope() string {
+	var parts []string
+	if d.Service != "" {
+		parts = append(parts, "service="+d.Service)
+	}
+	if d.Account != "" {
+		parts = append(parts, "account="+d.Account)
+	}
+	if d.TagKey != "" {
+		parts = append(parts, d.TagKey+"="+d.TagValue)
+	}
+	if len(parts) == 0 {
+		return "all spend"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Worst returns the most severe status among results
+func Worst(results []Result) Status {
+	worst := StatusOK
+	for _, r := range results {
+		switch {
+		case r.Status == StatusBreach:
+			return StatusBreach
+		case r.Status == StatusWarn:
+			worst = StatusWarn
+		}
+	}
+	return worst
+}
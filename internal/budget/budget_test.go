@@ -0,0 +1,117 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// dailySource serves a flat daily total and records the last request
+type dailySource struct {
+	perDay float64
+	req    cost.CostRequest
+}
+
+func (s *dailySource) GetCosts(ctx context.Context, req cost.CostRequest) ([]cost.TimeBucket, error) {
+	s.req = req
+	var buckets []cost.TimeBucket
+	for day := req.Start; day.Before(req.End); day = day.AddDate(0, 0, 1) {
+		buckets = append(buckets, cost.TimeBucket{
+			Start:  day,
+			End:    day.AddDate(0, 0, 1),
+			Groups: []cost.GroupCost{{Metrics: map[string]float64{req.Metrics[0]: s.perDay}}},
+		})
+	}
+	return buckets, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	// Ten closed days in a 30-day month at $100/day: $1000 so far, $3000 projected
+	today := time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC)
+	src := &dailySource{perDay: 100}
+
+	tests := []struct {
+		name   string
+		amount float64
+		want   Status
+	}{
+		{"comfortably under", 5000, StatusOK},
+		{"projected over", 2500, StatusWarn},
+		{"month to date near limit", 1200, StatusWarn},
+		{"month to date over", 900, StatusBreach},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Evaluate(context.Background(), src, []Definition{{Name: "ec2", Amount: tt.amount, Service: "AmazonEC2"}}, today)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			r := results[0]
+			if r.Status != tt.want {
+				t.Errorf("Status = %s (%s), want %s", r.Status, r.Reason, tt.want)
+			}
+			if r.Actual != 1000 || r.Projected < 2999 || r.Projected > 3001 {
+				t.Errorf("Actual = %v, Projected = %v; want 1000, 3000", r.Actual, r.Projected)
+			}
+		})
+	}
+
+	if src.req.Filter == nil || src.req.Filter.Dimensions.Values[0] != "AmazonEC2" || len(src.req.GroupBy) != 0 {
+		t.Errorf("expected an ungrouped SERVICE-filtered request, got %+v", src.req)
+	}
+}
+
+func TestEvaluate_Invalid(t *testing.T) {
+	tests := []Definition{
+		{Amount: 10},
+		{Name: "zero"},
+		{Name: "tag", Amount: 10, TagKey: "team"},
+		{Name: "warn", Amount: 10, WarnAt: 1.5},
+		{Name: "metric", Amount: 10, Metric: "Bogus"},
+	}
+	for _, def := range tests {
+		if _, err := Evaluate(context.Background(), &dailySource{}, []Definition{def}, time.Now()); err == nil {
+			t.Errorf("Evaluate(%+v) should fail validation", def)
+		}
+	}
+}
+
+func TestDefinitionFilter(t *testing.T) {
+	if f := (Definition{}).filter(); f != nil {
+		t.Errorf("unscoped budget filter = %+v, want nil", f)
+	}
+
+	d := Definition{Service: "AmazonEC2", Account: "111", TagKey: "team", TagValue: "web"}
+	f := d.filter()
+	if f == nil || len(f.And) != 3 {
+		t.Fatalf("expected AND of three filters, got %+v", f)
+	}
+	if d.scope() != "service=AmazonEC2, account=111, team=web" {
+		t.Errorf("scope() = %q", d.scope())
+	}
+}
+
+func TestRunRate(t *testing.T) {
+	monthStart := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	if got := runRate(500, monthStart, monthStart.AddDate(0, 0, 5)); got != 3000 {
+		t.Errorf("runRate() = %v, want 3000", got)
+	}
+	if got := runRate(0, monthStart, monthStart); got != 0 {
+		t.Errorf("runRate() on the first of the month = %v, want 0", got)
+	}
+}
+
+func TestWorst(t *testing.T) {
+	if got := Worst(nil); got != StatusOK {
+		t.Errorf("Worst(nil) = %s", got)
+	}
+	if got := Worst([]Result{{Status: StatusWarn}, {Status: StatusOK}}); got != StatusWarn {
+		t.Errorf("Worst() = %s, want WARN", got)
+	}
+	if got := Worst([]Result{{Status: StatusWarn}, {Status: StatusBreach}}); got != StatusBreach {
+		t.Errorf("Worst() = %s, want BREACH", got)
+	}
+}
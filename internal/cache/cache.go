@@ -0,0 +1,292 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// DefaultOpenTTL is how long responses for periods that include today are kept.
+// Closed days are final in Cost Explorer and never expire.
+const DefaultOpenTTL = time.Hour
+
+// Cache is a content-addressed on-disk store of cost responses
+type Cache struct {
+	dir       string
+	namespace string // separates data from different AWS accounts
+	openTTL   time.Duration
+	now       func() time.Time
+}
+
+// Entry describes one cached response
+type Entry struct {
+	Key         string    `json:"key"`
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	Namespace   string    `json:"namespace"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Granularity string    `json:"granularity"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"` // zero means never
+	Expired     bool      `json:"expired"`
+}
+
+// record is the on-disk format of a cached response
+type record struct {
+	Namespace   string            `json:"namespace"`
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Granularity string            `json:"granularity"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at,omitempty"`
+	Buckets     []cost.TimeBucket `json:"buckets"`
+}
+
+// DefaultDir returns $XDG_CACHE_HOME/cost-blame, falling back to ~/.cache/cost-blame
+func DefaultDir() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "cost-blame"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "cost-blame"), nil
+}
+
+// New creates a cache rooted at dir. namespace keeps responses from different
+// accounts or credentials apart; openTTL of zero uses DefaultOpenTTL.
+func New(dir, namespace string, openTTL time.Duration) *Cache {
+	if openTTL == 0 {
+		openTTL = DefaultOpenTTL
+	}
+	return &Cache{
+		dir:       dir,
+		namespace: namespace,
+		openTTL:   openTTL,
+		now:       time.Now,
+	}
+}
+
+// Dir returns the cache directory
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Wrap returns a cost.Source that serves repeated requests from the cache
+// and forwards misses to next
+func (c *Cache) Wrap(next cost.Source) cost.Source {
+	return &cachedSource{cache: c, next: next}
+}
+
+type cachedSource struct {
+	cache *Cache
+	next  cost.Source
+}
+
+func (s *cachedSource) GetCosts(ctx context.Context, req cost.CostRequest) ([]cost.TimeBucket, error) {
+	key, err := s.cache.key(req)
+	if err != nil {
+		return s.next.GetCosts(ctx, req)
+	}
+
+	if buckets, ok := s.cache.get(key); ok {
+		return buckets, nil
+	}
+
+	buckets, err := s.next.GetCosts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caching is best effort; a failed write only costs a future API call
+	_ = s.cache.put(key, req, buckets)
+	return buckets, nil
+}
+
+// key hashes everything that determines the response: time period,
+// granularity, metrics, group-by and filter
+func (c *Cache) key(req cost.CostRequest) (string, error) {
+	payload, err := json.Marshal(struct {
+		Namespace string
+		Request   cost.CostRequest
+	}{c.namespace, req})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+func (c *Cache) get(key string) ([]cost.TimeBucket, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, false
+	}
+	if c.expired(rec) {
+		return nil, false
+	}
+	return rec.Buckets, true
+}
+
+func (c *Cache) put(key string, req cost.CostRequest, buckets []cost.TimeBucket) error {
+	now := c.now().UTC()
+	rec := record{
+		Namespace:   c.namespace,
+		Start:       req.Start,
+		End:         req.End,
+		Granularity: string(req.Granularity),
+		CreatedAt:   now,
+		Buckets:     buckets,
+	}
+
+	// Periods that reach into today are still changing; closed days are final
+	if req.End.After(now.Truncate(24 * time.Hour)) {
+		rec.ExpiresAt = now.Add(c.openTTL)
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write atomically so concurrent runs never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *Cache) expired(rec record) bool {
+	return !rec.ExpiresAt.IsZero() && !c.now().Before(rec.ExpiresAt)
+}
+
+// List returns every cached entry, newest first
+func (c *Cache) List() ([]Entry, error) {
+	var entries []Entry
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		entry := Entry{
+			Key:  strings.TrimSuffix(filepath.Base(path), ".json"),
+			Path: path,
+			Size: info.Size(),
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			// Unreadable entries are reported as expired so prune removes them
+			entry.Expired = true
+			entries = append(entries, entry)
+			return nil
+		}
+
+		entry.Namespace = rec.Namespace
+		entry.Start = rec.Start
+		entry.End = rec.End
+		entry.Granularity = rec.Granularity
+		entry.CreatedAt = rec.CreatedAt
+		entry.ExpiresAt = rec.ExpiresAt
+		entry.Expired = c.expired(rec)
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+// Prune removes expired and unreadable entries and returns how many were removed
+func (c *Cache) Prune() (int, error) {
+	entries, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.Expired {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Clear removes every entry and returns how many were removed
+func (c *Cache) Clear() (int, error) {
+	removed := 0
+	err := c.walk(func(path string, info fs.FileInfo) error {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed++
+		return nil
+	})
+	return removed, err
+}
+
+func (c *Cache) walk(fn func(path string, info fs.FileInfo) error) error {
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(path, info)
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// countingSource returns one bucket per request and counts calls
+type countingSource struct {
+	calls int
+}
+
+func (s *countingSource) GetCosts(ctx context.Context, req cost.CostRequest) ([]cost.TimeBucket, error) {
+	s.calls++
+	return []cost.TimeBucket{{
+		Start:  req.Start,
+		End:    req.End,
+		Groups: []cost.GroupCost{{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"UnblendedCost": float64(s.calls)}}},
+	}}, nil
+}
+
+func closedRequest() cost.CostRequest {
+	return cost.CostRequest{
+		Start:       time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 9, 8, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{"UnblendedCost"},
+		GroupBy:     []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")}},
+	}
+}
+
+func openRequest() cost.CostRequest {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	req := closedRequest()
+	req.Start = today.AddDate(0, 0, -7)
+	req.End = today.AddDate(0, 0, 1)
+	return req
+}
+
+func TestCachedSource_HitAndMiss(t *testing.T) {
+	next := &countingSource{}
+	src := New(t.TempDir(), "111111111111", 0).Wrap(next)
+	ctx := context.Background()
+
+	first, err := src.GetCosts(ctx, closedRequest())
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+	second, err := src.GetCosts(ctx, closedRequest())
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+
+	if next.calls != 1 {
+		t.Errorf("expected 1 upstream call, got %d", next.calls)
+	}
+	if second[0].Groups[0].Metrics["UnblendedCost"] != first[0].Groups[0].Metrics["UnblendedCost"] {
+		t.Error("cached response differs from original")
+	}
+	if !second[0].Start.Equal(first[0].Start) {
+		t.Errorf("cached bucket start = %v, want %v", second[0].Start, first[0].Start)
+	}
+
+	// Any change to the request is a different key
+	req := closedRequest()
+	req.Filter = &types.Expression{Dimensions: &types.DimensionValues{Key: types.DimensionLinkedAccount, Values: []string{"222"}}}
+	if _, err := src.GetCosts(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if next.calls != 2 {
+		t.Errorf("different filter should miss the cache, got %d calls", next.calls)
+	}
+}
+
+func TestCachedSource_NamespaceIsolation(t *testing.T) {
+	dir := t.TempDir()
+	next := &countingSource{}
+	ctx := context.Background()
+
+	New(dir, "111111111111", 0).Wrap(next).GetCosts(ctx, closedRequest())
+	New(dir, "222222222222", 0).Wrap(next).GetCosts(ctx, closedRequest())
+
+	if next.calls != 2 {
+		t.Errorf("different namespaces must not share entries, got %d calls", next.calls)
+	}
+}
+
+func TestCachedSource_OpenPeriodExpires(t *testing.T) {
+	c := New(t.TempDir(), "", time.Hour)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	next := &countingSource{}
+	src := c.Wrap(next)
+	ctx := context.Background()
+
+	src.GetCosts(ctx, openRequest())
+	src.GetCosts(ctx, openRequest())
+	if next.calls != 1 {
+		t.Fatalf("open period should be cached within TTL, got %d calls", next.calls)
+	}
+
+	now = now.Add(2 * time.Hour)
+	src.GetCosts(ctx, openRequest())
+	if next.calls != 2 {
+		t.Errorf("open period should expire after TTL, got %d calls", next.calls)
+	}
+
+	// Closed periods never expire
+	src.GetCosts(ctx, closedRequest())
+	now = now.Add(365 * 24 * time.Hour)
+	src.GetCosts(ctx, closedRequest())
+	if next.calls != 3 {
+		t.Errorf("closed period should never expire, got %d calls", next.calls)
+	}
+}
+
+func TestCachedSource_OpenPeriodFollowsClock(t *testing.T) {
+	// On the injected clock, the request still reaches into today
+	c := New(t.TempDir(), "", time.Hour)
+	now := time.Date(2024, 9, 5, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	next := &countingSource{}
+	src := c.Wrap(next)
+	ctx := context.Background()
+
+	src.GetCosts(ctx, closedRequest())
+	now = now.Add(2 * time.Hour)
+	src.GetCosts(ctx, closedRequest())
+	if next.calls != 2 {
+		t.Errorf("period open on the cache's clock should expire after TTL, got %d calls", next.calls)
+	}
+}
+
+func TestListPruneClear(t *testing.T) {
+	c := New(t.TempDir(), "111111111111", time.Hour)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	src := c.Wrap(&countingSource{})
+	ctx := context.Background()
+	src.GetCosts(ctx, closedRequest())
+	src.GetCosts(ctx, openRequest())
+
+	// A corrupt entry is listed as expired
+	corrupt := c.path("ff00000000000000")
+	os.MkdirAll(c.Dir()+"/ff", 0o755)
+	os.WriteFile(corrupt, []byte("{not json"), 0o644)
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("List() returned %d entries, want 3", len(entries))
+	}
+
+	now = now.Add(2 * time.Hour)
+	removed, err := c.Prune()
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Prune() removed %d entries, want 2 (expired + corrupt)", removed)
+	}
+
+	entries, _ = c.List()
+	if len(entries) != 1 || !entries[0].ExpiresAt.IsZero() || entries[0].Namespace != "111111111111" {
+		t.Errorf("expected only the closed entry to remain, got %+v", entries)
+	}
+
+	removed, err = c.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Clear() removed %d entries, want 1", removed)
+	}
+}
+
+func TestList_MissingDir(t *testing.T) {
+	c := New(t.TempDir()+"/missing", "", 0)
+	entries, err := c.List()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List() on missing dir = %v, %v; want empty, nil", entries, err)
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg")
+	dir, err := DefaultDir()
+	if err != nil || dir != "/tmp/xdg/cost-blame" {
+		t.Errorf("DefaultDir() = %q, %v", dir, err)
+	}
+}
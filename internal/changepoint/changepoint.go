@@ -0,0 +1,117 @@
+package changepoint
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// daysPerMonth converts a daily level change into a monthly run-rate impact
+const daysPerMonth = 365.25 / 12
+
+// Config holds configuration for changepoint detection
+type Config struct {
+	HistoryDays    int       // Number of days of history to segment (default: 90)
+	MinSegmentDays int       // Shortest run of days that counts as a level (default: 7)
+	Penalty        float64   // Multiplier on the per-changepoint penalty; higher finds fewer shifts (default: 1)
+	MinImpact      float64   // Minimum absolute monthly impact to report
+	Metric         string    // Cost Explorer metric (default: UnblendedCost)
+	Today          time.Time // First day without final data (default: today UTC)
+}
+
+// Shift is a lasting change in a group's daily cost level
+type Shift struct {
+	Key           string
+	Date          time.Time // first day at the new level
+	Before        float64   // mean daily cost of the preceding segment
+	After         float64   // mean daily cost from Date until the next shift
+	PercentChange float64
+	MonthlyImpact float64 // (After - Before) as a monthly run rate
+	Metric        string
+}
+
+func (c Config) withDefaults() Config {
+	if c.HistoryDays == 0 {
+		c.HistoryDays = 90
+	}
+	if c.MinSegmentDays == 0 {
+		c.MinSegmentDays = 7
+	}
+	if c.Penalty == 0 {
+		c.Penalty = 1
+	}
+	if c.Metric == "" {
+		c.Metric = cost.DefaultMetric
+	}
+	if c.Today.IsZero() {
+		c.Today = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	return c
+}
+
+// FetchRange returns the [start, end) range of daily data Detect segments
+func (c Config) FetchRange() (time.Time, time.Time) {
+	c = c.withDefaults()
+	return c.Today.AddDate(0, 0, -c.HistoryDays), c.Today
+}
+
+// Detect segments each group's daily series and returns every level shift
+// whose monthly impact reaches MinImpact, largest impact first
+func Detect(series map[string][]anomaly.Point, config Config) []Shift {
+	config = config.withDefaults()
+	start, end := config.FetchRange()
+
+	var shifts []Shift
+	for key, points := range series {
+		daily := anomaly.Align(points, start, end)
+		changes := Segment(daily, config.MinSegmentDays, config.Penalty)
+
+		bounds := append(append([]int{0}, changes...), len(daily))
+		for i := 1; i < len(bounds)-1; i++ {
+			before := mean(daily[bounds[i-1]:bounds[i]])
+			after := mean(daily[bounds[i]:bounds[i+1]])
+
+			impact := (after - before) * daysPerMonth
+			if math.Abs(impact) < config.MinImpact {
+				continue
+			}
+
+			pctChange := 0.0
+			if before > 0 {
+				pctChange = (after - before) / before * 100
+			} else if after > 0 {
+				pctChange = 9999 // Effectively infinite for new spenders
+			}
+
+			shifts = append(shifts, Shift{
+				Key:           key,
+				Date:          start.AddDate(0, 0, bounds[i]),
+				Before:        before,
+				After:         after,
+				PercentChange: pctChange,
+				MonthlyImpact: impact,
+				Metric:        config.Metric,
+			})
+		}
+	}
+
+	sort.Slice(shifts, func(i, j int) bool {
+		return math.Abs(shifts[i].MonthlyImpact) > math.Abs(shifts[j].MonthlyImpact)
+	})
+
+	return shifts
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,61 @@
+package changepoint
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+)
+
+func TestDetect(t *testing.T) {
+	config := Config{Today: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), HistoryDays: 40}
+	start, _ := config.FetchRange()
+
+	toPoints := func(values []float64) []anomaly.Point {
+		points := make([]anomaly.Point, len(values))
+		for i, v := range values {
+			points[i] = anomaly.Point{Date: start.AddDate(0, 0, i), Value: v}
+		}
+		return points
+	}
+
+	series := map[string][]anomaly.Point{
+		"AmazonEC2": toPoints(steps(20, 100, 300)),
+		"AmazonS3":  toPoints(steps(20, 10, 12)),
+		"NewThing":  toPoints(append(make([]float64, 30), steps(10, 50)...)),
+	}
+
+	shifts := Detect(series, config)
+	if len(shifts) != 3 {
+		t.Fatalf("Detect() returned %d shifts, want 3: %+v", len(shifts), shifts)
+	}
+
+	ec2 := shifts[0]
+	if ec2.Key != "AmazonEC2" || !ec2.Date.Equal(start.AddDate(0, 0, 20)) {
+		t.Errorf("expected EC2 shift on %v first, got %+v", start.AddDate(0, 0, 20), ec2)
+	}
+	if math.Abs(ec2.Before-100) > 0.1 || math.Abs(ec2.After-300) > 0.1 || math.Abs(ec2.PercentChange-200) > 0.5 {
+		t.Errorf("unexpected EC2 levels: %+v", ec2)
+	}
+	if math.Abs(ec2.MonthlyImpact-200*daysPerMonth) > 5 {
+		t.Errorf("MonthlyImpact = %v, want about %v", ec2.MonthlyImpact, 200*daysPerMonth)
+	}
+	if ec2.Metric != "UnblendedCost" {
+		t.Errorf("Metric = %q, want default UnblendedCost", ec2.Metric)
+	}
+
+	if shifts[1].Key != "NewThing" || shifts[1].PercentChange != 9999 {
+		t.Errorf("expected new spender marked with 9999%%, got %+v", shifts[1])
+	}
+
+	// MinImpact drops the small S3 shift
+	config.MinImpact = 100
+	if shifts := Detect(series, config); len(shifts) != 2 {
+		t.Errorf("Detect(MinImpact 100) returned %d shifts, want 2", len(shifts))
+	}
+
+	if got := Detect(nil, config); len(got) != 0 {
+		t.Errorf("Detect(nil) = %+v, want none", got)
+	}
+}
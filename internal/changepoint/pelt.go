@@ -0,0 +1,125 @@
+package changepoint
+
+import (
+	"math"
+	"sort"
+)
+
+// spikeWidth is the longest one-off spike, in days, that is smoothed away
+// before segmenting so it isn't mistaken for two level shifts
+const spikeWidth = 2
+
+// Segment splits values into segments of constant mean with PELT (pruned
+// exact linear time) and returns the index at which each new segment starts.
+// Segments are at least minSize long, and each changepoint must reduce the
+// squared error, in units of the noise variance, by more than
+// penalty·2·ln(n) (the BIC cost of a changepoint when penalty is 1).
+func Segment(values []float64, minSize int, penalty float64) []int {
+	n := len(values)
+	if minSize < 1 {
+		minSize = 1
+	}
+	if n < 2*minSize {
+		return nil
+	}
+
+	sigma := noiseLevel(values)
+	if sigma == 0 {
+		return nil
+	}
+	variance := sigma * sigma
+	beta := penalty * 2 * math.Log(float64(n))
+
+	// Prefix sums give the squared error of any segment in O(1)
+	sum := make([]float64, n+1)
+	sumSq := make([]float64, n+1)
+	for i, v := range medianFilter(values, spikeWidth) {
+		sum[i+1] = sum[i] + v
+		sumSq[i+1] = sumSq[i] + v*v
+	}
+	segmentCost := func(from, to int) float64 {
+		s := sum[to] - sum[from]
+		sse := sumSq[to] - sumSq[from] - s*s/float64(to-from)
+		return math.Max(0, sse) / variance
+	}
+
+	// best[t] is the minimal penalized cost of values[:t]; last[t] is where
+	// its final segment starts
+	best := make([]float64, n+1)
+	last := make([]int, n+1)
+	best[0] = -beta
+	for t := 1; t < minSize; t++ {
+		best[t] = math.Inf(1)
+	}
+
+	candidates := []int{0}
+	for t := minSize; t <= n; t++ {
+		best[t] = math.Inf(1)
+		for _, tau := range candidates {
+			if t-tau < minSize {
+				continue
+			}
+			if c := best[tau] + segmentCost(tau, t) + beta; c < best[t] {
+				best[t], last[t] = c, tau
+			}
+		}
+
+		// Drop candidates that can never start the final segment again
+		kept := candidates[:0]
+		for _, tau := range candidates {
+			if t-tau < minSize || best[tau]+segmentCost(tau, t) <= best[t] {
+				kept = append(kept, tau)
+			}
+		}
+		candidates = append(kept, t)
+	}
+
+	var changes []int
+	for t := last[n]; t > 0; t = last[t] {
+		changes = append(changes, t)
+	}
+	sort.Ints(changes)
+	return changes
+}
+
+// noiseLevel estimates the day-to-day standard deviation from first
+// differences, which a level shift barely affects. The median absolute
+// difference is used when it is non-zero, otherwise the mean.
+func noiseLevel(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	diffs := make([]float64, len(values)-1)
+	mean := 0.0
+	for i := range diffs {
+		diffs[i] = math.Abs(values[i+1] - values[i])
+		mean += diffs[i] / float64(len(diffs))
+	}
+	sort.Float64s(diffs)
+
+	// Differences of independent noise have variance 2σ²
+	if median := diffs[len(diffs)/2]; median > 0 {
+		return 1.4826 * median / math.Sqrt2
+	}
+	return 1.2533 * mean / math.Sqrt2
+}
+
+// medianFilter replaces each value by the median of the values within radius
+// of it. Runs shorter than radius+1 are removed while step edges are kept.
+func medianFilter(values []float64, radius int) []float64 {
+	filtered := make([]float64, len(values))
+	window := make([]float64, 0, 2*radius+1)
+	for i := range values {
+		lo, hi := i-radius, i+radius+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(values) {
+			hi = len(values)
+		}
+		window = append(window[:0], values[lo:hi]...)
+		sort.Float64s(window)
+		filtered[i] = window[len(window)/2]
+	}
+	return filtered
+}
@@ -0,0 +1,61 @@
+package changepoint
+
+import (
+	"reflect"
+	"testing"
+)
+
+// steps returns a noisy series with one level per step, each lasting days
+func steps(days int, levels ...float64) []float64 {
+	var values []float64
+	for _, level := range levels {
+		for i := 0; i < days; i++ {
+			values = append(values, level+float64(i%3)-1)
+		}
+	}
+	return values
+}
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		minSize int
+		want    []int
+	}{
+		{"flat", steps(30, 100), 7, nil},
+		{"single step up", steps(20, 100, 300), 7, []int{20}},
+		{"up then down", steps(15, 100, 300, 120), 7, []int{15, 30}},
+		{"one-day spike is not a shift", append(append(steps(20, 100), 900), steps(20, 100)...), 7, nil},
+		{"constant", []float64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5}, 3, nil},
+		{"too short", []float64{1, 100}, 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Segment(tt.values, tt.minSize, 1)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegment_Penalty(t *testing.T) {
+	// A small step is found at the default penalty but not at a high one
+	values := steps(20, 100, 106)
+	if got := Segment(values, 7, 1); len(got) != 1 {
+		t.Errorf("Segment(penalty 1) = %v, want one change", got)
+	}
+	if got := Segment(values, 7, 500); got != nil {
+		t.Errorf("Segment(penalty 500) = %v, want none", got)
+	}
+}
+
+func TestMedianFilter(t *testing.T) {
+	got := medianFilter([]float64{1, 1, 9, 1, 1, 5, 5, 5, 5}, 2)
+	want := []float64{1, 1, 1, 1, 5, 5, 5, 5, 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("medianFilter() = %v, want %v", got, want)
+	}
+}
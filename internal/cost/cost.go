@@ -4,71 +4,57 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
 	"github.com/pfrederiksen/cost-blame/internal/timewin"
 )
 
 // Delta represents cost change between two periods
 type Delta struct {
-	Key            string  // Group key (service name, tag value, etc.)
-	CurrentCost    float64
-	PriorCost      float64
-	AbsoluteDelta  float64
-	PercentChange  float64
-	IsNewSpender   bool
-	Currency       string
+	Key           string // Group key (service name, tag value, etc.)
+	CurrentCost   float64
+	PriorCost     float64
+	AbsoluteDelta float64
+	PercentChange float64
+	IsNewSpender  bool
+	Currency      string
+	Metric        string // Cost Explorer metric the costs are measured in
+	PerDay        bool   // costs are per-day averages because the periods differ in length
+
+	// Cost in each day or hour of both periods, oldest first, set when
+	// QueryParams.Series is true. Series values are never per-day averaged.
+	CurrentSeries []SeriesPoint `json:",omitempty"`
+	PriorSeries   []SeriesPoint `json:",omitempty"`
+
+	// Price/volume decomposition, set when QueryParams.ExplainRate is true.
+	// VolumeEffect + RateEffect + MixEffect = AbsoluteDelta.
+	Decomposed   bool
+	VolumeEffect float64 // change in usage quantity at the prior unit rate
+	RateEffect   float64 // change in unit rate at the current usage quantity
+	MixEffect    float64 // usage types that started, stopped or have no quantity
 }
 
 // QueryParams holds parameters for Cost Explorer queries
 type QueryParams struct {
-	Window       *timewin.Window
-	Granularity  string   // DAILY or HOURLY
-	GroupBy      string   // service, linked_account, region, usage_type
-	TagKey       string   // optional tag dimension
-	TagValues    []string // optional filter for specific tag values
-	AccountIDs   []string // optional filter for specific accounts
+	Window      *timewin.Window
+	Granularity string   // DAILY or HOURLY
+	GroupBy     string   // service, linked_account, region, usage_type, tag, or two joined by a comma
+	TagKey      string   // optional tag dimension
+	TagValues   []string // optional filter for specific tag values
+	AccountIDs  []string // optional filter for specific accounts
+	Metric      string   // Cost Explorer metric (default: UnblendedCost)
+	ExplainRate bool     // split deltas into volume, rate and mix effects
+	Series      bool     // keep the per-day or per-hour cost of each period
 }
 
 // Query fetches cost data for current and prior periods and computes deltas
-func Query(ctx context.Context, client *costexplorer.Client, params QueryParams) ([]Delta, error) {
+func Query(ctx context.Context, src Source, params QueryParams) ([]Delta, error) {
 	// Build GroupBy dimensions
-	var groupDefs []types.GroupDefinition
-
-	switch params.GroupBy {
-	case "service":
-		groupDefs = append(groupDefs, types.GroupDefinition{
-			Type: types.GroupDefinitionType("DIMENSION"),
-			Key:  aws.String("SERVICE"),
-		})
-	case "linked_account":
-		groupDefs = append(groupDefs, types.GroupDefinition{
-			Type: types.GroupDefinitionType("DIMENSION"),
-			Key:  aws.String("LINKED_ACCOUNT"),
-		})
-	case "region":
-		groupDefs = append(groupDefs, types.GroupDefinition{
-			Type: types.GroupDefinitionType("DIMENSION"),
-			Key:  aws.String("REGION"),
-		})
-	case "usage_type":
-		groupDefs = append(groupDefs, types.GroupDefinition{
-			Type: types.GroupDefinitionType("DIMENSION"),
-			Key:  aws.String("USAGE_TYPE"),
-		})
-	default:
-		return nil, fmt.Errorf("unsupported group-by: %s", params.GroupBy)
-	}
-
-	// Add tag grouping if specified
-	if params.TagKey != "" {
-		groupDefs = append(groupDefs, types.GroupDefinition{
-			Type: types.GroupDefinitionType("TAG"),
-			Key:  aws.String(params.TagKey),
-		})
+	groupDefs, err := GroupDefinitions(params.GroupBy, params.TagKey)
+	if err != nil {
+		return nil, err
 	}
 
 	// Determine granularity
@@ -82,26 +68,64 @@ func Query(ctx context.Context, client *costexplorer.Client, params QueryParams)
 		gran = types.GranularityDaily
 	}
 
+	metric, err := ParseMetric(params.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := buildFilter(params)
+
 	// Query current period
-	currentCosts, err := queryCostAndUsage(ctx, client,
-		timewin.FormatCE(params.Window.CurrentStart),
-		timewin.FormatCE(params.Window.CurrentEnd),
-		gran, groupDefs, params.AccountIDs)
+	currentBuckets, err := queryBuckets(ctx, src,
+		params.Window.CurrentStart, params.Window.CurrentEnd,
+		gran, metric, groupDefs, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query current period: %w", err)
 	}
 
 	// Query prior period
-	priorCosts, err := queryCostAndUsage(ctx, client,
-		timewin.FormatCE(params.Window.PriorStart),
-		timewin.FormatCE(params.Window.PriorEnd),
-		gran, groupDefs, params.AccountIDs)
+	priorBuckets, err := queryBuckets(ctx, src,
+		params.Window.PriorStart, params.Window.PriorEnd,
+		gran, metric, groupDefs, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query prior period: %w", err)
 	}
 
+	currentCosts := sumBuckets(currentBuckets, metric)
+	priorCosts := sumBuckets(priorBuckets, metric)
+
+	// Periods of different lengths are compared as per-day rates
+	perDay := params.Window.Uneven()
+	if perDay {
+		scaleCosts(currentCosts, 1/params.Window.CurrentDays())
+		scaleCosts(priorCosts, 1/params.Window.PriorDays())
+	}
+
 	// Compute deltas
 	deltas := computeDeltas(currentCosts, priorCosts)
+	for i := range deltas {
+		deltas[i].Metric = metric
+		deltas[i].PerDay = perDay
+		if !IsMonetary(metric) {
+			deltas[i].Currency = ""
+		}
+	}
+
+	if params.Series {
+		w, step := params.Window, seriesStep(gran)
+		current := bucketSeries(currentBuckets, metric, w.CurrentStart, w.CurrentEnd, step)
+		prior := bucketSeries(priorBuckets, metric, w.PriorStart, w.PriorEnd, step)
+		for i := range deltas {
+			deltas[i].CurrentSeries = seriesPoints(current[deltas[i].Key], w.CurrentStart, w.CurrentEnd, step)
+			deltas[i].PriorSeries = seriesPoints(prior[deltas[i].Key], w.PriorStart, w.PriorEnd, step)
+		}
+	}
+
+	if params.ExplainRate {
+		if err := explainRate(ctx, src, params, gran, metric, groupDefs, filter, deltas); err != nil {
+			return nil, err
+		}
+	}
 
 	// Sort by absolute delta descending
 	sort.Slice(deltas, func(i, j int) bool {
@@ -111,77 +135,75 @@ func Query(ctx context.Context, client *costexplorer.Client, params QueryParams)
 	return deltas, nil
 }
 
-func queryCostAndUsage(ctx context.Context, client *costexplorer.Client, start, end string, gran types.Granularity, groupDefs []types.GroupDefinition, accountIDs []string) (map[string]float64, error) {
-	input := &costexplorer.GetCostAndUsageInput{
-		TimePeriod: &types.DateInterval{
-			Start: aws.String(start),
-			End:   aws.String(end),
-		},
+func queryBuckets(ctx context.Context, src Source, start, end time.Time, gran types.Granularity, metric string, groupDefs []types.GroupDefinition, filter *types.Expression) ([]TimeBucket, error) {
+	return src.GetCosts(ctx, CostRequest{
+		Start:       start,
+		End:         end,
 		Granularity: gran,
-		Metrics:     []string{"UnblendedCost"},
+		Metrics:     []string{metric},
 		GroupBy:     groupDefs,
+		Filter:      filter,
+	})
+}
+
+// sumBuckets totals each group's cost over all buckets
+func sumBuckets(buckets []TimeBucket, metric string) map[string]float64 {
+	costs := make(map[string]float64)
+	for _, bucket := range buckets {
+		for _, group := range bucket.Groups {
+			// Build composite key from all group dimensions
+			key := GroupKey(group.Keys)
+
+			// Sum costs across time periods
+			if amount, ok := group.Metrics[metric]; ok {
+				costs[key] += amount
+			}
+		}
 	}
 
-	// Add account filter if specified
-	if len(accountIDs) > 0 {
-		values := make([]string, len(accountIDs))
-		copy(values, accountIDs)
-		input.Filter = &types.Expression{
+	return costs
+}
+
+// buildFilter restricts a query to the requested accounts and tag values
+func buildFilter(params QueryParams) *types.Expression {
+	var exprs []types.Expression
+
+	if len(params.AccountIDs) > 0 {
+		values := make([]string, len(params.AccountIDs))
+		copy(values, params.AccountIDs)
+		exprs = append(exprs, types.Expression{
 			Dimensions: &types.DimensionValues{
 				Key:    types.DimensionLinkedAccount,
 				Values: values,
 			},
-		}
+		})
 	}
 
-	costs := make(map[string]float64)
-
-	// Handle pagination manually
-	var nextToken *string
-	for {
-		if nextToken != nil {
-			input.NextPageToken = nextToken
-		}
-
-		output, err := client.GetCostAndUsage(ctx, input)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, result := range output.ResultsByTime {
-			for _, group := range result.Groups {
-				// Build composite key from all group dimensions
-				key := buildGroupKey(group.Keys)
-
-				// Sum costs across time periods
-				if len(group.Metrics) > 0 {
-					if unblended, ok := group.Metrics["UnblendedCost"]; ok && unblended.Amount != nil {
-						amount, _ := strconv.ParseFloat(*unblended.Amount, 64)
-						costs[key] += amount
-					}
-				}
-			}
-		}
-
-		nextToken = output.NextPageToken
-		if nextToken == nil {
-			break
-		}
+	if params.TagKey != "" && len(params.TagValues) > 0 {
+		values := make([]string, len(params.TagValues))
+		copy(values, params.TagValues)
+		exprs = append(exprs, types.Expression{
+			Tags: &types.TagValues{
+				Key:    aws.String(params.TagKey),
+				Values: values,
+			},
+		})
 	}
 
-	return costs, nil
+	switch len(exprs) {
+	case 0:
+		return nil
+	case 1:
+		return &exprs[0]
+	default:
+		return &types.Expression{And: exprs}
+	}
 }
 
-func buildGroupKey(keys []string) string {
-	if len(keys) == 0 {
-		return "Unknown"
-	}
-	// Join multiple dimensions with " | "
-	result := keys[0]
-	for i := 1; i < len(keys); i++ {
-		result += " | " + keys[i]
+func scaleCosts(costs map[string]float64, factor float64) {
+	for key := range costs {
+		costs[key] *= factor
 	}
-	return result
 }
 
 func computeDeltas(current, prior map[string]float64) []Delta {
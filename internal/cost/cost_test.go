@@ -92,9 +92,9 @@ func TestBuildGroupKey(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := buildGroupKey(tt.keys)
+			got := GroupKey(tt.keys)
 			if got != tt.want {
-				t.Errorf("buildGroupKey() = %v, want %v", got, tt.want)
+				t.Errorf("GroupKey() = %v, want %v", got, tt.want)
 			}
 		})
 	}
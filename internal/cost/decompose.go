@@ -0,0 +1,152 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// usageCost is the cost and usage quantity of one usage type within a group
+type usageCost struct {
+	amount   float64
+	quantity float64
+}
+
+// explainRate splits each delta into volume, rate and mix effects by
+// re-querying both periods with usage quantities per usage type
+func explainRate(ctx context.Context, src Source, params QueryParams, gran types.Granularity, metric string, groupDefs []types.GroupDefinition, filter *types.Expression, deltas []Delta) error {
+	if !IsMonetary(metric) {
+		return fmt.Errorf("rate explanation needs a cost metric, not %s", metric)
+	}
+
+	defs, usageIdx, err := usageTypeGroupDefs(groupDefs)
+	if err != nil {
+		return err
+	}
+	// When USAGE_TYPE was added only for the breakdown it is not part of the delta key
+	keyLen := len(groupDefs)
+
+	current, err := queryUsageBreakdown(ctx, src, params.Window.CurrentStart, params.Window.CurrentEnd, gran, metric, defs, usageIdx, keyLen, filter)
+	if err != nil {
+		return fmt.Errorf("failed to query current usage: %w", err)
+	}
+	prior, err := queryUsageBreakdown(ctx, src, params.Window.PriorStart, params.Window.PriorEnd, gran, metric, defs, usageIdx, keyLen, filter)
+	if err != nil {
+		return fmt.Errorf("failed to query prior usage: %w", err)
+	}
+
+	// Match the per-day rates of uneven periods; unit rates are unchanged
+	if params.Window.Uneven() {
+		scaleUsage(current, 1/params.Window.CurrentDays())
+		scaleUsage(prior, 1/params.Window.PriorDays())
+	}
+
+	for i := range deltas {
+		d := &deltas[i]
+		d.VolumeEffect, d.RateEffect, d.MixEffect = decompose(current[d.Key], prior[d.Key])
+		d.Decomposed = true
+	}
+	return nil
+}
+
+func scaleUsage(usage map[string]map[string]usageCost, factor float64) {
+	for _, byType := range usage {
+		for usageType, u := range byType {
+			byType[usageType] = usageCost{amount: u.amount * factor, quantity: u.quantity * factor}
+		}
+	}
+}
+
+// usageTypeGroupDefs adds a USAGE_TYPE grouping unless one is already present
+// and returns the index of the usage type among the group keys
+func usageTypeGroupDefs(groupDefs []types.GroupDefinition) ([]types.GroupDefinition, int, error) {
+	for i, def := range groupDefs {
+		if def.Type == types.GroupDefinitionTypeDimension && aws.ToString(def.Key) == "USAGE_TYPE" {
+			return groupDefs, i, nil
+		}
+	}
+
+	// Cost Explorer accepts at most two group-by definitions
+	if len(groupDefs) >= 2 {
+		return nil, 0, fmt.Errorf("rate explanation cannot be combined with a two-level or tag group-by")
+	}
+
+	defs := append(append([]types.GroupDefinition{}, groupDefs...), types.GroupDefinition{
+		Type: types.GroupDefinitionTypeDimension,
+		Key:  aws.String("USAGE_TYPE"),
+	})
+	return defs, len(defs) - 1, nil
+}
+
+// queryUsageBreakdown returns cost and quantity per usage type, indexed by
+// the delta key built from the first keyLen group keys
+func queryUsageBreakdown(ctx context.Context, src Source, start, end time.Time, gran types.Granularity, metric string, groupDefs []types.GroupDefinition, usageIdx, keyLen int, filter *types.Expression) (map[string]map[string]usageCost, error) {
+	buckets, err := src.GetCosts(ctx, CostRequest{
+		Start:       start,
+		End:         end,
+		Granularity: gran,
+		Metrics:     []string{metric, "UsageQuantity"},
+		GroupBy:     groupDefs,
+		Filter:      filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := make(map[string]map[string]usageCost)
+	for _, bucket := range buckets {
+		for _, group := range bucket.Groups {
+			if len(group.Keys) != len(groupDefs) {
+				continue
+			}
+
+			key := GroupKey(group.Keys[:keyLen])
+			usageType := group.Keys[usageIdx]
+
+			if breakdown[key] == nil {
+				breakdown[key] = make(map[string]usageCost)
+			}
+			uc := breakdown[key][usageType]
+			uc.amount += group.Metrics[metric]
+			uc.quantity += group.Metrics["UsageQuantity"]
+			breakdown[key][usageType] = uc
+		}
+	}
+
+	return breakdown, nil
+}
+
+// decompose splits the cost change of one group into three effects that sum
+// to the total change:
+//
+//   - volume: change in quantity priced at the prior unit rate, (q1-q0)*r0
+//   - rate: change in unit rate applied to current quantity, (r1-r0)*q1
+//   - mix: cost of usage types that only appear in one period or have no
+//     usage quantity (fees, credits, support), where no rate exists
+func decompose(current, prior map[string]usageCost) (volume, rate, mix float64) {
+	usageTypes := make(map[string]bool)
+	for u := range current {
+		usageTypes[u] = true
+	}
+	for u := range prior {
+		usageTypes[u] = true
+	}
+
+	for u := range usageTypes {
+		c, p := current[u], prior[u]
+		if c.quantity <= 0 || p.quantity <= 0 {
+			mix += c.amount - p.amount
+			continue
+		}
+
+		r0 := p.amount / p.quantity
+		r1 := c.amount / c.quantity
+		volume += (c.quantity - p.quantity) * r0
+		rate += (r1 - r0) * c.quantity
+	}
+
+	return volume, rate, mix
+}
@@ -0,0 +1,133 @@
+package cost
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+func TestDecompose(t *testing.T) {
+	tests := []struct {
+		name                       string
+		current, prior             map[string]usageCost
+		wantVol, wantRate, wantMix float64
+	}{
+		{
+			name:    "pure volume",
+			current: map[string]usageCost{"BoxUsage": {amount: 200, quantity: 20}},
+			prior:   map[string]usageCost{"BoxUsage": {amount: 100, quantity: 10}},
+			wantVol: 100,
+		},
+		{
+			name:     "pure rate",
+			current:  map[string]usageCost{"BoxUsage": {amount: 150, quantity: 10}},
+			prior:    map[string]usageCost{"BoxUsage": {amount: 100, quantity: 10}},
+			wantRate: 50,
+		},
+		{
+			name:     "volume and rate",
+			current:  map[string]usageCost{"BoxUsage": {amount: 300, quantity: 20}},
+			prior:    map[string]usageCost{"BoxUsage": {amount: 100, quantity: 10}},
+			wantVol:  100, // 10 more units at $10
+			wantRate: 100, // $5 more per unit on 20 units
+		},
+		{
+			name: "new usage type and fee are mix",
+			current: map[string]usageCost{
+				"BoxUsage":   {amount: 100, quantity: 10},
+				"NatGateway": {amount: 40, quantity: 4},
+				"SupportFee": {amount: 25},
+			},
+			prior: map[string]usageCost{
+				"BoxUsage":  {amount: 100, quantity: 10},
+				"OldVolume": {amount: 10, quantity: 100},
+			},
+			wantMix: 55, // +40 +25 -10
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vol, rate, mix := decompose(tt.current, tt.prior)
+			if math.Abs(vol-tt.wantVol) > 1e-9 || math.Abs(rate-tt.wantRate) > 1e-9 || math.Abs(mix-tt.wantMix) > 1e-9 {
+				t.Errorf("decompose() = %v, %v, %v; want %v, %v, %v", vol, rate, mix, tt.wantVol, tt.wantRate, tt.wantMix)
+			}
+		})
+	}
+}
+
+func TestUsageTypeGroupDefs(t *testing.T) {
+	service := types.GroupDefinition{Type: types.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")}
+	usage := types.GroupDefinition{Type: types.GroupDefinitionTypeDimension, Key: aws.String("USAGE_TYPE")}
+	tag := types.GroupDefinition{Type: types.GroupDefinitionTypeTag, Key: aws.String("team")}
+
+	defs, idx, err := usageTypeGroupDefs([]types.GroupDefinition{service})
+	if err != nil || len(defs) != 2 || idx != 1 {
+		t.Errorf("service group-by should gain USAGE_TYPE, got %d defs, idx %d, err %v", len(defs), idx, err)
+	}
+
+	defs, idx, err = usageTypeGroupDefs([]types.GroupDefinition{usage, tag})
+	if err != nil || len(defs) != 2 || idx != 0 {
+		t.Errorf("existing USAGE_TYPE should be reused, got %d defs, idx %d, err %v", len(defs), idx, err)
+	}
+
+	if _, _, err := usageTypeGroupDefs([]types.GroupDefinition{service, tag}); err == nil {
+		t.Error("three group-bys should be rejected")
+	}
+}
+
+// usageSource serves totals for plain queries and a per-usage-type
+// breakdown when USAGE_TYPE is added to the group-by
+type usageSource struct {
+	priorStart time.Time
+}
+
+func (s usageSource) GetCosts(ctx context.Context, req CostRequest) ([]TimeBucket, error) {
+	prior := req.Start.Equal(s.priorStart)
+	group := func(amount, quantity float64, keys ...string) GroupCost {
+		return GroupCost{Keys: keys, Metrics: map[string]float64{req.Metrics[0]: amount, "UsageQuantity": quantity}}
+	}
+
+	var groups []GroupCost
+	switch {
+	case len(req.GroupBy) == 1 && prior:
+		groups = []GroupCost{group(100, 0, "AmazonEC2")}
+	case len(req.GroupBy) == 1:
+		groups = []GroupCost{group(330, 0, "AmazonEC2")}
+	case prior:
+		groups = []GroupCost{group(100, 10, "AmazonEC2", "BoxUsage")}
+	default:
+		groups = []GroupCost{group(300, 20, "AmazonEC2", "BoxUsage"), group(30, 0, "AmazonEC2", "Fee")}
+	}
+	return []TimeBucket{{Groups: groups}}, nil
+}
+
+func TestQuery_ExplainRate(t *testing.T) {
+	window, _ := timewin.Parse("7d")
+	src := usageSource{priorStart: window.PriorStart}
+
+	deltas, err := Query(context.Background(), src, QueryParams{Window: window, GroupBy: "service", ExplainRate: true})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(deltas) != 1 {
+		t.Fatalf("Query() returned %d deltas, want 1", len(deltas))
+	}
+
+	d := deltas[0]
+	if !d.Decomposed || d.VolumeEffect != 100 || d.RateEffect != 100 || d.MixEffect != 30 {
+		t.Errorf("unexpected decomposition: %+v", d)
+	}
+	if d.VolumeEffect+d.RateEffect+d.MixEffect != d.AbsoluteDelta {
+		t.Errorf("effects should sum to the delta %v", d.AbsoluteDelta)
+	}
+
+	if _, err := Query(context.Background(), src, QueryParams{Window: window, GroupBy: "service", ExplainRate: true, Metric: "UsageQuantity"}); err == nil {
+		t.Error("Query() should reject rate explanation of UsageQuantity")
+	}
+}
@@ -100,9 +100,9 @@ func TestBuildGroupKeyMultipleDimensions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := buildGroupKey(tt.keys)
+			got := GroupKey(tt.keys)
 			if got != tt.want {
-				t.Errorf("buildGroupKey() = %q, want %q", got, tt.want)
+				t.Errorf("GroupKey() = %q, want %q", got, tt.want)
 			}
 		})
 	}
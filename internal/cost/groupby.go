@@ -0,0 +1,70 @@
+package cost
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// GroupByTag is the group-by name that groups by a tag key alone
+const GroupByTag = "tag"
+
+// groupDimensions maps group-by names to Cost Explorer dimensions
+var groupDimensions = map[string]string{
+	"service":        "SERVICE",
+	"linked_account": "LINKED_ACCOUNT",
+	"region":         "REGION",
+	"usage_type":     "USAGE_TYPE",
+}
+
+// GroupDefinitions builds the Cost Explorer grouping for a group-by
+// expression: one dimension name, two joined with a comma (e.g.
+// "service,linked_account"), or "tag" to group by tagKey alone. A tagKey
+// given with a single dimension adds the tag as a second level. Cost
+// Explorer accepts at most two groupings.
+func GroupDefinitions(groupBy, tagKey string) ([]types.GroupDefinition, error) {
+	var groupDefs []types.GroupDefinition
+	for _, name := range strings.Split(groupBy, ",") {
+		name = strings.TrimSpace(name)
+
+		if name == GroupByTag {
+			if tagKey == "" {
+				return nil, fmt.Errorf("group-by %s requires a tag key", GroupByTag)
+			}
+			continue
+		}
+
+		dim, ok := groupDimensions[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported group-by: %s (expected service, linked_account, region, usage_type or tag)", name)
+		}
+		groupDefs = append(groupDefs, types.GroupDefinition{
+			Type: types.GroupDefinitionType("DIMENSION"),
+			Key:  aws.String(dim),
+		})
+	}
+
+	// Add tag grouping if specified
+	if tagKey != "" {
+		groupDefs = append(groupDefs, types.GroupDefinition{
+			Type: types.GroupDefinitionType("TAG"),
+			Key:  aws.String(tagKey),
+		})
+	}
+
+	if len(groupDefs) > 2 {
+		return nil, fmt.Errorf("group-by %s: Cost Explorer supports at most two groupings including the tag key", groupBy)
+	}
+	return groupDefs, nil
+}
+
+// GroupKey builds the composite key of a cost group, joining multiple
+// dimensions with " | "
+func GroupKey(keys []string) string {
+	if len(keys) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(keys, " | ")
+}
@@ -0,0 +1,43 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestGroupDefinitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy string
+		tagKey  string
+		want    []string // TYPE:KEY per grouping
+		wantErr bool
+	}{
+		{"single dimension", "service", "", []string{"DIMENSION:SERVICE"}, false},
+		{"dimension with tag", "region", "team", []string{"DIMENSION:REGION", "TAG:team"}, false},
+		{"two dimensions", "service,linked_account", "", []string{"DIMENSION:SERVICE", "DIMENSION:LINKED_ACCOUNT"}, false},
+		{"spaces around names", "usage_type, region", "", []string{"DIMENSION:USAGE_TYPE", "DIMENSION:REGION"}, false},
+		{"tag alone", "tag", "team", []string{"TAG:team"}, false},
+		{"tag without key", "tag", "", nil, true},
+		{"too many groupings", "service,linked_account", "team", nil, true},
+		{"unknown dimension", "instance_type", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs, err := GroupDefinitions(tt.groupBy, tt.tagKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GroupDefinitions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(defs) != len(tt.want) {
+				t.Fatalf("GroupDefinitions() returned %d groupings, want %d", len(defs), len(tt.want))
+			}
+			for i, def := range defs {
+				if got := string(def.Type) + ":" + aws.ToString(def.Key); got != tt.want[i] {
+					t.Errorf("grouping %d = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package cost
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMetric is the Cost Explorer metric used when none is requested
+const DefaultMetric = "UnblendedCost"
+
+// Metrics lists the Cost Explorer metrics cost-blame can report on
+var Metrics = []string{
+	"UnblendedCost",
+	"AmortizedCost",
+	"NetAmortizedCost",
+	"NetUnblendedCost",
+	"BlendedCost",
+	"UsageQuantity",
+}
+
+// ParseMetric validates a metric name case-insensitively and returns its
+// canonical Cost Explorer spelling. An empty name yields DefaultMetric.
+func ParseMetric(name string) (string, error) {
+	if name == "" {
+		return DefaultMetric, nil
+	}
+	for _, m := range Metrics {
+		if strings.EqualFold(name, m) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported metric: %s (expected one of %s)", name, strings.Join(Metrics, ", "))
+}
+
+// IsMonetary reports whether a metric is measured in currency rather than usage units
+func IsMonetary(metric string) bool {
+	return metric != "UsageQuantity"
+}
+
+// FormatAmount renders a metric value, with a dollar sign for monetary metrics
+func FormatAmount(metric string, amount float64) string {
+	if IsMonetary(metric) {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// MetricOf returns the metric a set of deltas is measured in
+func MetricOf(deltas []Delta) string {
+	if len(deltas) > 0 && deltas[0].Metric != "" {
+		return deltas[0].Metric
+	}
+	return DefaultMetric
+}
@@ -0,0 +1,91 @@
+package cost
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+func TestParseMetric(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", "UnblendedCost", false},
+		{"AmortizedCost", "AmortizedCost", false},
+		{"netamortizedcost", "NetAmortizedCost", false},
+		{"usagequantity", "UsageQuantity", false},
+		{"TotalCost", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMetric(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMetric(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMetric(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	if got := FormatAmount("AmortizedCost", 12.345); got != "$12.35" {
+		t.Errorf("FormatAmount(AmortizedCost) = %q", got)
+	}
+	if got := FormatAmount("UsageQuantity", 12.345); got != "12.35" {
+		t.Errorf("FormatAmount(UsageQuantity) = %q", got)
+	}
+}
+
+func TestMetricOf(t *testing.T) {
+	if got := MetricOf(nil); got != DefaultMetric {
+		t.Errorf("MetricOf(nil) = %q, want %q", got, DefaultMetric)
+	}
+	if got := MetricOf([]Delta{{Metric: "BlendedCost"}}); got != "BlendedCost" {
+		t.Errorf("MetricOf() = %q, want BlendedCost", got)
+	}
+}
+
+func TestQuery_Metric(t *testing.T) {
+	window, _ := timewin.Parse("7d")
+	src := &fakeSource{
+		current: []TimeBucket{{Groups: []GroupCost{{
+			Keys:    []string{"AmazonEC2"},
+			Metrics: map[string]float64{"UnblendedCost": 5000, "AmortizedCost": 120},
+		}}}},
+		prior: []TimeBucket{{Groups: []GroupCost{{
+			Keys:    []string{"AmazonEC2"},
+			Metrics: map[string]float64{"UnblendedCost": 100, "AmortizedCost": 100},
+		}}}},
+	}
+
+	deltas, err := Query(context.Background(), src, QueryParams{Window: window, GroupBy: "service", Metric: "amortizedcost"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	if got := src.requests[0].Metrics; len(got) != 1 || got[0] != "AmortizedCost" {
+		t.Errorf("requested metrics = %v, want [AmortizedCost]", got)
+	}
+	if len(deltas) != 1 || deltas[0].AbsoluteDelta != 20 || deltas[0].Metric != "AmortizedCost" {
+		t.Errorf("unexpected deltas: %+v", deltas)
+	}
+
+	// Usage quantities carry no currency
+	src.requests = nil
+	deltas, _ = Query(context.Background(), src, QueryParams{Window: window, GroupBy: "service", Metric: "UsageQuantity"})
+	for _, d := range deltas {
+		if d.Currency != "" {
+			t.Errorf("UsageQuantity delta currency = %q, want empty", d.Currency)
+		}
+	}
+
+	if _, err := Query(context.Background(), src, QueryParams{Window: window, GroupBy: "service", Metric: "Bogus"}); err == nil {
+		t.Error("Query() should reject unknown metrics")
+	}
+}
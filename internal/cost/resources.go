@@ -0,0 +1,178 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+// ResourceLookbackDays is how far back Cost Explorer keeps resource-level
+// data
+const ResourceLookbackDays = 14
+
+// ResourceCostAPI is the subset of the Cost Explorer client used by
+// CostExplorerResourceSource
+type ResourceCostAPI interface {
+	GetCostAndUsageWithResources(ctx context.Context, params *costexplorer.GetCostAndUsageWithResourcesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageWithResourcesOutput, error)
+}
+
+// CostExplorerResourceSource reads costs from Cost Explorer's resource-level
+// data, which allows grouping by RESOURCE_ID. Requests must filter on a
+// service and stay within the last 14 days.
+type CostExplorerResourceSource struct {
+	client ResourceCostAPI
+}
+
+// NewCostExplorerResourceSource creates a Source backed by
+// GetCostAndUsageWithResources
+func NewCostExplorerResourceSource(client ResourceCostAPI) *CostExplorerResourceSource {
+	return &CostExplorerResourceSource{client: client}
+}
+
+// GetCosts calls GetCostAndUsageWithResources, following pagination
+func (s *CostExplorerResourceSource) GetCosts(ctx context.Context, req CostRequest) ([]TimeBucket, error) {
+	input := &costexplorer.GetCostAndUsageWithResourcesInput{
+		TimePeriod: &types.DateInterval{
+			Start: aws.String(formatPeriod(req.Start, req.Granularity)),
+			End:   aws.String(formatPeriod(req.End, req.Granularity)),
+		},
+		Granularity: req.Granularity,
+		Metrics:     req.Metrics,
+		GroupBy:     req.GroupBy,
+		Filter:      req.Filter,
+	}
+
+	var buckets []TimeBucket
+	for {
+		output, err := s.client.GetCostAndUsageWithResources(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		buckets = appendResults(buckets, output.ResultsByTime, len(req.GroupBy) > 0)
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return buckets, nil
+}
+
+// ResourceParams describes a per-resource cost comparison
+type ResourceParams struct {
+	Window   *timewin.Window
+	Services []string  // Cost Explorer SERVICE values; at least one is required
+	Regions  []string  // regions searched for resources; empty for every region
+	Accounts []string  // linked accounts searched for resources; empty for every account
+	Metric   string    // Cost Explorer metric (default: UnblendedCost)
+	Today    time.Time // UTC day that resource-level data is counted back from
+}
+
+// QueryResources compares the cost of each resource of the services between
+// the current and prior periods. Delta keys are the resource IDs reported by
+// Cost Explorer: instance and volume IDs, bucket names, or ARNs.
+func QueryResources(ctx context.Context, src Source, params ResourceParams) ([]Delta, error) {
+	if len(params.Services) == 0 {
+		return nil, fmt.Errorf("resource costs need a service")
+	}
+
+	earliest := params.Today.AddDate(0, 0, -ResourceLookbackDays)
+	if params.Window.PriorStart.Before(earliest) {
+		return nil, fmt.Errorf("resource-level costs only cover the last %d days, but the prior period starts %s",
+			ResourceLookbackDays, timewin.FormatCE(params.Window.PriorStart))
+	}
+
+	metric, err := ParseMetric(params.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	groupDefs := []types.GroupDefinition{{
+		Type: types.GroupDefinitionTypeDimension,
+		Key:  aws.String(string(types.DimensionResourceId)),
+	}}
+	filter := resourceFilter(params)
+
+	w := params.Window
+	currentBuckets, err := queryBuckets(ctx, src, w.CurrentStart, w.CurrentEnd,
+		types.GranularityDaily, metric, groupDefs, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query current resource costs: %w", err)
+	}
+
+	priorBuckets, err := queryBuckets(ctx, src, w.PriorStart, w.PriorEnd,
+		types.GranularityDaily, metric, groupDefs, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prior resource costs: %w", err)
+	}
+
+	currentCosts := sumBuckets(currentBuckets, metric)
+	priorCosts := sumBuckets(priorBuckets, metric)
+
+	perDay := w.Uneven()
+	if perDay {
+		scaleCosts(currentCosts, 1/w.CurrentDays())
+		scaleCosts(priorCosts, 1/w.PriorDays())
+	}
+
+	deltas := computeDeltas(currentCosts, priorCosts)
+	for i := range deltas {
+		deltas[i].Metric = metric
+		deltas[i].PerDay = perDay
+		if !IsMonetary(metric) {
+			deltas[i].Currency = ""
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return deltas[i].AbsoluteDelta > deltas[j].AbsoluteDelta
+	})
+
+	return deltas, nil
+}
+
+// globalRegions are the REGION values Cost Explorer reports for global
+// resources, such as CloudFront distributions, which no region search misses
+var globalRegions = []string{"global", "NoRegion"}
+
+// resourceFilter restricts a resource query to the services, and to the
+// regions and accounts searched when they are given
+func resourceFilter(params ResourceParams) *types.Expression {
+	exprs := []types.Expression{{
+		Dimensions: &types.DimensionValues{
+			Key:    types.DimensionService,
+			Values: params.Services,
+		},
+	}}
+
+	if len(params.Regions) > 0 {
+		exprs = append(exprs, types.Expression{
+			Dimensions: &types.DimensionValues{
+				Key:    types.DimensionRegion,
+				Values: append(append([]string(nil), params.Regions...), globalRegions...),
+			},
+		})
+	}
+
+	if len(params.Accounts) > 0 {
+		exprs = append(exprs, types.Expression{
+			Dimensions: &types.DimensionValues{
+				Key:    types.DimensionLinkedAccount,
+				Values: append([]string(nil), params.Accounts...),
+			},
+		})
+	}
+
+	if len(exprs) == 1 {
+		return &exprs[0]
+	}
+	return &types.Expression{And: exprs}
+}
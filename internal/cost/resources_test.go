@@ -0,0 +1,163 @@
+package cost
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+// fakeResourceAPI returns one page per call and records every input
+type fakeResourceAPI struct {
+	pages  []*costexplorer.GetCostAndUsageWithResourcesOutput
+	inputs []costexplorer.GetCostAndUsageWithResourcesInput
+}
+
+func (f *fakeResourceAPI) GetCostAndUsageWithResources(ctx context.Context, params *costexplorer.GetCostAndUsageWithResourcesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageWithResourcesOutput, error) {
+	f.inputs = append(f.inputs, *params)
+	return f.pages[len(f.inputs)-1], nil
+}
+
+func TestCostExplorerResourceSource_Pagination(t *testing.T) {
+	result := func(id, amount string) types.ResultByTime {
+		return types.ResultByTime{
+			TimePeriod: &types.DateInterval{Start: aws.String("2024-09-01"), End: aws.String("2024-09-02")},
+			Groups: []types.Group{{
+				Keys:    []string{id},
+				Metrics: map[string]types.MetricValue{"UnblendedCost": {Amount: aws.String(amount)}},
+			}},
+		}
+	}
+	client := &fakeResourceAPI{pages: []*costexplorer.GetCostAndUsageWithResourcesOutput{
+		{ResultsByTime: []types.ResultByTime{result("i-111", "5")}, NextPageToken: aws.String("page2")},
+		{ResultsByTime: []types.ResultByTime{result("i-222", "7")}},
+	}}
+
+	buckets, err := NewCostExplorerResourceSource(client).GetCosts(context.Background(), CostRequest{
+		Start:       time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{"UnblendedCost"},
+		GroupBy:     []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: aws.String("RESOURCE_ID")}},
+	})
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+
+	if len(client.inputs) != 2 || aws.ToString(client.inputs[1].NextPageToken) != "page2" {
+		t.Errorf("expected a second request for page2, got %d requests", len(client.inputs))
+	}
+	if len(buckets) != 2 || buckets[1].Groups[0].Keys[0] != "i-222" || buckets[1].Groups[0].Metrics["UnblendedCost"] != 7 {
+		t.Errorf("unexpected buckets %+v", buckets)
+	}
+}
+
+func TestQueryResources(t *testing.T) {
+	today := time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC)
+	window := &timewin.Window{
+		CurrentStart: today.AddDate(0, 0, -7),
+		CurrentEnd:   today,
+		PriorStart:   today.AddDate(0, 0, -14),
+		PriorEnd:     today.AddDate(0, 0, -7),
+	}
+	src := &fakeSource{
+		current: []TimeBucket{{Groups: []GroupCost{
+			{Keys: []string{"i-111"}, Metrics: map[string]float64{"UnblendedCost": 70}},
+			{Keys: []string{"vol-222"}, Metrics: map[string]float64{"UnblendedCost": 10}},
+		}}},
+		prior: []TimeBucket{{Groups: []GroupCost{
+			{Keys: []string{"i-111"}, Metrics: map[string]float64{"UnblendedCost": 20}},
+			{Keys: []string{"i-deleted"}, Metrics: map[string]float64{"UnblendedCost": 30}},
+		}}},
+	}
+
+	deltas, err := QueryResources(context.Background(), src, ResourceParams{
+		Window:   window,
+		Services: []string{"Amazon Elastic Compute Cloud - Compute", "EC2 - Other"},
+		Today:    today,
+	})
+	if err != nil {
+		t.Fatalf("QueryResources() error = %v", err)
+	}
+
+	req := src.requests[0]
+	if req.Granularity != types.GranularityDaily || aws.ToString(req.GroupBy[0].Key) != "RESOURCE_ID" {
+		t.Errorf("expected daily RESOURCE_ID grouping, got %+v", req)
+	}
+	if req.Filter == nil || req.Filter.Dimensions.Key != types.DimensionService || len(req.Filter.Dimensions.Values) != 2 {
+		t.Errorf("expected a filter on both services, got %+v", req.Filter)
+	}
+
+	want := []struct {
+		key   string
+		delta float64
+	}{{"i-111", 50}, {"vol-222", 10}, {"i-deleted", -30}}
+	if len(deltas) != len(want) {
+		t.Fatalf("QueryResources() returned %d deltas, want %d", len(deltas), len(want))
+	}
+	for i, w := range want {
+		if deltas[i].Key != w.key || deltas[i].AbsoluteDelta != w.delta || deltas[i].Metric != "UnblendedCost" {
+			t.Errorf("delta %d = %s %.0f, want %s %.0f", i, deltas[i].Key, deltas[i].AbsoluteDelta, w.key, w.delta)
+		}
+	}
+}
+
+func TestResourceFilter(t *testing.T) {
+	services := []string{"AWS Lambda"}
+
+	if f := resourceFilter(ResourceParams{Services: services}); f.Dimensions == nil || f.Dimensions.Key != types.DimensionService {
+		t.Errorf("resourceFilter() without scope = %+v, want a service filter", f)
+	}
+
+	f := resourceFilter(ResourceParams{
+		Services: services,
+		Regions:  []string{"us-west-2"},
+		Accounts: []string{"111111111111"},
+	})
+	if len(f.And) != 3 {
+		t.Fatalf("resourceFilter() with scope = %+v, want service, region and account filters", f)
+	}
+	if region := f.And[1].Dimensions; region.Key != types.DimensionRegion || strings.Join(region.Values, ",") != "us-west-2,global,NoRegion" {
+		t.Errorf("region filter = %+v, want the searched region and global", region)
+	}
+	if account := f.And[2].Dimensions; account.Key != types.DimensionLinkedAccount || strings.Join(account.Values, ",") != "111111111111" {
+		t.Errorf("account filter = %+v, want the searched account", account)
+	}
+}
+
+func TestQueryResources_Errors(t *testing.T) {
+	today := time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC)
+	window := &timewin.Window{
+		CurrentStart: today.AddDate(0, 0, -10),
+		CurrentEnd:   today,
+		PriorStart:   today.AddDate(0, 0, -20),
+		PriorEnd:     today.AddDate(0, 0, -10),
+	}
+
+	tests := []struct {
+		name     string
+		services []string
+		want     string
+	}{
+		{"no service", nil, "need a service"},
+		{"older than 14 days", []string{"AWS Lambda"}, "last 14 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &fakeSource{}
+			_, err := QueryResources(context.Background(), src, ResourceParams{Window: window, Services: tt.services, Today: today})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("QueryResources() error = %v, want %q", err, tt.want)
+			}
+			if len(src.requests) != 0 {
+				t.Errorf("expected no requests, got %d", len(src.requests))
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cost
+
+import (
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// SeriesPoint is a group's cost in one day or hour of a period
+type SeriesPoint struct {
+	Date  time.Time
+	Value float64
+}
+
+// seriesStep returns the bucket length of a granularity
+func seriesStep(gran types.Granularity) time.Duration {
+	if gran == types.GranularityHourly {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// bucketSeries spreads each group's cost over one slot per step in
+// [start, end), oldest first. Slots without cost are zero.
+func bucketSeries(buckets []TimeBucket, metric string, start, end time.Time, step time.Duration) map[string][]float64 {
+	n := int(math.Round(float64(end.Sub(start)) / float64(step)))
+
+	series := make(map[string][]float64)
+	for _, bucket := range buckets {
+		i := int(math.Round(float64(bucket.Start.Sub(start)) / float64(step)))
+		if i < 0 || i >= n {
+			continue
+		}
+		for _, group := range bucket.Groups {
+			amount, ok := group.Metrics[metric]
+			if !ok {
+				continue
+			}
+			key := GroupKey(group.Keys)
+			if series[key] == nil {
+				series[key] = make([]float64, n)
+			}
+			series[key][i] += amount
+		}
+	}
+	return series
+}
+
+// seriesPoints dates the slots of a series; a nil series is all zeros
+func seriesPoints(values []float64, start, end time.Time, step time.Duration) []SeriesPoint {
+	n := int(math.Round(float64(end.Sub(start)) / float64(step)))
+	points := make([]SeriesPoint, n)
+	for i := range points {
+		points[i].Date = start.Add(time.Duration(i) * step)
+		if i < len(values) {
+			points[i].Value = values[i]
+		}
+	}
+	return points
+}
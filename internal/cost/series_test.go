@@ -0,0 +1,65 @@
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+func TestBucketSeries(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := func(i int, amount float64, key string) TimeBucket {
+		return TimeBucket{
+			Start:  start.AddDate(0, 0, i),
+			Groups: []GroupCost{{Keys: []string{key}, Metrics: map[string]float64{"UnblendedCost": amount}}},
+		}
+	}
+	buckets := []TimeBucket{day(0, 10, "AmazonEC2"), day(2, 30, "AmazonEC2"), day(1, 5, "AmazonS3"), day(5, 99, "AmazonEC2")}
+
+	series := bucketSeries(buckets, "UnblendedCost", start, start.AddDate(0, 0, 3), 24*time.Hour)
+	if got := series["AmazonEC2"]; len(got) != 3 || got[0] != 10 || got[1] != 0 || got[2] != 30 {
+		t.Errorf("EC2 series = %v, want [10 0 30] (out-of-range day dropped)", got)
+	}
+	if got := series["AmazonS3"]; len(got) != 3 || got[1] != 5 {
+		t.Errorf("S3 series = %v, want [0 5 0]", got)
+	}
+
+	points := seriesPoints(nil, start, start.Add(3*time.Hour), time.Hour)
+	if len(points) != 3 || !points[2].Date.Equal(start.Add(2*time.Hour)) || points[2].Value != 0 {
+		t.Errorf("seriesPoints() = %+v, want three zero hours", points)
+	}
+}
+
+func TestQuery_Series(t *testing.T) {
+	w := &timewin.Window{
+		CurrentStart: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC),
+		CurrentEnd:   time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		PriorStart:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PriorEnd:     time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC),
+	}
+	bucket := func(start time.Time, amount float64) TimeBucket {
+		return TimeBucket{Start: start, Groups: []GroupCost{{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"UnblendedCost": amount}}}}
+	}
+	src := &fakeSource{
+		current: []TimeBucket{bucket(w.CurrentStart, 10), bucket(w.CurrentStart.AddDate(0, 0, 1), 90)},
+		prior:   []TimeBucket{bucket(w.PriorStart, 10)},
+	}
+
+	deltas, err := Query(context.Background(), src, QueryParams{Window: w, GroupBy: "service", Series: true})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	d := deltas[0]
+	if len(d.CurrentSeries) != 2 || d.CurrentSeries[1].Value != 90 || !d.CurrentSeries[1].Date.Equal(w.CurrentStart.AddDate(0, 0, 1)) {
+		t.Errorf("CurrentSeries = %+v", d.CurrentSeries)
+	}
+	if len(d.PriorSeries) != 2 || d.PriorSeries[0].Value != 10 || d.PriorSeries[1].Value != 0 {
+		t.Errorf("PriorSeries = %+v, want [10 0]", d.PriorSeries)
+	}
+	if d.CurrentCost != 100 {
+		t.Errorf("CurrentCost = %v, want the series total 100", d.CurrentCost)
+	}
+}
@@ -0,0 +1,152 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+// Source fetches grouped cost data for a time range. Cost Explorer is the
+// default implementation; CUR files, caches and test fixtures plug in here.
+type Source interface {
+	GetCosts(ctx context.Context, req CostRequest) ([]TimeBucket, error)
+}
+
+// CostRequest describes a grouped cost query using Cost Explorer's vocabulary
+type CostRequest struct {
+	Start       time.Time // inclusive
+	End         time.Time // exclusive
+	Granularity types.Granularity
+	Metrics     []string
+	GroupBy     []types.GroupDefinition
+	Filter      *types.Expression // optional
+}
+
+// TimeBucket holds grouped costs for one granularity period
+type TimeBucket struct {
+	Start  time.Time
+	End    time.Time
+	Groups []GroupCost
+}
+
+// GroupCost is the value of each requested metric for one group in a bucket
+type GroupCost struct {
+	Keys    []string
+	Metrics map[string]float64
+}
+
+// CostExplorerAPI is the subset of the Cost Explorer client used by CostExplorerSource
+type CostExplorerAPI interface {
+	GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error)
+}
+
+// CostExplorerSource reads costs from the AWS Cost Explorer API
+type CostExplorerSource struct {
+	client CostExplorerAPI
+}
+
+// NewCostExplorerSource creates a Source backed by Cost Explorer
+func NewCostExplorerSource(client CostExplorerAPI) *CostExplorerSource {
+	return &CostExplorerSource{client: client}
+}
+
+// GetCosts calls GetCostAndUsage, following pagination
+func (s *CostExplorerSource) GetCosts(ctx context.Context, req CostRequest) ([]TimeBucket, error) {
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &types.DateInterval{
+			Start: aws.String(formatPeriod(req.Start, req.Granularity)),
+			End:   aws.String(formatPeriod(req.End, req.Granularity)),
+		},
+		Granularity: req.Granularity,
+		Metrics:     req.Metrics,
+		GroupBy:     req.GroupBy,
+		Filter:      req.Filter,
+	}
+
+	var buckets []TimeBucket
+
+	// Handle pagination manually
+	var nextToken *string
+	for {
+		if nextToken != nil {
+			input.NextPageToken = nextToken
+		}
+
+		output, err := s.client.GetCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		buckets = appendResults(buckets, output.ResultsByTime, len(req.GroupBy) > 0)
+
+		nextToken = output.NextPageToken
+		if nextToken == nil {
+			break
+		}
+	}
+
+	return buckets, nil
+}
+
+// appendResults converts Cost Explorer results to buckets. Ungrouped
+// requests only carry a total, which is reported as one keyless group.
+func appendResults(buckets []TimeBucket, results []types.ResultByTime, grouped bool) []TimeBucket {
+	for _, result := range results {
+		bucket := TimeBucket{}
+		if result.TimePeriod != nil {
+			bucket.Start, _ = parsePeriod(aws.ToString(result.TimePeriod.Start))
+			bucket.End, _ = parsePeriod(aws.ToString(result.TimePeriod.End))
+		}
+
+		for _, group := range result.Groups {
+			bucket.Groups = append(bucket.Groups, GroupCost{
+				Keys:    group.Keys,
+				Metrics: parseMetrics(group.Metrics),
+			})
+		}
+
+		if !grouped && len(result.Total) > 0 {
+			bucket.Groups = append(bucket.Groups, GroupCost{Metrics: parseMetrics(result.Total)})
+		}
+
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+func parseMetrics(values map[string]types.MetricValue) map[string]float64 {
+	metrics := make(map[string]float64, len(values))
+	for name, value := range values {
+		if value.Amount != nil {
+			amount, _ := strconv.ParseFloat(*value.Amount, 64)
+			metrics[name] = amount
+		}
+	}
+	return metrics
+}
+
+// formatPeriod formats a boundary the way GetCostAndUsage expects:
+// dates for DAILY/MONTHLY, full timestamps for HOURLY
+func formatPeriod(t time.Time, gran types.Granularity) string {
+	if gran == types.GranularityHourly {
+		return t.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return timewin.FormatCE(t)
+}
+
+func parsePeriod(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time period %q: %w", s, err)
+	}
+	return t.UTC(), nil
+}
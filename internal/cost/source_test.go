@@ -0,0 +1,219 @@
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+// fakeCostExplorer serves pre-built pages and records every input
+type fakeCostExplorer struct {
+	pages  []*costexplorer.GetCostAndUsageOutput
+	inputs []costexplorer.GetCostAndUsageInput
+}
+
+func (f *fakeCostExplorer) GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error) {
+	f.inputs = append(f.inputs, *params)
+	page := f.pages[len(f.inputs)-1]
+	return page, nil
+}
+
+func ceResult(start, end string, groups ...types.Group) types.ResultByTime {
+	return types.ResultByTime{
+		TimePeriod: &types.DateInterval{Start: aws.String(start), End: aws.String(end)},
+		Groups:     groups,
+	}
+}
+
+func ceGroup(amount string, keys ...string) types.Group {
+	return types.Group{
+		Keys:    keys,
+		Metrics: map[string]types.MetricValue{"UnblendedCost": {Amount: aws.String(amount), Unit: aws.String("USD")}},
+	}
+}
+
+func TestCostExplorerSource_Pagination(t *testing.T) {
+	client := &fakeCostExplorer{pages: []*costexplorer.GetCostAndUsageOutput{
+		{
+			ResultsByTime: []types.ResultByTime{ceResult("2024-09-01", "2024-09-02", ceGroup("10.5", "AmazonEC2"))},
+			NextPageToken: aws.String("page-2"),
+		},
+		{
+			ResultsByTime: []types.ResultByTime{ceResult("2024-09-02", "2024-09-03", ceGroup("4", "AmazonS3"))},
+		},
+	}}
+
+	src := NewCostExplorerSource(client)
+	buckets, err := src.GetCosts(context.Background(), CostRequest{
+		Start:       time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{"UnblendedCost"},
+	})
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+
+	if len(client.inputs) != 2 {
+		t.Fatalf("expected 2 API calls, got %d", len(client.inputs))
+	}
+	if aws.ToString(client.inputs[1].NextPageToken) != "page-2" {
+		t.Error("second call should pass the next page token")
+	}
+	if aws.ToString(client.inputs[0].TimePeriod.Start) != "2024-09-01" {
+		t.Errorf("daily start = %q, want date only", aws.ToString(client.inputs[0].TimePeriod.Start))
+	}
+
+	if len(buckets) != 2 {
+		t.Fatalf("GetCosts() returned %d buckets, want 2", len(buckets))
+	}
+	if !buckets[1].Start.Equal(time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("bucket start = %v", buckets[1].Start)
+	}
+	if got := buckets[0].Groups[0].Metrics["UnblendedCost"]; got != 10.5 {
+		t.Errorf("UnblendedCost = %v, want 10.5", got)
+	}
+}
+
+func TestCostExplorerSource_Ungrouped(t *testing.T) {
+	result := ceResult("2024-09-01", "2024-09-02")
+	result.Total = map[string]types.MetricValue{"UnblendedCost": {Amount: aws.String("42")}}
+	client := &fakeCostExplorer{pages: []*costexplorer.GetCostAndUsageOutput{
+		{ResultsByTime: []types.ResultByTime{result}},
+	}}
+
+	buckets, err := NewCostExplorerSource(client).GetCosts(context.Background(), CostRequest{
+		Start:       time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{"UnblendedCost"},
+	})
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+	if len(buckets) != 1 || len(buckets[0].Groups) != 1 || buckets[0].Groups[0].Metrics["UnblendedCost"] != 42 {
+		t.Errorf("expected the total as one keyless group, got %+v", buckets)
+	}
+}
+
+func TestFormatPeriod(t *testing.T) {
+	ts := time.Date(2024, 9, 1, 13, 0, 0, 0, time.UTC)
+	if got := formatPeriod(ts, types.GranularityDaily); got != "2024-09-01" {
+		t.Errorf("formatPeriod(DAILY) = %q", got)
+	}
+	if got := formatPeriod(ts, types.GranularityHourly); got != "2024-09-01T13:00:00Z" {
+		t.Errorf("formatPeriod(HOURLY) = %q", got)
+	}
+}
+
+// fakeSource answers every request with the same buckets
+type fakeSource struct {
+	current  []TimeBucket
+	prior    []TimeBucket
+	requests []CostRequest
+}
+
+func (f *fakeSource) GetCosts(ctx context.Context, req CostRequest) ([]TimeBucket, error) {
+	f.requests = append(f.requests, req)
+	if len(f.requests) == 1 {
+		return f.current, nil
+	}
+	return f.prior, nil
+}
+
+func TestQuery_WithSource(t *testing.T) {
+	window, err := timewin.Parse("7d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &fakeSource{
+		current: []TimeBucket{
+			{Groups: []GroupCost{{Keys: []string{"AmazonEC2", "team$web"}, Metrics: map[string]float64{"UnblendedCost": 80}}}},
+			{Groups: []GroupCost{{Keys: []string{"AmazonEC2", "team$web"}, Metrics: map[string]float64{"UnblendedCost": 70}}}},
+		},
+		prior: []TimeBucket{
+			{Groups: []GroupCost{{Keys: []string{"AmazonEC2", "team$web"}, Metrics: map[string]float64{"UnblendedCost": 100}}}},
+		},
+	}
+
+	deltas, err := Query(context.Background(), src, QueryParams{
+		Window:     window,
+		GroupBy:    "service",
+		TagKey:     "team",
+		TagValues:  []string{"web"},
+		AccountIDs: []string{"123456789012"},
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	if len(deltas) != 1 || deltas[0].Key != "AmazonEC2 | team$web" || deltas[0].AbsoluteDelta != 50 {
+		t.Fatalf("unexpected deltas: %+v", deltas)
+	}
+
+	if len(src.requests) != 2 {
+		t.Fatalf("expected current and prior requests, got %d", len(src.requests))
+	}
+	req := src.requests[0]
+	if !req.Start.Equal(window.CurrentStart) || !src.requests[1].Start.Equal(window.PriorStart) {
+		t.Error("requests should cover current then prior period")
+	}
+	if len(req.GroupBy) != 2 || aws.ToString(req.GroupBy[1].Key) != "team" {
+		t.Errorf("unexpected group definitions: %+v", req.GroupBy)
+	}
+	if req.Filter == nil || len(req.Filter.And) != 2 {
+		t.Fatalf("expected account AND tag filter, got %+v", req.Filter)
+	}
+	if req.Filter.And[1].Tags == nil || req.Filter.And[1].Tags.Values[0] != "web" {
+		t.Errorf("tag filter missing: %+v", req.Filter.And[1])
+	}
+}
+
+func TestQuery_PerDay(t *testing.T) {
+	// 30 days of September against 31 days of August
+	window := &timewin.Window{
+		CurrentStart: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		CurrentEnd:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		PriorStart:   time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		PriorEnd:     time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+	src := &fakeSource{
+		current: []TimeBucket{{Groups: []GroupCost{{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"UnblendedCost": 300}}}}},
+		prior:   []TimeBucket{{Groups: []GroupCost{{Keys: []string{"AmazonEC2"}, Metrics: map[string]float64{"UnblendedCost": 310}}}}},
+	}
+
+	deltas, err := Query(context.Background(), src, QueryParams{Window: window, GroupBy: "service"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(deltas) != 1 {
+		t.Fatalf("Query() returned %d deltas, want 1", len(deltas))
+	}
+
+	d := deltas[0]
+	if !d.PerDay || d.CurrentCost != 10 || d.PriorCost != 10 || d.AbsoluteDelta != 0 {
+		t.Errorf("expected equal per-day costs of 10, got %+v", d)
+	}
+}
+
+func TestBuildFilter(t *testing.T) {
+	if f := buildFilter(QueryParams{}); f != nil {
+		t.Errorf("buildFilter() with no filters = %+v, want nil", f)
+	}
+
+	f := buildFilter(QueryParams{AccountIDs: []string{"111"}})
+	if f == nil || f.Dimensions == nil || f.Dimensions.Key != types.DimensionLinkedAccount {
+		t.Errorf("expected single account filter, got %+v", f)
+	}
+
+	// Tag values without a tag key are ignored
+	if f := buildFilter(QueryParams{TagValues: []string{"web"}}); f != nil {
+		t.Errorf("buildFilter() without tag key = %+v, want nil", f)
+	}
+}
@@ -0,0 +1,105 @@
+package cur
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func readCSVFile(path string) ([]LineItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return readCSV(r)
+}
+
+func readCSV(r io.Reader) ([]LineItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	// Legacy CUR spreads tags over "resourceTags/user:<key>" columns,
+	// CUR 2.0 stores them as a JSON map in a single "resource_tags" column
+	columns := make(map[string]int)
+	tagColumns := make(map[int]string)
+	for i, name := range header {
+		if key, ok := strings.CutPrefix(name, "resourceTags/"); ok {
+			tagColumns[i] = normalizeTagKey(key)
+			continue
+		}
+		columns[normalizeColumn(name)] = i
+	}
+
+	if _, ok := columns[colUsageStart]; !ok {
+		return nil, fmt.Errorf("missing %s column; is this a CUR file?", colUsageStart)
+	}
+
+	var items []LineItem
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		tags := make(map[string]string)
+		for i, key := range tagColumns {
+			if i < len(record) && record[i] != "" {
+				tags[key] = record[i]
+			}
+		}
+		for key, value := range parseJSONMap(get(colResourceTags)) {
+			tags[normalizeTagKey(key)] = value
+		}
+
+		item, err := row{get: get, tags: tags, product: parseJSONMap(get(colProduct))}.lineItem()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func parseJSONMap(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,318 @@
+package cur
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LineItem is a single Cost and Usage Report line item, reduced to the
+// columns cost-blame groups and sums on
+type LineItem struct {
+	UsageStart    time.Time
+	Service       string
+	Account       string
+	Region        string
+	UsageType     string
+	ResourceID    string
+	Tags          map[string]string
+	UnblendedCost float64
+	UsageAmount   float64
+
+	BlendedCost      float64
+	NetUnblendedCost float64
+	AmortizedCost    float64
+	NetAmortizedCost float64
+}
+
+// Dataset holds line items loaded from one or more CUR files
+type Dataset struct {
+	Items []LineItem
+	Files []string
+}
+
+// Load reads a CUR file, or every CUR file below a directory.
+// Supported formats are CSV, gzipped CSV and Parquet (CUR 2.0 and legacy CUR).
+func Load(path string) (*Dataset, error) {
+	files, err := findFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no CUR files (.csv, .csv.gz, .parquet) found in %s", path)
+	}
+
+	ds := &Dataset{Files: files}
+	for _, file := range files {
+		items, err := loadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		ds.Items = append(ds.Items, items...)
+	}
+
+	// Keep line items in time order so daily series come out sorted
+	sort.SliceStable(ds.Items, func(i, j int) bool {
+		return ds.Items[i].UsageStart.Before(ds.Items[j].UsageStart)
+	})
+
+	return ds, nil
+}
+
+func findFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CUR path: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isCURFile(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan CUR directory: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func isCURFile(path string) bool {
+	name := strings.ToLower(path)
+	return strings.HasSuffix(name, ".csv") ||
+		strings.HasSuffix(name, ".csv.gz") ||
+		strings.HasSuffix(name, ".parquet")
+}
+
+func loadFile(path string) ([]LineItem, error) {
+	if strings.HasSuffix(strings.ToLower(path), ".parquet") {
+		return readParquetFile(path)
+	}
+	return readCSVFile(path)
+}
+
+// Dimension returns the line item's value for a cost-blame group-by dimension
+// (service, linked_account, region, usage_type)
+func (li LineItem) Dimension(name string) (string, error) {
+	switch name {
+	case "service":
+		return li.Service, nil
+	case "linked_account":
+		return li.Account, nil
+	case "region":
+		return li.Region, nil
+	case "usage_type":
+		return li.UsageType, nil
+	default:
+		return "", fmt.Errorf("unsupported group-by: %s", name)
+	}
+}
+
+// Tag returns the value of a user tag. CUR prefixes user-defined tags with
+// "user:" (legacy) or "user_" (CUR 2.0); both are stripped at load time.
+func (li LineItem) Tag(key string) string {
+	return li.Tags[key]
+}
+
+// column names used to build a line item, in normalized snake_case form
+const (
+	colUsageStart    = "line_item_usage_start_date"
+	colProductCode   = "line_item_product_code"
+	colProductName   = "product_product_name"
+	colAccount       = "line_item_usage_account_id"
+	colRegionCode    = "product_region_code"
+	colRegion        = "product_region"
+	colUsageType     = "line_item_usage_type"
+	colResourceID    = "line_item_resource_id"
+	colUnblendedCost = "line_item_unblended_cost"
+	colUsageAmount   = "line_item_usage_amount"
+	colResourceTags  = "resource_tags"
+	colProduct       = "product"
+
+	colLineItemType         = "line_item_line_item_type"
+	colBlendedCost          = "line_item_blended_cost"
+	colNetUnblendedCost     = "line_item_net_unblended_cost"
+	colSPEffectiveCost      = "savings_plan_savings_plan_effective_cost"
+	colSPNetEffectiveCost   = "savings_plan_net_savings_plan_effective_cost"
+	colSPTotalCommitment    = "savings_plan_total_commitment_to_date"
+	colSPUsedCommitment     = "savings_plan_used_commitment"
+	colRIEffectiveCost      = "reservation_effective_cost"
+	colRINetEffectiveCost   = "reservation_net_effective_cost"
+	colRIUnusedUpfront      = "reservation_unused_amortized_upfront_fee_for_billing_period"
+	colRIUnusedRecurring    = "reservation_unused_recurring_fee"
+	colRINetUnusedUpfront   = "reservation_net_unused_amortized_upfront_fee_for_billing_period"
+	colRINetUnusedRecurring = "reservation_net_unused_recurring_fee"
+	colReservationARN       = "reservation_reservation_a_r_n" // CUR 2.0 spelling
+	colReservationARNLegacy = "reservation_reservation_arn"
+)
+
+// row is a format-independent view of one CUR record
+type row struct {
+	get     func(column string) string
+	tags    map[string]string
+	product map[string]string
+}
+
+func (r row) lineItem() (LineItem, error) {
+	start, err := parseTime(r.get(colUsageStart))
+	if err != nil {
+		return LineItem{}, fmt.Errorf("invalid %s: %w", colUsageStart, err)
+	}
+
+	service := r.get(colProductName)
+	if service == "" {
+		service = r.product["product_name"]
+	}
+	if service == "" {
+		service = r.get(colProductCode)
+	}
+
+	region := r.get(colRegionCode)
+	if region == "" {
+		region = r.get(colRegion)
+	}
+	if region == "" {
+		region = r.product["region"]
+	}
+	if region == "" {
+		region = "NoRegion"
+	}
+
+	unblended := parseAmount(r.get(colUnblendedCost))
+	netUnblended := unblended
+	if v := r.get(colNetUnblendedCost); v != "" {
+		netUnblended = parseAmount(v)
+	}
+
+	blended := unblended
+	if v := r.get(colBlendedCost); v != "" {
+		blended = parseAmount(v)
+	}
+
+	return LineItem{
+		UsageStart:    start,
+		Service:       service,
+		Account:       r.get(colAccount),
+		Region:        region,
+		UsageType:     r.get(colUsageType),
+		ResourceID:    r.get(colResourceID),
+		Tags:          r.tags,
+		UnblendedCost: unblended,
+		UsageAmount:   parseAmount(r.get(colUsageAmount)),
+
+		BlendedCost:      blended,
+		NetUnblendedCost: netUnblended,
+		AmortizedCost:    r.amortized(unblended, false),
+		NetAmortizedCost: r.amortized(netUnblended, true),
+	}, nil
+}
+
+// amortized spreads Savings Plan and Reserved Instance commitments over the
+// usage they cover, the way Cost Explorer's AmortizedCost does: covered usage
+// carries its effective cost, upfront fees and negations drop to zero, and
+// recurring fees keep only the unused part of the commitment
+func (r row) amortized(cost float64, net bool) float64 {
+	pick := func(gross, netCol string) float64 {
+		if net && r.get(netCol) != "" {
+			return parseAmount(r.get(netCol))
+		}
+		return parseAmount(r.get(gross))
+	}
+
+	switch r.get(colLineItemType) {
+	case "SavingsPlanCoveredUsage":
+		return pick(colSPEffectiveCost, colSPNetEffectiveCost)
+	case "SavingsPlanNegation", "SavingsPlanUpfrontFee":
+		return 0
+	case "SavingsPlanRecurringFee":
+		return parseAmount(r.get(colSPTotalCommitment)) - parseAmount(r.get(colSPUsedCommitment))
+	case "DiscountedUsage":
+		return pick(colRIEffectiveCost, colRINetEffectiveCost)
+	case "RIFee":
+		return pick(colRIUnusedUpfront, colRINetUnusedUpfront) + pick(colRIUnusedRecurring, colRINetUnusedRecurring)
+	case "Fee":
+		// The one-off upfront payment for a reservation is amortized via RIFee lines
+		if r.get(colReservationARN) != "" || r.get(colReservationARNLegacy) != "" {
+			return 0
+		}
+	}
+	return cost
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.000Z",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04Z",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
+
+func parseAmount(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f
+}
+
+// normalizeColumn maps legacy CUR headers ("lineItem/UsageStartDate") and
+// CUR 2.0 headers ("line_item_usage_start_date") to the same snake_case name
+func normalizeColumn(name string) string {
+	parts := strings.Split(strings.TrimSpace(name), "/")
+	for i, part := range parts {
+		parts[i] = toSnake(part)
+	}
+	return strings.Join(parts, "_")
+}
+
+func toSnake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// normalizeTagKey strips the "user:" / "user_" prefix CUR adds to
+// user-defined cost allocation tags so keys match Cost Explorer's TAG keys
+func normalizeTagKey(key string) string {
+	for _, prefix := range []string{"user:", "user_"} {
+		if strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
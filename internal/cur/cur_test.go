@@ -0,0 +1,286 @@
+package cur
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestNormalizeColumn(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"lineItem/UsageStartDate", "line_item_usage_start_date"},
+		{"lineItem/UnblendedCost", "line_item_unblended_cost"},
+		{"lineItem/UsageAccountId", "line_item_usage_account_id"},
+		{"product/ProductName", "product_product_name"},
+		{"product/region", "product_region"},
+		{"line_item_usage_start_date", "line_item_usage_start_date"},
+		{"product_region_code", "product_region_code"},
+		{"reservation/ReservationARN", "reservation_reservation_arn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := normalizeColumn(tt.input); got != tt.want {
+				t.Errorf("normalizeColumn(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTagKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"user:team", "team"},
+		{"user_team", "team"},
+		{"aws:createdBy", "aws:createdBy"},
+		{"team", "team"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeTagKey(tt.input); got != tt.want {
+			t.Errorf("normalizeTagKey(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestReadCSV_Legacy(t *testing.T) {
+	data := `lineItem/UsageStartDate,lineItem/UsageAccountId,lineItem/ProductCode,product/ProductName,product/region,lineItem/UsageType,lineItem/UnblendedCost,lineItem/UsageAmount,resourceTags/user:team
+2024-09-01T00:00:00Z,111111111111,AmazonEC2,Amazon Elastic Compute Cloud,us-east-1,BoxUsage:m5.large,1.25,10,platform
+2024-09-02T00:00:00Z,222222222222,AmazonS3,Amazon Simple Storage Service,,TimedStorage-ByteHrs,0.50,100,
+`
+	items, err := readCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("readCSV() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("readCSV() returned %d items, want 2", len(items))
+	}
+
+	ec2 := items[0]
+	if ec2.Service != "Amazon Elastic Compute Cloud" {
+		t.Errorf("Service = %q", ec2.Service)
+	}
+	if ec2.Account != "111111111111" || ec2.Region != "us-east-1" || ec2.UsageType != "BoxUsage:m5.large" {
+		t.Errorf("unexpected dimensions: %+v", ec2)
+	}
+	if ec2.UnblendedCost != 1.25 || ec2.UsageAmount != 10 {
+		t.Errorf("unexpected amounts: cost=%v usage=%v", ec2.UnblendedCost, ec2.UsageAmount)
+	}
+	if ec2.Tag("team") != "platform" {
+		t.Errorf("Tag(team) = %q, want platform", ec2.Tag("team"))
+	}
+	if !ec2.UsageStart.Equal(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("UsageStart = %v", ec2.UsageStart)
+	}
+
+	s3 := items[1]
+	if s3.Region != "NoRegion" {
+		t.Errorf("empty region should map to NoRegion, got %q", s3.Region)
+	}
+	if _, ok := s3.Tags["team"]; ok {
+		t.Error("empty tag values should not be recorded")
+	}
+}
+
+func TestReadCSV_CUR2(t *testing.T) {
+	data := `line_item_usage_start_date,line_item_usage_account_id,line_item_product_code,product_region_code,line_item_usage_type,line_item_unblended_cost,resource_tags,product
+2024-09-01 00:00:00.000,111111111111,AmazonEC2,eu-west-1,BoxUsage,2.5,"{""user_team"":""data""}","{""product_name"":""Amazon Elastic Compute Cloud""}"
+`
+	items, err := readCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("readCSV() error = %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("readCSV() returned %d items, want 1", len(items))
+	}
+	if items[0].Service != "Amazon Elastic Compute Cloud" {
+		t.Errorf("Service = %q, want product_name from product map", items[0].Service)
+	}
+	if items[0].Region != "eu-west-1" {
+		t.Errorf("Region = %q", items[0].Region)
+	}
+	if items[0].Tag("team") != "data" {
+		t.Errorf("Tag(team) = %q, want data", items[0].Tag("team"))
+	}
+}
+
+func TestReadCSV_AmortizedCost(t *testing.T) {
+	data := `line_item_usage_start_date,line_item_line_item_type,line_item_unblended_cost,line_item_net_unblended_cost,savings_plan_savings_plan_effective_cost,savings_plan_total_commitment_to_date,savings_plan_used_commitment,reservation_effective_cost,reservation_unused_amortized_upfront_fee_for_billing_period,reservation_unused_recurring_fee,reservation_reservation_a_r_n
+2024-09-01,Usage,10,9,,,,,,,
+2024-09-01,SavingsPlanCoveredUsage,5,5,3,,,,,,
+2024-09-01,SavingsPlanNegation,-5,-5,,,,,,,
+2024-09-01,SavingsPlanRecurringFee,4,4,,4,3.5,,,,
+2024-09-01,SavingsPlanUpfrontFee,1000,1000,,,,,,,
+2024-09-01,DiscountedUsage,0,0,,,,2,,,
+2024-09-01,RIFee,6,6,,,,,1,0.5,arn:aws:ec2:reserved
+2024-09-01,Fee,500,500,,,,,,,arn:aws:ec2:reserved
+`
+	items, err := readCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("readCSV() error = %v", err)
+	}
+
+	want := []float64{10, 3, 0, 0.5, 0, 2, 1.5, 0}
+	for i, item := range items {
+		if item.AmortizedCost != want[i] {
+			t.Errorf("row %d AmortizedCost = %v, want %v", i, item.AmortizedCost, want[i])
+		}
+	}
+
+	// Net metrics fall back to the gross column when no net column exists
+	if items[0].NetUnblendedCost != 9 || items[0].NetAmortizedCost != 9 {
+		t.Errorf("net costs = %v / %v, want 9 / 9", items[0].NetUnblendedCost, items[0].NetAmortizedCost)
+	}
+	if items[1].NetAmortizedCost != 3 {
+		t.Errorf("covered usage NetAmortizedCost = %v, want effective cost 3", items[1].NetAmortizedCost)
+	}
+	if items[0].BlendedCost != 10 {
+		t.Errorf("BlendedCost = %v, want unblended fallback 10", items[0].BlendedCost)
+	}
+}
+
+func TestReadCSV_NotCUR(t *testing.T) {
+	_, err := readCSV(strings.NewReader("a,b,c\n1,2,3\n"))
+	if err == nil {
+		t.Error("readCSV() should fail without a usage start column")
+	}
+}
+
+type parquetLineItemRow struct {
+	UsageStart    time.Time         `parquet:"line_item_usage_start_date,timestamp(millisecond)"`
+	Account       string            `parquet:"line_item_usage_account_id"`
+	ProductCode   string            `parquet:"line_item_product_code"`
+	RegionCode    string            `parquet:"product_region_code"`
+	UsageType     string            `parquet:"line_item_usage_type"`
+	UnblendedCost float64           `parquet:"line_item_unblended_cost"`
+	Tags          map[string]string `parquet:"resource_tags"`
+}
+
+func TestReadParquet(t *testing.T) {
+	var buf bytes.Buffer
+	writer := parquet.NewGenericWriter[parquetLineItemRow](&buf)
+	_, err := writer.Write([]parquetLineItemRow{
+		{
+			UsageStart:    time.Date(2024, 9, 1, 5, 0, 0, 0, time.UTC),
+			Account:       "111111111111",
+			ProductCode:   "AmazonEC2",
+			RegionCode:    "us-west-2",
+			UsageType:     "BoxUsage",
+			UnblendedCost: 3.5,
+			Tags:          map[string]string{"user_team": "platform"},
+		},
+		{
+			UsageStart:    time.Date(2024, 9, 2, 5, 0, 0, 0, time.UTC),
+			Account:       "111111111111",
+			ProductCode:   "AmazonRDS",
+			UnblendedCost: 1.0,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to write parquet: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close parquet writer: %v", err)
+	}
+
+	items, err := readParquet(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("readParquet() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("readParquet() returned %d items, want 2", len(items))
+	}
+
+	if !items[0].UsageStart.Equal(time.Date(2024, 9, 1, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("UsageStart = %v", items[0].UsageStart)
+	}
+	if items[0].Service != "AmazonEC2" || items[0].Region != "us-west-2" {
+		t.Errorf("unexpected dimensions: %+v", items[0])
+	}
+	if items[0].UnblendedCost != 3.5 {
+		t.Errorf("UnblendedCost = %v, want 3.5", items[0].UnblendedCost)
+	}
+	if items[0].Tag("team") != "platform" {
+		t.Errorf("Tag(team) = %q, want platform", items[0].Tag("team"))
+	}
+	if items[1].Service != "AmazonRDS" || len(items[1].Tags) != 0 {
+		t.Errorf("unexpected second item: %+v", items[1])
+	}
+}
+
+func TestLoad_Directory(t *testing.T) {
+	dir := t.TempDir()
+
+	plain := "line_item_usage_start_date,line_item_product_code,line_item_unblended_cost\n2024-09-02T00:00:00Z,AmazonS3,2\n"
+	if err := os.WriteFile(filepath.Join(dir, "part-0.csv"), []byte(plain), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("line_item_usage_start_date,line_item_product_code,line_item_unblended_cost\n2024-09-01T00:00:00Z,AmazonEC2,1\n"))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "part-1.csv.gz"), gz.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Manifests and other files are ignored
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ds, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(ds.Files) != 2 {
+		t.Errorf("Load() read %d files, want 2", len(ds.Files))
+	}
+	if len(ds.Items) != 2 {
+		t.Fatalf("Load() returned %d items, want 2", len(ds.Items))
+	}
+	if ds.Items[0].Service != "AmazonEC2" {
+		t.Errorf("items should be sorted by usage start, got %q first", ds.Items[0].Service)
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Error("Load() should fail when no CUR files are present")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load() should fail for a missing path")
+	}
+}
+
+func TestLineItemDimension(t *testing.T) {
+	item := LineItem{Service: "AmazonEC2", Account: "123", Region: "us-east-1", UsageType: "BoxUsage"}
+
+	tests := map[string]string{
+		"service":        "AmazonEC2",
+		"linked_account": "123",
+		"region":         "us-east-1",
+		"usage_type":     "BoxUsage",
+	}
+	for dim, want := range tests {
+		got, err := item.Dimension(dim)
+		if err != nil || got != want {
+			t.Errorf("Dimension(%q) = %q, %v; want %q", dim, got, err, want)
+		}
+	}
+
+	if _, err := item.Dimension("invalid"); err == nil {
+		t.Error("Dimension() should reject unknown group-by")
+	}
+}
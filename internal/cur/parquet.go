@@ -0,0 +1,190 @@
+package cur
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// parquetColumn describes how to turn one leaf column into row fields
+type parquetColumn struct {
+	name      string // normalized top-level column name
+	mapKey    bool   // key leaf of a MAP column (resource_tags, product)
+	mapValue  bool   // value leaf of a MAP column
+	timestamp *format.TimestampType
+}
+
+func readParquetFile(path string) ([]LineItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return readParquet(f, info.Size())
+}
+
+func readParquet(r io.ReaderAt, size int64) ([]LineItem, error) {
+	file, err := parquet.OpenFile(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open parquet file: %w", err)
+	}
+
+	schema := file.Schema()
+	columns := make(map[int]parquetColumn)
+	for _, path := range schema.Columns() {
+		leaf, ok := schema.Lookup(path...)
+		if !ok {
+			continue
+		}
+
+		col := parquetColumn{name: normalizeColumn(path[0])}
+		if len(path) > 1 {
+			switch path[len(path)-1] {
+			case "key":
+				col.mapKey = true
+			case "value":
+				col.mapValue = true
+			default:
+				continue
+			}
+		}
+		if lt := leaf.Node.Type().LogicalType(); lt != nil {
+			col.timestamp = lt.Timestamp
+		}
+		columns[leaf.ColumnIndex] = col
+	}
+
+	var usageStartFound bool
+	for _, col := range columns {
+		if col.name == colUsageStart {
+			usageStartFound = true
+		}
+	}
+	if !usageStartFound {
+		return nil, fmt.Errorf("missing %s column; is this a CUR file?", colUsageStart)
+	}
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+
+	var items []LineItem
+	rows := make([]parquet.Row, 256)
+	for {
+		n, err := reader.ReadRows(rows)
+		for _, values := range rows[:n] {
+			item, itemErr := parquetLineItem(values, columns)
+			if itemErr != nil {
+				return nil, fmt.Errorf("row %d: %w", len(items)+1, itemErr)
+			}
+			items = append(items, item)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parquet rows: %w", err)
+		}
+	}
+
+	return items, nil
+}
+
+func parquetLineItem(values parquet.Row, columns map[int]parquetColumn) (LineItem, error) {
+	fields := make(map[string]string)
+	mapKeys := make(map[string][]string)
+	mapValues := make(map[string][]string)
+
+	for _, v := range values {
+		col, ok := columns[v.Column()]
+		if !ok || v.IsNull() {
+			continue
+		}
+		s := parquetString(v, col.timestamp)
+		switch {
+		case col.mapKey:
+			mapKeys[col.name] = append(mapKeys[col.name], s)
+		case col.mapValue:
+			mapValues[col.name] = append(mapValues[col.name], s)
+		default:
+			fields[col.name] = s
+		}
+	}
+
+	zip := func(name string) map[string]string {
+		keys, vals := mapKeys[name], mapValues[name]
+		if len(keys) == 0 {
+			return nil
+		}
+		m := make(map[string]string, len(keys))
+		for i, k := range keys {
+			if i < len(vals) {
+				m[k] = vals[i]
+			}
+		}
+		return m
+	}
+
+	tags := make(map[string]string)
+	for key, value := range zip(colResourceTags) {
+		if value != "" {
+			tags[normalizeTagKey(key)] = value
+		}
+	}
+
+	return row{
+		get:     func(column string) string { return fields[column] },
+		tags:    tags,
+		product: zip(colProduct),
+	}.lineItem()
+}
+
+func parquetString(v parquet.Value, ts *format.TimestampType) string {
+	switch v.Kind() {
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return string(v.ByteArray())
+	case parquet.Double:
+		return strconv.FormatFloat(v.Double(), 'f', -1, 64)
+	case parquet.Float:
+		return strconv.FormatFloat(float64(v.Float()), 'f', -1, 32)
+	case parquet.Int64:
+		if ts != nil {
+			return timestampFromInt64(v.Int64(), ts).Format(time.RFC3339)
+		}
+		return strconv.FormatInt(v.Int64(), 10)
+	case parquet.Int32:
+		return strconv.FormatInt(int64(v.Int32()), 10)
+	case parquet.Int96:
+		// Legacy Spark/Athena timestamps: nanoseconds of day + Julian day
+		i := v.Int96()
+		nanos := int64(i[1])<<32 | int64(i[0])
+		days := int64(i[2]) - 2440588 // Julian day of the Unix epoch
+		return time.Unix(days*86400, nanos).UTC().Format(time.RFC3339)
+	case parquet.Boolean:
+		return strconv.FormatBool(v.Boolean())
+	default:
+		return v.String()
+	}
+}
+
+func timestampFromInt64(n int64, ts *format.TimestampType) time.Time {
+	switch {
+	case ts.Unit.Nanos != nil:
+		return time.Unix(0, n).UTC()
+	case ts.Unit.Micros != nil:
+		return time.UnixMicro(n).UTC()
+	default:
+		return time.UnixMilli(n).UTC()
+	}
+}
@@ -0,0 +1,212 @@
+package cur
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// dimensionNames maps Cost Explorer DIMENSION keys to line item dimensions
+var dimensionNames = map[string]string{
+	"SERVICE":        "service",
+	"LINKED_ACCOUNT": "linked_account",
+	"REGION":         "region",
+	"USAGE_TYPE":     "usage_type",
+}
+
+// GetCosts answers a Cost Explorer style request from the loaded line items,
+// so a Dataset can be used anywhere a cost.Source is expected
+func (d *Dataset) GetCosts(ctx context.Context, req cost.CostRequest) ([]cost.TimeBucket, error) {
+	groupKeys, err := groupKeyFuncs(req.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	match, err := filterFunc(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type groupSums struct {
+		keys    []string
+		metrics map[string]float64
+	}
+	buckets := make(map[time.Time]map[string]*groupSums)
+
+	for _, item := range d.Items {
+		if item.UsageStart.Before(req.Start) || !item.UsageStart.Before(req.End) || !match(item) {
+			continue
+		}
+
+		keys := make([]string, len(groupKeys))
+		for i, fn := range groupKeys {
+			keys[i] = fn(item)
+		}
+		id := strings.Join(keys, "\x00")
+
+		start := bucketStart(item.UsageStart, req.Granularity)
+		if buckets[start] == nil {
+			buckets[start] = make(map[string]*groupSums)
+		}
+		sums := buckets[start][id]
+		if sums == nil {
+			sums = &groupSums{keys: keys, metrics: make(map[string]float64)}
+			buckets[start][id] = sums
+		}
+		for _, metric := range req.Metrics {
+			value, err := item.Metric(metric)
+			if err != nil {
+				return nil, err
+			}
+			sums.metrics[metric] += value
+		}
+	}
+
+	starts := make([]time.Time, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	result := make([]cost.TimeBucket, 0, len(starts))
+	for _, start := range starts {
+		bucket := cost.TimeBucket{Start: start, End: bucketEnd(start, req.Granularity)}
+		for _, sums := range buckets[start] {
+			bucket.Groups = append(bucket.Groups, cost.GroupCost{Keys: sums.keys, Metrics: sums.metrics})
+		}
+		sort.Slice(bucket.Groups, func(i, j int) bool {
+			return strings.Join(bucket.Groups[i].Keys, "\x00") < strings.Join(bucket.Groups[j].Keys, "\x00")
+		})
+		result = append(result, bucket)
+	}
+
+	return result, nil
+}
+
+// Metric returns the line item's value for a Cost Explorer metric name
+func (li LineItem) Metric(name string) (float64, error) {
+	switch name {
+	case "UnblendedCost":
+		return li.UnblendedCost, nil
+	case "BlendedCost":
+		return li.BlendedCost, nil
+	case "NetUnblendedCost":
+		return li.NetUnblendedCost, nil
+	case "AmortizedCost":
+		return li.AmortizedCost, nil
+	case "NetAmortizedCost":
+		return li.NetAmortizedCost, nil
+	case "UsageQuantity":
+		return li.UsageAmount, nil
+	default:
+		return 0, fmt.Errorf("metric %s is not available from CUR data", name)
+	}
+}
+
+func groupKeyFuncs(defs []types.GroupDefinition) ([]func(LineItem) string, error) {
+	var fns []func(LineItem) string
+	for _, def := range defs {
+		key := aws.ToString(def.Key)
+		switch def.Type {
+		case types.GroupDefinitionTypeDimension:
+			name, ok := dimensionNames[key]
+			if !ok {
+				return nil, fmt.Errorf("group-by dimension %s is not supported for CUR data", key)
+			}
+			fns = append(fns, func(li LineItem) string {
+				value, _ := li.Dimension(name)
+				return value
+			})
+		case types.GroupDefinitionTypeTag:
+			// Cost Explorer reports tag groups as "key$value"
+			fns = append(fns, func(li LineItem) string {
+				return key + "$" + li.Tag(key)
+			})
+		default:
+			return nil, fmt.Errorf("group-by type %s is not supported for CUR data", def.Type)
+		}
+	}
+	return fns, nil
+}
+
+func filterFunc(expr *types.Expression) (func(LineItem) bool, error) {
+	if expr == nil {
+		return func(LineItem) bool { return true }, nil
+	}
+
+	switch {
+	case len(expr.And) > 0:
+		var fns []func(LineItem) bool
+		for i := range expr.And {
+			fn, err := filterFunc(&expr.And[i])
+			if err != nil {
+				return nil, err
+			}
+			fns = append(fns, fn)
+		}
+		return func(li LineItem) bool {
+			for _, fn := range fns {
+				if !fn(li) {
+					return false
+				}
+			}
+			return true
+		}, nil
+
+	case expr.Dimensions != nil:
+		name, ok := dimensionNames[string(expr.Dimensions.Key)]
+		if !ok {
+			return nil, fmt.Errorf("filter dimension %s is not supported for CUR data", expr.Dimensions.Key)
+		}
+		values := toSet(expr.Dimensions.Values)
+		return func(li LineItem) bool {
+			value, _ := li.Dimension(name)
+			return values[value]
+		}, nil
+
+	case expr.Tags != nil:
+		key := aws.ToString(expr.Tags.Key)
+		values := toSet(expr.Tags.Values)
+		return func(li LineItem) bool {
+			return values[li.Tag(key)]
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("filter expression is not supported for CUR data")
+	}
+}
+
+func bucketStart(t time.Time, gran types.Granularity) time.Time {
+	switch gran {
+	case types.GranularityHourly:
+		return t.Truncate(time.Hour)
+	case types.GranularityMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t.Truncate(24 * time.Hour)
+	}
+}
+
+func bucketEnd(start time.Time, gran types.Granularity) time.Time {
+	switch gran {
+	case types.GranularityHourly:
+		return start.Add(time.Hour)
+	case types.GranularityMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,128 @@
+package cur
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+func testDataset() *Dataset {
+	day := func(d, h int) time.Time { return time.Date(2024, 9, d, h, 0, 0, 0, time.UTC) }
+	return &Dataset{Items: []LineItem{
+		{UsageStart: day(2, 0), Service: "AmazonEC2", Account: "111", UnblendedCost: 40, UsageAmount: 4, Tags: map[string]string{"team": "platform"}},
+		{UsageStart: day(2, 5), Service: "AmazonEC2", Account: "111", UnblendedCost: 10, UsageAmount: 1, Tags: map[string]string{"team": "platform"}},
+		{UsageStart: day(3, 0), Service: "AmazonEC2", Account: "222", UnblendedCost: 50},
+		{UsageStart: day(9, 0), Service: "AmazonEC2", Account: "111", UnblendedCost: 150, Tags: map[string]string{"team": "platform"}},
+		{UsageStart: day(10, 0), Service: "AmazonS3", Account: "222", UnblendedCost: 25, Tags: map[string]string{"team": "data"}},
+		{UsageStart: day(20, 0), Service: "AmazonEC2", Account: "111", UnblendedCost: 999},
+	}}
+}
+
+func serviceGroup() []types.GroupDefinition {
+	return []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")}}
+}
+
+func TestGetCosts_DailyBuckets(t *testing.T) {
+	buckets, err := testDataset().GetCosts(context.Background(), cost.CostRequest{
+		Start:       time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 9, 4, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityDaily,
+		Metrics:     []string{"UnblendedCost", "UsageQuantity"},
+		GroupBy:     serviceGroup(),
+	})
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("GetCosts() returned %d buckets, want 2", len(buckets))
+	}
+
+	first := buckets[0]
+	if !first.Start.Equal(time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)) || !first.End.Equal(time.Date(2024, 9, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected bucket range: %v - %v", first.Start, first.End)
+	}
+	if len(first.Groups) != 1 || first.Groups[0].Metrics["UnblendedCost"] != 50 || first.Groups[0].Metrics["UsageQuantity"] != 5 {
+		t.Errorf("unexpected first bucket groups: %+v", first.Groups)
+	}
+}
+
+func TestGetCosts_TagGroupAndFilter(t *testing.T) {
+	buckets, err := testDataset().GetCosts(context.Background(), cost.CostRequest{
+		Start:       time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityMonthly,
+		Metrics:     []string{"UnblendedCost"},
+		GroupBy: append(serviceGroup(), types.GroupDefinition{
+			Type: types.GroupDefinitionTypeTag, Key: aws.String("team"),
+		}),
+		Filter: &types.Expression{Dimensions: &types.DimensionValues{
+			Key: types.DimensionLinkedAccount, Values: []string{"111"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("GetCosts() error = %v", err)
+	}
+	if len(buckets) != 1 || len(buckets[0].Groups) != 1 {
+		t.Fatalf("expected one monthly bucket with one group, got %+v", buckets)
+	}
+	group := buckets[0].Groups[0]
+	if group.Keys[0] != "AmazonEC2" || group.Keys[1] != "team$platform" {
+		t.Errorf("unexpected keys: %v", group.Keys)
+	}
+	if group.Metrics["UnblendedCost"] != 200 {
+		t.Errorf("UnblendedCost = %v, want 200", group.Metrics["UnblendedCost"])
+	}
+}
+
+func TestGetCosts_Unsupported(t *testing.T) {
+	ds := testDataset()
+	ctx := context.Background()
+
+	_, err := ds.GetCosts(ctx, cost.CostRequest{
+		GroupBy: []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: aws.String("INSTANCE_TYPE")}},
+	})
+	if err == nil {
+		t.Error("GetCosts() should reject unsupported dimensions")
+	}
+
+	_, err = ds.GetCosts(ctx, cost.CostRequest{
+		Start:   time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC),
+		Metrics: []string{"NotAMetric"},
+	})
+	if err == nil {
+		t.Error("GetCosts() should reject unknown metrics")
+	}
+}
+
+// Dataset plugs straight into cost.Query
+func TestDataset_AsCostSource(t *testing.T) {
+	priorStart := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	window := &timewin.Window{
+		PriorStart:   priorStart,
+		PriorEnd:     priorStart.AddDate(0, 0, 7),
+		CurrentStart: priorStart.AddDate(0, 0, 7),
+		CurrentEnd:   priorStart.AddDate(0, 0, 14),
+	}
+
+	deltas, err := cost.Query(context.Background(), testDataset(), cost.QueryParams{
+		Window:    window,
+		GroupBy:   "service",
+		TagKey:    "team",
+		TagValues: []string{"platform"},
+	})
+	if err != nil {
+		t.Fatalf("cost.Query() error = %v", err)
+	}
+	if len(deltas) != 1 {
+		t.Fatalf("cost.Query() returned %d deltas, want 1", len(deltas))
+	}
+	if deltas[0].Key != "AmazonEC2 | team$platform" || deltas[0].AbsoluteDelta != 100 {
+		t.Errorf("unexpected delta: %+v", deltas[0])
+	}
+}
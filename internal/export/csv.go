@@ -4,21 +4,34 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
 )
 
-// WriteCSV exports cost deltas to CSV format
-func WriteCSV(w io.Writer, deltas []cost.Delta) error {
+// WriteCSV exports cost deltas to CSV format. When the window is given, every
+// row is labeled with the compared periods and how their days are bucketed.
+func WriteCSV(w io.Writer, deltas []cost.Delta, window *timewin.Window) error {
 	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Write header
-	header := []string{"Key", "Current Cost", "Prior Cost", "Absolute Delta", "Percent Change", "New Spender", "Currency"}
+	header := []string{"Key", "Current Cost", "Prior Cost", "Absolute Delta", "Percent Change", "New Spender", "Currency", "Metric"}
+	if window != nil {
+		header = append(header, "Current Period", "Prior Period", "Bucketing")
+	}
+
+	// Deltas with series get one column per day or hour of both periods
+	if len(deltas) > 0 {
+		header = append(header, seriesColumns(deltas[0])...)
+	}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
+	metric := cost.MetricOf(deltas)
+
 	// Write data rows
 	for _, d := range deltas {
 		newSpender := "No"
@@ -34,6 +47,19 @@ func WriteCSV(w io.Writer, deltas []cost.Delta) error {
 			fmt.Sprintf("%.2f", d.PercentChange),
 			newSpender,
 			d.Currency,
+			metric,
+		}
+		if window != nil {
+			row = append(row,
+				window.Label(window.CurrentStart, window.CurrentEnd),
+				window.Label(window.PriorStart, window.PriorEnd),
+				window.Bucketing())
+		}
+		for _, p := range d.PriorSeries {
+			row = append(row, fmt.Sprintf("%.2f", p.Value))
+		}
+		for _, p := range d.CurrentSeries {
+			row = append(row, fmt.Sprintf("%.2f", p.Value))
 		}
 
 		if err := writer.Write(row); err != nil {
@@ -43,3 +69,29 @@ func WriteCSV(w io.Writer, deltas []cost.Delta) error {
 
 	return nil
 }
+
+// seriesColumns names the series columns of a delta by date, or by UTC hour
+// when the series is hourly
+func seriesColumns(d cost.Delta) []string {
+	var dates []time.Time
+	for _, p := range d.PriorSeries {
+		dates = append(dates, p.Date)
+	}
+	for _, p := range d.CurrentSeries {
+		dates = append(dates, p.Date)
+	}
+
+	layout := "2006-01-02"
+	for _, date := range dates {
+		if !date.UTC().Truncate(24 * time.Hour).Equal(date) {
+			layout = "2006-01-02T15:04Z"
+			break
+		}
+	}
+
+	columns := make([]string, len(dates))
+	for i, date := range dates {
+		columns[i] = date.UTC().Format(layout)
+	}
+	return columns
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+	"github.com/pfrederiksen/cost-blame/internal/waste"
 )
 
 func TestWriteCSV(t *testing.T) {
@@ -31,7 +35,7 @@ func TestWriteCSV(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := WriteCSV(&buf, deltas)
+	err := WriteCSV(&buf, deltas, nil)
 	if err != nil {
 		t.Fatalf("WriteCSV() error = %v", err)
 	}
@@ -52,6 +56,11 @@ func TestWriteCSV(t *testing.T) {
 		t.Error("NewService row missing or incorrect")
 	}
 
+	// Deltas without a metric are labeled with the default
+	if !strings.Contains(output, ",Metric\n") || !strings.Contains(output, "No,USD,UnblendedCost") {
+		t.Error("Metric column missing or incorrect")
+	}
+
 	// Check row count (header + 2 data rows)
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	if len(lines) != 3 {
@@ -61,7 +70,7 @@ func TestWriteCSV(t *testing.T) {
 
 func TestWriteCSV_Empty(t *testing.T) {
 	var buf bytes.Buffer
-	err := WriteCSV(&buf, []cost.Delta{})
+	err := WriteCSV(&buf, []cost.Delta{}, nil)
 	if err != nil {
 		t.Fatalf("WriteCSV() error = %v", err)
 	}
@@ -93,7 +102,7 @@ func TestWriteCSV_NegativeValues(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := WriteCSV(&buf, deltas)
+	err := WriteCSV(&buf, deltas, nil)
 	if err != nil {
 		t.Fatalf("WriteCSV() error = %v", err)
 	}
@@ -120,7 +129,7 @@ func TestWriteCSV_SpecialCharactersInKey(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := WriteCSV(&buf, deltas)
+	err := WriteCSV(&buf, deltas, nil)
 	if err != nil {
 		t.Fatalf("WriteCSV() error = %v", err)
 	}
@@ -149,7 +158,7 @@ func TestWriteCSV_LargeDataset(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := WriteCSV(&buf, deltas)
+	err := WriteCSV(&buf, deltas, nil)
 	if err != nil {
 		t.Fatalf("WriteCSV() error = %v", err)
 	}
@@ -159,3 +168,83 @@ func TestWriteCSV_LargeDataset(t *testing.T) {
 		t.Errorf("Expected 101 lines, got %d", len(lines))
 	}
 }
+
+func TestWriteCSV_Period(t *testing.T) {
+	window := &timewin.Window{
+		CurrentStart: time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC),
+		CurrentEnd:   time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		PriorStart:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PriorEnd:     time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []cost.Delta{{Key: "AmazonEC2", Currency: "USD"}}, window); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, ",Metric,Current Period,Prior Period,Bucketing\n") {
+		t.Error("period columns missing from header")
+	}
+	if !strings.Contains(output, ",2026-10-08 → 2026-10-14,2026-10-01 → 2026-10-07,UTC days\n") {
+		t.Errorf("period columns missing from row:\n%s", output)
+	}
+}
+
+func TestWriteCSV_Series(t *testing.T) {
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	deltas := []cost.Delta{{
+		Key:           "AmazonEC2",
+		Currency:      "USD",
+		PriorSeries:   []cost.SeriesPoint{{Date: day, Value: 10}},
+		CurrentSeries: []cost.SeriesPoint{{Date: day.AddDate(0, 0, 1), Value: 90}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, deltas, nil); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, ",Metric,2026-10-01,2026-10-02\n") || !strings.Contains(output, ",UnblendedCost,10.00,90.00\n") {
+		t.Errorf("expected one column per date, got:\n%s", output)
+	}
+
+	// Hourly series are labeled by UTC hour
+	deltas[0].CurrentSeries[0].Date = day.Add(time.Hour)
+	if got := seriesColumns(deltas[0]); got[1] != "2026-10-01T01:00Z" {
+		t.Errorf("seriesColumns() = %v, want hourly labels", got)
+	}
+}
+
+func TestWriteWasteCSV(t *testing.T) {
+	items := []waste.Item{{
+		Reason:      waste.UnattachedVolume,
+		Detail:      "100 GiB gp2 volume not attached to any instance",
+		MonthlyCost: 10,
+		Resource: inventory.Resource{
+			ARN:     "arn:aws:ec2:us-east-1::volume/vol-1",
+			ID:      "vol-1",
+			Type:    "EBS Volume",
+			Region:  "us-east-1",
+			Account: "111111111111",
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteWasteCSV(&buf, items); err != nil {
+		t.Fatalf("WriteWasteCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got %d lines", len(lines))
+	}
+	if lines[0] != "Account,Region,ID,Type,Reason,Monthly Cost,Detail,ARN" {
+		t.Errorf("unexpected header: %s", lines[0])
+	}
+	want := "111111111111,us-east-1,vol-1,EBS Volume,unattached-volume,10.00,100 GiB gp2 volume not attached to any instance,arn:aws:ec2:us-east-1::volume/vol-1"
+	if lines[1] != want {
+		t.Errorf("row = %s, want %s", lines[1], want)
+	}
+}
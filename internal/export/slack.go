@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/pfrederiksen/cost-blame/internal/budget"
 	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
 )
 
 // SlackMessage represents a Slack webhook payload
@@ -45,8 +47,9 @@ type SlackField struct {
 	Short bool   `json:"short"`
 }
 
-// SendToSlack sends cost spike data to a Slack webhook
-func SendToSlack(webhookURL string, deltas []cost.Delta, topN int) error {
+// SendToSlack sends cost spike data to a Slack webhook. The window, if not
+// nil, labels the compared periods.
+func SendToSlack(webhookURL string, deltas []cost.Delta, topN int, window *timewin.Window) error {
 	if len(deltas) == 0 {
 		return fmt.Errorf("no data to send")
 	}
@@ -56,6 +59,8 @@ func SendToSlack(webhookURL string, deltas []cost.Delta, topN int) error {
 		deltas = deltas[:topN]
 	}
 
+	metric := cost.MetricOf(deltas)
+
 	// Build message
 	msg := SlackMessage{
 		Text: ":warning: *AWS Cost Spike Alert*",
@@ -71,12 +76,22 @@ func SendToSlack(webhookURL string, deltas []cost.Delta, topN int) error {
 				Type: "section",
 				Text: &SlackText{
 					Type: "mrkdwn",
-					Text: fmt.Sprintf("Detected %d cost changes. Top movers:", len(deltas)),
+					Text: fmt.Sprintf("Detected %d cost changes in %s. Top movers:", len(deltas), metric),
 				},
 			},
 		},
 	}
 
+	if window != nil {
+		msg.Blocks = append(msg.Blocks, SlackBlock{
+			Type: "section",
+			Text: &SlackText{
+				Type: "mrkdwn",
+				Text: window.Describe(),
+			},
+		})
+	}
+
 	// Add top spikes as attachments
 	for i, d := range deltas {
 		if i >= 5 { // Limit to 5 for readability
@@ -97,12 +112,12 @@ func SendToSlack(webhookURL string, deltas []cost.Delta, topN int) error {
 			Fields: []SlackField{
 				{
 					Title: "Current Cost",
-					Value: fmt.Sprintf("$%.2f", d.CurrentCost),
+					Value: cost.FormatAmount(metric, d.CurrentCost),
 					Short: true,
 				},
 				{
 					Title: "Delta",
-					Value: fmt.Sprintf("$%.2f", d.AbsoluteDelta),
+					Value: cost.FormatAmount(metric, d.AbsoluteDelta),
 					Short: true,
 				},
 				{
@@ -112,7 +127,7 @@ func SendToSlack(webhookURL string, deltas []cost.Delta, topN int) error {
 				},
 				{
 					Title: "Prior Cost",
-					Value: fmt.Sprintf("$%.2f", d.PriorCost),
+					Value: cost.FormatAmount(metric, d.PriorCost),
 					Short: true,
 				},
 			},
@@ -129,7 +144,76 @@ func SendToSlack(webhookURL string, deltas []cost.Delta, topN int) error {
 		msg.Attachments = append(msg.Attachments, attachment)
 	}
 
-	// Send to Slack
+	return postToSlack(webhookURL, msg)
+}
+
+// SendBudgetsToSlack sends breached budgets to a Slack webhook
+func SendBudgetsToSlack(webhookURL string, results []budget.Result) error {
+	var breached []budget.Result
+	for _, r := range results {
+		if r.Status == budget.StatusBreach {
+			breached = append(breached, r)
+		}
+	}
+	if len(breached) == 0 {
+		return fmt.Errorf("no breached budgets to send")
+	}
+
+	msg := SlackMessage{
+		Text: ":rotating_light: *AWS Budget Breach*",
+		Blocks: []SlackBlock{
+			{
+				Type: "header",
+				Text: &SlackText{
+					Type: "plain_text",
+					Text: "AWS Budget Breached",
+				},
+			},
+			{
+				Type: "section",
+				Text: &SlackText{
+					Type: "mrkdwn",
+					Text: fmt.Sprintf("%d of %d budgets are over their monthly amount:", len(breached), len(results)),
+				},
+			},
+		},
+	}
+
+	for _, r := range breached {
+		msg.Attachments = append(msg.Attachments, SlackAttachment{
+			Color: "danger",
+			Title: r.Name,
+			Text:  r.Scope,
+			Fields: []SlackField{
+				{
+					Title: "Budget",
+					Value: cost.FormatAmount(r.Metric, r.Amount),
+					Short: true,
+				},
+				{
+					Title: "Month to Date",
+					Value: fmt.Sprintf("%s (%.0f%%)", cost.FormatAmount(r.Metric, r.Actual), r.PercentUsed),
+					Short: true,
+				},
+				{
+					Title: "Projected Month End",
+					Value: fmt.Sprintf("%s (%.0f%%)", cost.FormatAmount(r.Metric, r.Projected), r.PercentProjected),
+					Short: true,
+				},
+				{
+					Title: "Metric",
+					Value: r.Metric,
+					Short: true,
+				},
+			},
+		})
+	}
+
+	return postToSlack(webhookURL, msg)
+}
+
+// postToSlack delivers a message to a Slack webhook
+func postToSlack(webhookURL string, msg SlackMessage) error {
 	payload, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal Slack message: %w", err)
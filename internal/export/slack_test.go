@@ -2,8 +2,11 @@ package export
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/pfrederiksen/cost-blame/internal/budget"
 	"github.com/pfrederiksen/cost-blame/internal/cost"
 )
 
@@ -132,7 +135,7 @@ func TestSlackMessageJSON(t *testing.T) {
 func TestSendToSlack_EmptyDeltas(t *testing.T) {
 	// Test that sending empty deltas returns an error
 	// We don't actually send to HTTP, just verify validation
-	err := SendToSlack("https://hooks.slack.com/test", []cost.Delta{}, 5)
+	err := SendToSlack("https://hooks.slack.com/test", []cost.Delta{}, 5, nil)
 	if err == nil {
 		t.Error("SendToSlack should return error for empty deltas")
 	}
@@ -257,3 +260,27 @@ func TestSlackMessageTopNLimit(t *testing.T) {
 	t.Log("Even if topN parameter is higher, only 5 attachments are created")
 	t.Skip("Requires HTTP mock server for full test")
 }
+
+func TestSendBudgetsToSlack(t *testing.T) {
+	var received SlackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	results := []budget.Result{
+		{Definition: budget.Definition{Name: "ec2-prod", Amount: 1000, Metric: "UnblendedCost"}, Actual: 1200, Projected: 3000, Status: budget.StatusBreach},
+		{Definition: budget.Definition{Name: "s3", Amount: 1000, Metric: "UnblendedCost"}, Status: budget.StatusOK},
+	}
+
+	if err := SendBudgetsToSlack(server.URL, results); err != nil {
+		t.Fatalf("SendBudgetsToSlack() error = %v", err)
+	}
+	if len(received.Attachments) != 1 || received.Attachments[0].Title != "ec2-prod" {
+		t.Errorf("expected only the breached budget, got %+v", received.Attachments)
+	}
+
+	if err := SendBudgetsToSlack(server.URL, results[1:]); err == nil {
+		t.Error("SendBudgetsToSlack() should refuse to send without breaches")
+	}
+}
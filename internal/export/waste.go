@@ -0,0 +1,38 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/pfrederiksen/cost-blame/internal/waste"
+)
+
+// WriteWasteCSV exports waste items to CSV format, one row per item
+func WriteWasteCSV(w io.Writer, items []waste.Item) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Account", "Region", "ID", "Type", "Reason", "Monthly Cost", "Detail", "ARN"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, item := range items {
+		row := []string{
+			item.Resource.Account,
+			item.Resource.Region,
+			item.Resource.ID,
+			item.Resource.Type,
+			item.Reason,
+			fmt.Sprintf("%.2f", item.MonthlyCost),
+			item.Detail,
+			item.Resource.ARN,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	return nil
+}
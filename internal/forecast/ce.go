@@ -0,0 +1,126 @@
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// ForecastAPI is the subset of the Cost Explorer client used for forecasts
+type ForecastAPI interface {
+	GetCostForecast(ctx context.Context, params *costexplorer.GetCostForecastInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostForecastOutput, error)
+}
+
+// CostExplorerForecaster fetches Cost Explorer's own forecasts for comparison
+type CostExplorerForecaster struct {
+	client ForecastAPI
+}
+
+// NewCostExplorerForecaster creates a forecaster backed by a Cost Explorer client
+func NewCostExplorerForecaster(client ForecastAPI) *CostExplorerForecaster {
+	return &CostExplorerForecaster{client: client}
+}
+
+// ceMetrics maps metric names to GetCostForecast's metric enum
+var ceMetrics = map[string]types.Metric{
+	"UnblendedCost":    types.MetricUnblendedCost,
+	"AmortizedCost":    types.MetricAmortizedCost,
+	"NetAmortizedCost": types.MetricNetAmortizedCost,
+	"NetUnblendedCost": types.MetricNetUnblendedCost,
+	"BlendedCost":      types.MetricBlendedCost,
+	"UsageQuantity":    types.MetricUsageQuantity,
+}
+
+// Compare fills in Cost Explorer's forecast for every projection of fc,
+// whose key was grouped by groupDefs. Cost Explorer cannot group forecasts,
+// so each key is a separate filtered call.
+func (f *CostExplorerForecaster) Compare(ctx context.Context, fc *Forecast, groupDefs []types.GroupDefinition, today time.Time, confidence float64) error {
+	metric, ok := ceMetrics[fc.Metric]
+	if !ok {
+		return fmt.Errorf("Cost Explorer cannot forecast metric %s", fc.Metric)
+	}
+
+	var filter *types.Expression
+	if fc.Key != TotalKey {
+		var err error
+		if filter, err = keyFilter(fc.Key, groupDefs); err != nil {
+			return err
+		}
+	}
+
+	for i := range fc.Projections {
+		p := &fc.Projections[i]
+
+		output, err := f.client.GetCostForecast(ctx, &costexplorer.GetCostForecastInput{
+			TimePeriod: &types.DateInterval{
+				Start: aws.String(today.Format("2006-01-02")),
+				End:   aws.String(p.End.Format("2006-01-02")),
+			},
+			Granularity:             types.GranularityMonthly,
+			Metric:                  metric,
+			Filter:                  filter,
+			PredictionIntervalLevel: aws.Int32(int32(math.Round(confidence * 100))),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get Cost Explorer forecast for %s: %w", fc.Key, err)
+		}
+
+		// The period total has no interval, so bounds are summed per month
+		est := &Estimate{Total: p.Actual, Lower: p.Actual, Upper: p.Actual}
+		if output.Total != nil {
+			est.Total += parseAmount(output.Total.Amount)
+		}
+		for _, r := range output.ForecastResultsByTime {
+			est.Lower += parseAmount(r.PredictionIntervalLowerBound)
+			est.Upper += parseAmount(r.PredictionIntervalUpperBound)
+		}
+		p.CostExplorer = est
+	}
+
+	return nil
+}
+
+func parseAmount(s *string) float64 {
+	f, _ := strconv.ParseFloat(aws.ToString(s), 64)
+	return f
+}
+
+// keyFilter builds the filter selecting one group key, with a condition for
+// each of its dimensions and tags. Cost Explorer reports tag keys as
+// "key$value", with an empty value for untagged costs.
+func keyFilter(key string, groupDefs []types.GroupDefinition) (*types.Expression, error) {
+	parts := strings.Split(key, " | ")
+	if len(parts) != len(groupDefs) {
+		return nil, fmt.Errorf("key %q doesn't match its %d groupings", key, len(groupDefs))
+	}
+
+	exprs := make([]types.Expression, len(parts))
+	for i, gd := range groupDefs {
+		if gd.Type != types.GroupDefinitionTypeTag {
+			exprs[i] = types.Expression{Dimensions: &types.DimensionValues{
+				Key:    types.Dimension(aws.ToString(gd.Key)),
+				Values: []string{parts[i]},
+			}}
+			continue
+		}
+
+		_, value, _ := strings.Cut(parts[i], "$")
+		tag := &types.TagValues{Key: gd.Key, Values: []string{value}}
+		if value == "" {
+			tag = &types.TagValues{Key: gd.Key, MatchOptions: []types.MatchOption{types.MatchOptionAbsent}}
+		}
+		exprs[i] = types.Expression{Tags: tag}
+	}
+
+	if len(exprs) == 1 {
+		return &exprs[0], nil
+	}
+	return &types.Expression{And: exprs}, nil
+}
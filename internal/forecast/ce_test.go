@@ -0,0 +1,108 @@
+package forecast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// fakeForecastAPI returns a fixed forecast and records every input
+type fakeForecastAPI struct {
+	inputs []costexplorer.GetCostForecastInput
+}
+
+func (f *fakeForecastAPI) GetCostForecast(ctx context.Context, params *costexplorer.GetCostForecastInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostForecastOutput, error) {
+	f.inputs = append(f.inputs, *params)
+	return &costexplorer.GetCostForecastOutput{
+		Total: &types.MetricValue{Amount: aws.String("500")},
+		ForecastResultsByTime: []types.ForecastResult{{
+			PredictionIntervalLowerBound: aws.String("400"),
+			PredictionIntervalUpperBound: aws.String("650"),
+		}},
+	}, nil
+}
+
+var serviceGroup = []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")}}
+
+func TestCompare(t *testing.T) {
+	today := time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC)
+	fc := &Forecast{
+		Key:    "AmazonEC2",
+		Metric: "AmortizedCost",
+		Projections: []Projection{
+			{Period: "month", End: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), Actual: 1000},
+		},
+	}
+
+	client := &fakeForecastAPI{}
+	if err := NewCostExplorerForecaster(client).Compare(context.Background(), fc, serviceGroup, today, 0.8); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+
+	in := client.inputs[0]
+	if aws.ToString(in.TimePeriod.Start) != "2024-11-11" || aws.ToString(in.TimePeriod.End) != "2024-12-01" {
+		t.Errorf("unexpected time period: %s - %s", aws.ToString(in.TimePeriod.Start), aws.ToString(in.TimePeriod.End))
+	}
+	if in.Metric != types.MetricAmortizedCost || aws.ToInt32(in.PredictionIntervalLevel) != 80 {
+		t.Errorf("unexpected metric %v or level %v", in.Metric, aws.ToInt32(in.PredictionIntervalLevel))
+	}
+	if in.Filter == nil || in.Filter.Dimensions.Key != types.DimensionService || in.Filter.Dimensions.Values[0] != "AmazonEC2" {
+		t.Errorf("expected SERVICE filter, got %+v", in.Filter)
+	}
+
+	ce := fc.Projections[0].CostExplorer
+	if ce == nil || ce.Total != 1500 || ce.Lower != 1400 || ce.Upper != 1650 {
+		t.Errorf("unexpected Cost Explorer estimate: %+v", ce)
+	}
+
+	// Levels are rounded to the nearest percent, not truncated
+	NewCostExplorerForecaster(client).Compare(context.Background(), fc, serviceGroup, today, 0.57)
+	if level := aws.ToInt32(client.inputs[1].PredictionIntervalLevel); level != 57 {
+		t.Errorf("level for 0.57 = %d, want 57", level)
+	}
+	client.inputs = client.inputs[:1]
+
+	// The total row is not filtered
+	total := &Forecast{Key: TotalKey, Metric: "UnblendedCost", Projections: fc.Projections}
+	NewCostExplorerForecaster(client).Compare(context.Background(), total, serviceGroup, today, 0.8)
+	if client.inputs[1].Filter != nil {
+		t.Errorf("total forecast should not be filtered, got %+v", client.inputs[1].Filter)
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	groupDefs := []types.GroupDefinition{
+		{Type: types.GroupDefinitionTypeDimension, Key: aws.String("REGION")},
+		{Type: types.GroupDefinitionTypeTag, Key: aws.String("team")},
+	}
+
+	f, err := keyFilter("us-east-1 | team$web", groupDefs)
+	if err != nil {
+		t.Fatalf("keyFilter() error = %v", err)
+	}
+	if len(f.And) != 2 || f.And[0].Dimensions.Key != types.DimensionRegion || f.And[0].Dimensions.Values[0] != "us-east-1" {
+		t.Fatalf("keyFilter() = %+v, want REGION and tag conditions", f)
+	}
+	if tag := f.And[1].Tags; aws.ToString(tag.Key) != "team" || len(tag.Values) != 1 || tag.Values[0] != "web" {
+		t.Errorf("tag condition = %+v, want team=web", tag)
+	}
+
+	// Untagged costs match an absent tag
+	f, err = keyFilter("us-east-1 | team$", groupDefs)
+	if err != nil || len(f.And[1].Tags.MatchOptions) != 1 || f.And[1].Tags.MatchOptions[0] != types.MatchOptionAbsent {
+		t.Errorf("keyFilter() untagged = %+v, %v, want an ABSENT match", f, err)
+	}
+
+	f, err = keyFilter("SMS-USE1-Requests", []types.GroupDefinition{{Type: types.GroupDefinitionTypeDimension, Key: aws.String("USAGE_TYPE")}})
+	if err != nil || f.Dimensions == nil || f.Dimensions.Key != types.DimensionUsageType {
+		t.Errorf("keyFilter() usage type = %+v, %v, want a USAGE_TYPE filter", f, err)
+	}
+
+	if _, err := keyFilter("AmazonEC2", groupDefs); err == nil {
+		t.Error("keyFilter() with too few parts should fail")
+	}
+}
@@ -0,0 +1,185 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+)
+
+// TotalKey is the key of the forecast for the sum of all groups
+const TotalKey = "Total"
+
+// Config holds configuration for forecasting
+type Config struct {
+	HistoryDays int       // Number of days the model is fitted on (default: 90)
+	Confidence  float64   // Prediction interval level (default: 0.8)
+	Metric      string    // Cost Explorer metric (default: UnblendedCost)
+	Today       time.Time // First day without final data (default: today UTC)
+}
+
+// Estimate is a period total with its prediction interval
+type Estimate struct {
+	Total float64
+	Lower float64
+	Upper float64
+}
+
+// Projection is the forecast of one group key for a calendar period
+type Projection struct {
+	Period   string    // month or quarter
+	Start    time.Time // first day of the period
+	End      time.Time // first day after the period
+	Actual   float64   // cost of the closed days so far
+	Forecast float64   // expected cost of the remaining days
+	Estimate           // Actual + Forecast, with interval
+
+	// Cost Explorer's own forecast for the same period, when requested
+	CostExplorer *Estimate `json:",omitempty"`
+}
+
+// Forecast holds the projections of one group key
+type Forecast struct {
+	Key         string
+	Metric      string
+	DailyTrend  float64 // fitted change in daily cost per day
+	Projections []Projection
+}
+
+func (c Config) withDefaults() Config {
+	if c.HistoryDays == 0 {
+		c.HistoryDays = 90
+	}
+	if c.Confidence == 0 {
+		c.Confidence = 0.8
+	}
+	if c.Metric == "" {
+		c.Metric = cost.DefaultMetric
+	}
+	if c.Today.IsZero() {
+		c.Today = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	return c
+}
+
+// FetchRange returns the [start, end) range of daily data Project needs:
+// the fitting history plus everything since the start of the quarter
+func (c Config) FetchRange() (time.Time, time.Time) {
+	c = c.withDefaults()
+	start := c.Today.AddDate(0, 0, -c.HistoryDays)
+	if qs := quarterStart(c.Today); qs.Before(start) {
+		start = qs
+	}
+	return start, c.Today
+}
+
+// Project fits a trend plus weekly seasonality model per group key and
+// projects each to the end of the current month and quarter. A forecast for
+// the sum of all keys comes first; the rest are sorted by month-end total.
+func Project(series map[string][]anomaly.Point, config Config) ([]Forecast, error) {
+	config = config.withDefaults()
+
+	z, err := ZScore(config.Confidence)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchStart, _ := config.FetchRange()
+	total := make([]float64, dayCount(fetchStart, config.Today))
+
+	var forecasts []Forecast
+	for key, points := range series {
+		daily := anomaly.Align(points, fetchStart, config.Today)
+		for i, v := range daily {
+			total[i] += v
+		}
+
+		fc, err := project(key, daily, fetchStart, config, z)
+		if err != nil {
+			// Keys with too little history are skipped rather than failing the run
+			continue
+		}
+		forecasts = append(forecasts, fc)
+	}
+
+	sort.Slice(forecasts, func(i, j int) bool {
+		return forecasts[i].Projections[0].Total > forecasts[j].Projections[0].Total
+	})
+
+	totalForecast, err := project(TotalKey, total, fetchStart, config, z)
+	if err != nil {
+		return nil, fmt.Errorf("cannot forecast total: %w", err)
+	}
+
+	return append([]Forecast{totalForecast}, forecasts...), nil
+}
+
+func project(key string, daily []float64, start time.Time, config Config, z float64) (Forecast, error) {
+	// Fit on the most recent HistoryDays only
+	fitStart := config.Today.AddDate(0, 0, -config.HistoryDays)
+	offset := dayCount(start, fitStart)
+	if offset < 0 {
+		offset = 0
+	}
+
+	model, err := Fit(start.AddDate(0, 0, offset), daily[offset:])
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	fc := Forecast{
+		Key:        key,
+		Metric:     config.Metric,
+		DailyTrend: model.Slope(),
+	}
+
+	for _, period := range []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"month", monthStart(config.Today), monthStart(config.Today).AddDate(0, 1, 0)},
+		{"quarter", quarterStart(config.Today), quarterStart(config.Today).AddDate(0, 3, 0)},
+	} {
+		actual := 0.0
+		for i := dayCount(start, period.start); i < len(daily); i++ {
+			actual += daily[i]
+		}
+
+		var remaining []time.Time
+		for day := config.Today; day.Before(period.end); day = day.AddDate(0, 0, 1) {
+			remaining = append(remaining, day)
+		}
+		sum, halfWidth := model.PredictSum(remaining, z)
+
+		fc.Projections = append(fc.Projections, Projection{
+			Period:   period.name,
+			Start:    period.start,
+			End:      period.end,
+			Actual:   actual,
+			Forecast: sum,
+			Estimate: Estimate{
+				Total: actual + sum,
+				Lower: actual + math.Max(0, sum-halfWidth),
+				Upper: actual + sum + halfWidth,
+			},
+		})
+	}
+
+	return fc, nil
+}
+
+func dayCount(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func quarterStart(t time.Time) time.Time {
+	month := time.Month((int(t.Month())-1)/3*3 + 1)
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,92 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/anomaly"
+)
+
+func flatSeries(start, end time.Time, value float64) []anomaly.Point {
+	var points []anomaly.Point
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		points = append(points, anomaly.Point{Date: day, Value: value})
+	}
+	return points
+}
+
+func TestProject(t *testing.T) {
+	config := Config{Today: time.Date(2024, 11, 11, 0, 0, 0, 0, time.UTC), HistoryDays: 60}
+	start, end := config.FetchRange()
+
+	// Quarter started on Oct 1, 41 days back, inside the 60-day history
+	if !start.Equal(time.Date(2024, 9, 12, 0, 0, 0, 0, time.UTC)) || !end.Equal(config.Today) {
+		t.Fatalf("FetchRange() = %v - %v", start, end)
+	}
+
+	series := map[string][]anomaly.Point{
+		"AmazonEC2": flatSeries(start, end, 100),
+		"AmazonS3":  flatSeries(start, end, 10),
+		"NewThing":  flatSeries(end.AddDate(0, 0, -1), end, 5), // too little history
+	}
+
+	forecasts, err := Project(series, config)
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+
+	keys := []string{}
+	for _, fc := range forecasts {
+		keys = append(keys, fc.Key)
+	}
+	if len(forecasts) != 4 || keys[0] != TotalKey || keys[1] != "AmazonEC2" || keys[2] != "AmazonS3" {
+		t.Fatalf("unexpected forecast order: %v", keys)
+	}
+
+	ec2 := forecasts[1]
+	month := ec2.Projections[0]
+	if month.Period != "month" || !month.End.Equal(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected month projection: %+v", month)
+	}
+	// Nov 1-10 actual, Nov 11-30 forecast, flat $100/day
+	if month.Actual != 1000 || math.Abs(month.Forecast-2000) > 1e-6 || math.Abs(month.Total-3000) > 1e-6 {
+		t.Errorf("month = %v actual + %v forecast = %v, want 1000 + 2000", month.Actual, month.Forecast, month.Total)
+	}
+
+	quarter := ec2.Projections[1]
+	// Oct 1 - Nov 10 is 41 days, Nov 11 - Dec 31 is 51 days
+	if quarter.Actual != 4100 || math.Abs(quarter.Total-9200) > 1e-6 {
+		t.Errorf("quarter total = %v (actual %v), want 9200", quarter.Total, quarter.Actual)
+	}
+
+	// The total includes the short series that could not be fitted on its own
+	if math.Abs(forecasts[0].Projections[0].Actual-1105) > 1e-6 {
+		t.Errorf("total month actual = %v, want 1105", forecasts[0].Projections[0].Actual)
+	}
+}
+
+func TestProject_InvalidConfidence(t *testing.T) {
+	if _, err := Project(nil, Config{Confidence: 2}); err == nil {
+		t.Error("Project() should reject confidence outside (0, 1)")
+	}
+}
+
+func TestQuarterStart(t *testing.T) {
+	tests := []struct {
+		month time.Month
+		want  time.Month
+	}{
+		{time.January, time.January},
+		{time.March, time.January},
+		{time.April, time.April},
+		{time.September, time.July},
+		{time.December, time.October},
+	}
+	for _, tt := range tests {
+		got := quarterStart(time.Date(2024, tt.month, 15, 0, 0, 0, 0, time.UTC))
+		if got.Month() != tt.want || got.Day() != 1 {
+			t.Errorf("quarterStart(%v) = %v, want %v 1", tt.month, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// minSeasonalPoints is the shortest history that can support day-of-week
+// offsets; shorter series are fitted with a trend only
+const minSeasonalPoints = 14
+
+// Model is a linear trend plus day-of-week seasonality fitted by ordinary
+// least squares to a daily series
+type Model struct {
+	origin   time.Time   // day zero of the trend
+	seasonal bool        // whether day-of-week offsets are fitted
+	coef     []float64   // intercept, slope, then Tuesday..Sunday offsets from Monday
+	xtxInv   [][]float64 // (XᵀX)⁻¹, for prediction intervals
+	sigma    float64     // residual standard deviation
+}
+
+// Fit fits a model to values, one per day starting at start
+func Fit(start time.Time, values []float64) (*Model, error) {
+	m := &Model{
+		origin:   start,
+		seasonal: len(values) >= minSeasonalPoints,
+	}
+
+	p := m.numFeatures()
+	if len(values) <= p {
+		return nil, fmt.Errorf("need more than %d days of history, got %d", p, len(values))
+	}
+
+	// Accumulate the normal equations XᵀX b = Xᵀy
+	xtx := make([][]float64, p)
+	for i := range xtx {
+		xtx[i] = make([]float64, p)
+	}
+	xty := make([]float64, p)
+	for i, y := range values {
+		x := m.features(start.AddDate(0, 0, i))
+		for r := 0; r < p; r++ {
+			xty[r] += x[r] * y
+			for c := 0; c < p; c++ {
+				xtx[r][c] += x[r] * x[c]
+			}
+		}
+	}
+
+	inv, err := invert(xtx)
+	if err != nil {
+		return nil, err
+	}
+	m.xtxInv = inv
+
+	m.coef = make([]float64, p)
+	for r := 0; r < p; r++ {
+		for c := 0; c < p; c++ {
+			m.coef[r] += inv[r][c] * xty[c]
+		}
+	}
+
+	sse := 0.0
+	for i, y := range values {
+		resid := y - dot(m.coef, m.features(start.AddDate(0, 0, i)))
+		sse += resid * resid
+	}
+	m.sigma = math.Sqrt(sse / float64(len(values)-p))
+
+	return m, nil
+}
+
+// Slope returns the fitted trend per day
+func (m *Model) Slope() float64 {
+	return m.coef[1]
+}
+
+// Predict returns the expected value for a single day, never below zero
+func (m *Model) Predict(day time.Time) float64 {
+	return math.Max(0, dot(m.coef, m.features(day)))
+}
+
+// PredictSum returns the expected total over days and the half-width of its
+// prediction interval at z standard errors. The variance accounts for both
+// day-to-day noise and uncertainty in the fitted coefficients.
+func (m *Model) PredictSum(days []time.Time, z float64) (sum, halfWidth float64) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	x := make([]float64, m.numFeatures())
+	for _, day := range days {
+		sum += m.Predict(day)
+		for i, v := range m.features(day) {
+			x[i] += v
+		}
+	}
+
+	leverage := 0.0
+	for r := range x {
+		for c := range x {
+			leverage += x[r] * m.xtxInv[r][c] * x[c]
+		}
+	}
+
+	variance := m.sigma * m.sigma * (float64(len(days)) + leverage)
+	return sum, z * math.Sqrt(variance)
+}
+
+func (m *Model) numFeatures() int {
+	if m.seasonal {
+		return 8
+	}
+	return 2
+}
+
+func (m *Model) features(day time.Time) []float64 {
+	x := make([]float64, m.numFeatures())
+	x[0] = 1
+	x[1] = day.Sub(m.origin).Hours() / 24
+	if m.seasonal {
+		// Monday is the baseline; Tuesday..Sunday get their own offsets
+		offset := (int(day.Weekday()) + 6) % 7
+		if offset > 0 {
+			x[1+offset] = 1
+		}
+	}
+	return x
+}
+
+// ZScore returns the two-sided standard normal quantile for a confidence level
+// such as 0.8 or 0.95
+func ZScore(confidence float64) (float64, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return 0, fmt.Errorf("confidence must be between 0 and 1, got %v", confidence)
+	}
+	return math.Sqrt2 * math.Erfinv(confidence), nil
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// invert returns the inverse of a square matrix by Gauss-Jordan elimination
+func invert(a [][]float64) ([][]float64, error) {
+	n := len(a)
+	aug := make([][]float64, n)
+	for i := range a {
+		aug[i] = make([]float64, 2*n)
+		copy(aug[i], a[i])
+		aug[i][n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(aug[r][col]) > math.Abs(aug[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(aug[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("history is too short or too regular to fit a trend")
+		}
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+
+		div := aug[col][col]
+		for c := range aug[col] {
+			aug[col][c] /= div
+		}
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			factor := aug[r][col]
+			for c := range aug[r] {
+				aug[r][c] -= factor * aug[col][c]
+			}
+		}
+	}
+
+	inv := make([][]float64, n)
+	for i := range aug {
+		inv[i] = aug[i][n:]
+	}
+	return inv, nil
+}
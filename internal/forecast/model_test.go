@@ -0,0 +1,111 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// monday is a Monday, so index i falls on weekday (i % 7) from Monday
+var monday = time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+
+func TestFit_TrendAndSeasonality(t *testing.T) {
+	// 100 + 2/day, with weekends 30 lower
+	values := make([]float64, 56)
+	for i := range values {
+		values[i] = 100 + 2*float64(i)
+		if i%7 >= 5 {
+			values[i] -= 30
+		}
+	}
+
+	m, err := Fit(monday, values)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if math.Abs(m.Slope()-2) > 1e-6 {
+		t.Errorf("Slope() = %v, want 2", m.Slope())
+	}
+
+	saturday := monday.AddDate(0, 0, 61)
+	if got := m.Predict(saturday); math.Abs(got-(100+2*61-30)) > 1e-6 {
+		t.Errorf("Predict(saturday) = %v, want %v", got, 100+2*61-30)
+	}
+
+	// A perfect fit has no residual noise, only coefficient uncertainty
+	sum, half := m.PredictSum([]time.Time{saturday}, 1.28)
+	if math.Abs(sum-192) > 1e-6 || half > 1e-6 {
+		t.Errorf("PredictSum() = %v ± %v, want 192 ± 0", sum, half)
+	}
+}
+
+func TestFit_IntervalWidensWithHorizon(t *testing.T) {
+	values := make([]float64, 28)
+	for i := range values {
+		values[i] = 100 + float64(i%3)*10 // noisy but flat
+	}
+
+	m, err := Fit(monday, values)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	var days []time.Time
+	var prevHalf float64
+	for i := 0; i < 30; i++ {
+		days = append(days, monday.AddDate(0, 0, 28+i))
+		_, half := m.PredictSum(days, 1.28)
+		if half < prevHalf {
+			t.Fatalf("interval shrank at horizon %d: %v < %v", i+1, half, prevHalf)
+		}
+		prevHalf = half
+	}
+	if prevHalf <= 0 {
+		t.Error("noisy history should give a non-zero interval")
+	}
+}
+
+func TestFit_ShortHistory(t *testing.T) {
+	// Under two weeks falls back to a trend-only fit
+	m, err := Fit(monday, []float64{10, 12, 14, 16, 18})
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if m.seasonal || math.Abs(m.Slope()-2) > 1e-9 {
+		t.Errorf("expected trend-only fit with slope 2, got seasonal=%v slope=%v", m.seasonal, m.Slope())
+	}
+
+	if _, err := Fit(monday, []float64{10, 12}); err == nil {
+		t.Error("Fit() should reject two data points")
+	}
+}
+
+func TestPredict_NeverNegative(t *testing.T) {
+	m, err := Fit(monday, []float64{50, 40, 30, 20, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Predict(monday.AddDate(0, 0, 30)); got != 0 {
+		t.Errorf("Predict() = %v, want 0 for a declining trend", got)
+	}
+}
+
+func TestZScore(t *testing.T) {
+	tests := []struct {
+		confidence float64
+		want       float64
+	}{
+		{0.8, 1.2816},
+		{0.95, 1.9600},
+	}
+	for _, tt := range tests {
+		got, err := ZScore(tt.confidence)
+		if err != nil || math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("ZScore(%v) = %v, %v; want %v", tt.confidence, got, err, tt.want)
+		}
+	}
+
+	if _, err := ZScore(1.5); err == nil {
+		t.Error("ZScore() should reject confidence >= 1")
+	}
+}
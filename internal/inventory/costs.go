@@ -0,0 +1,104 @@
+package inventory
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Billing states of resources that only one side knows about
+const (
+	BillingOnly   = "billing-only"   // billed in the window but not found, usually deleted
+	InventoryOnly = "inventory-only" // found but not billed in the window
+)
+
+// Cost is the billed cost of a resource in the current and prior periods
+type Cost struct {
+	Metric  string
+	Current float64
+	Prior   float64
+	Delta   float64
+}
+
+// AttachCosts matches billed costs, keyed by the resource IDs or ARNs Cost
+// Explorer reports, to resources by ID or ARN. Billed resources that were
+// not found are added as BillingOnly, resources without cost are marked
+// InventoryOnly, and the result is ordered by delta, largest increase first.
+func AttachCosts(resources []Resource, costs map[string]Cost) []Resource {
+	index := make(map[string]int, 2*len(resources))
+	for i := len(resources) - 1; i >= 0; i-- {
+		index[resources[i].ID] = i
+		if resources[i].ARN != "" {
+			index[resources[i].ARN] = i
+		}
+	}
+
+	// Visit billing IDs in order so billing-only resources are stable
+	keys := make([]string, 0, len(costs))
+	for key := range costs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		c := costs[key]
+		if i, ok := index[key]; ok && resources[i].Cost == nil {
+			resources[i].Cost = &c
+			continue
+		}
+		r := billedResource(key)
+		r.Cost = &c
+		r.Billing = BillingOnly
+		resources = append(resources, r)
+	}
+
+	for i := range resources {
+		if resources[i].Cost == nil {
+			resources[i].Billing = InventoryOnly
+		}
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		ci, cj := resources[i].Cost, resources[j].Cost
+		if ci == nil || cj == nil {
+			return cj == nil && ci != nil
+		}
+		return ci.Delta > cj.Delta
+	})
+	return resources
+}
+
+// billedResource describes a resource known only by its billing ID, taking
+// what it can from the ID when it is an ARN
+func billedResource(id string) Resource {
+	if _, err := arn.Parse(id); err != nil {
+		return Resource{ID: id}
+	}
+	return resourceFromARN(id)
+}
+
+// resourceFromARN describes a resource by its ARN alone: the name after the
+// last separator as ID and the service and resource type as type, such as
+// dynamodb:table
+func resourceFromARN(s string) Resource {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return Resource{ARN: s, ID: s}
+	}
+
+	name, typ := parsed.Resource, parsed.Service
+	if i := strings.IndexAny(name, "/:"); i >= 0 {
+		typ += ":" + name[:i]
+	}
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return Resource{
+		ARN:     s,
+		ID:      name,
+		Type:    typ,
+		Region:  parsed.Region,
+		Account: parsed.AccountID,
+	}
+}
@@ -0,0 +1,52 @@
+package inventory
+
+import "testing"
+
+func TestAttachCosts(t *testing.T) {
+	resources := []Resource{
+		{ID: "i-idle", Type: "EC2 Instance"},
+		{ID: "i-111", Type: "EC2 Instance"},
+		{ARN: "arn:aws:rds:us-east-1:111111111111:db:orders", ID: "orders", Type: "RDS Instance"},
+	}
+	costs := map[string]Cost{
+		"i-111": {Current: 70, Prior: 20, Delta: 50},
+		"arn:aws:rds:us-east-1:111111111111:db:orders": {Current: 40, Prior: 30, Delta: 10},
+		"arn:aws:rds:us-east-1:111111111111:db:legacy": {Current: 0, Prior: 25, Delta: -25},
+	}
+
+	got := AttachCosts(resources, costs)
+
+	want := []struct {
+		id      string
+		billing string
+		delta   float64
+	}{
+		{"i-111", "", 50},
+		{"orders", "", 10},
+		{"legacy", BillingOnly, -25},
+		{"i-idle", InventoryOnly, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AttachCosts() returned %d resources, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		r := got[i]
+		if r.ID != w.id || r.Billing != w.billing {
+			t.Errorf("resource %d = %s (%q), want %s (%q)", i, r.ID, r.Billing, w.id, w.billing)
+			continue
+		}
+		if w.billing == InventoryOnly {
+			if r.Cost != nil {
+				t.Errorf("%s: expected no cost, got %+v", r.ID, r.Cost)
+			}
+		} else if r.Cost == nil || r.Cost.Delta != w.delta {
+			t.Errorf("%s: cost = %+v, want delta %.0f", r.ID, r.Cost, w.delta)
+		}
+	}
+
+	// Billing-only ARNs keep what they say about the resource
+	deleted := got[2]
+	if deleted.Region != "us-east-1" || deleted.Account != "111111111111" || deleted.ARN == "" {
+		t.Errorf("billing-only resource = %+v, want region and account from its ARN", deleted)
+	}
+}
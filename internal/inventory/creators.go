@@ -0,0 +1,229 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cttypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// EventHistory is how far back CloudTrail event history goes
+const EventHistory = 90 * 24 * time.Hour
+
+// cloudTrailInterval spaces LookupEvents calls to stay under CloudTrail's
+// limit of 2 requests per second per account and region
+const cloudTrailInterval = 500 * time.Millisecond
+
+// maxEventPages bounds how many pages of events are read per resource;
+// the creating event is the oldest, so busy resources need several
+const maxEventPages = 10
+
+// Creator is who created a resource, from its CloudTrail creation event
+type Creator struct {
+	ARN       string // principal that made the call
+	SourceIP  string // or the AWS service that made the call on its behalf
+	UserAgent string
+	EventName string
+	Time      time.Time
+	InWindow  bool // created within the current period
+}
+
+// creationEvents are the CloudTrail events creating each resource type.
+// Types found through the Tagging API accept any Create event.
+var creationEvents = map[string][]string{
+	TypeEC2Instance:           {"RunInstances"},
+	TypeEBSVolume:             {"CreateVolume"},
+	TypeEBSSnapshot:           {"CreateSnapshot", "CreateSnapshots", "CopySnapshot"},
+	TypeNATGateway:            {"CreateNatGateway"},
+	TypeElasticIP:             {"AllocateAddress"},
+	TypeApplicationLB:         {"CreateLoadBalancer"},
+	TypeNetworkLB:             {"CreateLoadBalancer"},
+	TypeGatewayLB:             {"CreateLoadBalancer"},
+	TypeClassicLB:             {"CreateLoadBalancer"},
+	TypeVPCEndpoint:           {"CreateVpcEndpoint"},
+	TypeTGWAttachment:         {"CreateTransitGatewayVpcAttachment", "CreateTransitGatewayPeeringAttachment"},
+	TypeVPNConnection:         {"CreateVpnConnection"},
+	"RDS Instance":            {"CreateDBInstance", "CreateDBInstanceReadReplica", "RestoreDBInstanceFromDBSnapshot", "RestoreDBInstanceToPointInTime"},
+	"Lambda Function":         {"CreateFunction", "CreateFunction20150331"},
+	"S3 Bucket":               {"CreateBucket"},
+	"CloudFront Distribution": {"CreateDistribution", "CreateDistributionWithTags"},
+	TypeECSCluster:            {"CreateCluster"},
+	TypeEKSCluster:            {"CreateCluster"},
+}
+
+// creationSources are the event sources of types whose creation events
+// share a name with another type's
+var creationSources = map[string]string{
+	TypeECSCluster: "ecs.amazonaws.com",
+	TypeEKSCluster: "eks.amazonaws.com",
+}
+
+// isCreation reports whether an event creates a resource of the type, and
+// whether the type can be looked up at all
+func isCreation(resourceType, eventName string) (created, known bool) {
+	if names, ok := creationEvents[resourceType]; ok {
+		for _, name := range names {
+			if name == eventName {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	if strings.Contains(resourceType, ":") {
+		return strings.HasPrefix(eventName, "Create"), true
+	}
+	return false, false
+}
+
+// CreatorFinder looks up who created resources in CloudTrail event history
+type CreatorFinder struct {
+	client   cloudtrail.LookupEventsAPIClient
+	interval time.Duration
+	now      func() time.Time
+	last     time.Time
+}
+
+// NewCreatorFinder creates a finder backed by a CloudTrail client in the
+// account and region of the resources
+func NewCreatorFinder(client cloudtrail.LookupEventsAPIClient) *CreatorFinder {
+	return &CreatorFinder{client: client, interval: cloudTrailInterval, now: time.Now}
+}
+
+// Annotate sets the creator of the first max resources (all when max is 0)
+// whose creation event is still in the event history, marking those
+// created at or after since. Resources created earlier keep no creator.
+// Failed lookups don't stop the others; their errors are returned together.
+func (f *CreatorFinder) Annotate(ctx context.Context, resources []Resource, since time.Time, max int) error {
+	var errs []error
+	looked := 0
+	for i := range resources {
+		r := &resources[i]
+		if _, known := isCreation(r.Type, ""); !known {
+			continue
+		}
+		if max > 0 && looked == max {
+			errs = append(errs, fmt.Errorf("looked up the creators of the first %d resources only", max))
+			break
+		}
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		looked++
+
+		creator, err := f.lookup(ctx, r.Type, r.ID)
+		if err == nil && creator == nil && r.ARN != "" && r.ARN != r.ID {
+			creator, err = f.lookup(ctx, r.Type, r.ARN)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to look up the creator of %s: %w", r.ID, err))
+			continue
+		}
+		if creator != nil {
+			creator.InWindow = !creator.Time.Before(since)
+			r.Creator = creator
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lookup finds the oldest creation event of a resource by name
+func (f *CreatorFinder) lookup(ctx context.Context, resourceType, name string) (*Creator, error) {
+	input := &cloudtrail.LookupEventsInput{
+		LookupAttributes: []cttypes.LookupAttribute{{
+			AttributeKey:   cttypes.LookupAttributeKeyResourceName,
+			AttributeValue: aws.String(name),
+		}},
+		StartTime: aws.Time(f.now().Add(-EventHistory)),
+	}
+
+	var oldest *cttypes.Event
+	paginator := cloudtrail.NewLookupEventsPaginator(f.client, input)
+	for page := 0; paginator.HasMorePages() && page < maxEventPages; page++ {
+		if err := f.wait(ctx); err != nil {
+			return nil, err
+		}
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, event := range output.Events {
+			if created, _ := isCreation(resourceType, aws.ToString(event.EventName)); !created {
+				continue
+			}
+			if source, ok := creationSources[resourceType]; ok && aws.ToString(event.EventSource) != source {
+				continue
+			}
+			if oldest == nil || aws.ToTime(event.EventTime).Before(aws.ToTime(oldest.EventTime)) {
+				oldest = &output.Events[i]
+			}
+		}
+	}
+
+	if oldest == nil {
+		return nil, nil
+	}
+	return parseCreator(oldest), nil
+}
+
+// wait spaces calls by the finder's interval
+func (f *CreatorFinder) wait(ctx context.Context) error {
+	next := f.last.Add(f.interval)
+	if d := next.Sub(f.now()); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	f.last = f.now()
+	return nil
+}
+
+// cloudTrailRecord holds the fields of a CloudTrail record naming the
+// caller
+type cloudTrailRecord struct {
+	UserIdentity struct {
+		ARN string `json:"arn"`
+	} `json:"userIdentity"`
+	SourceIPAddress string `json:"sourceIPAddress"`
+	UserAgent       string `json:"userAgent"`
+}
+
+// parseCreator describes the caller of an event, falling back to the
+// event's user name when the record can't be read
+func parseCreator(event *cttypes.Event) *Creator {
+	c := &Creator{
+		ARN:       aws.ToString(event.Username),
+		EventName: aws.ToString(event.EventName),
+		Time:      aws.ToTime(event.EventTime),
+	}
+
+	var record cloudTrailRecord
+	if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &record); err == nil {
+		if record.UserIdentity.ARN != "" {
+			c.ARN = record.UserIdentity.ARN
+		}
+		c.SourceIP = record.SourceIPAddress
+		c.UserAgent = record.UserAgent
+	}
+	return c
+}
+
+// Principal shortens a creator ARN to its resource part, such as
+// assumed-role/Admin/alice
+func (c *Creator) Principal() string {
+	parsed, err := arn.Parse(c.ARN)
+	if err != nil {
+		return c.ARN
+	}
+	return parsed.Resource
+}
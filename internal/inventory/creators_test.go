@@ -0,0 +1,194 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cttypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// fakeCloudTrail serves events by resource name, one event per page
+type fakeCloudTrail struct {
+	events map[string][]cttypes.Event
+	errs   map[string]error
+	calls  int
+}
+
+func (f *fakeCloudTrail) LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error) {
+	f.calls++
+	name := aws.ToString(params.LookupAttributes[0].AttributeValue)
+	if err := f.errs[name]; err != nil {
+		return nil, err
+	}
+	events := f.events[name]
+
+	page := 0
+	if params.NextToken != nil {
+		page = len(aws.ToString(params.NextToken))
+	}
+	if page >= len(events) {
+		return &cloudtrail.LookupEventsOutput{}, nil
+	}
+	output := &cloudtrail.LookupEventsOutput{Events: events[page : page+1]}
+	if page+1 < len(events) {
+		output.NextToken = aws.String(strings.Repeat("x", page+1))
+	}
+	return output, nil
+}
+
+func event(name string, at time.Time, record string) cttypes.Event {
+	return cttypes.Event{
+		EventName:       aws.String(name),
+		EventTime:       aws.Time(at),
+		Username:        aws.String("fallback"),
+		CloudTrailEvent: aws.String(record),
+	}
+}
+
+func newFakeCreatorFinder(client *fakeCloudTrail, now time.Time) *CreatorFinder {
+	f := NewCreatorFinder(client)
+	f.interval = 0
+	f.now = func() time.Time { return now }
+	return f
+}
+
+func TestCreatorFinder_Annotate(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-48 * time.Hour)
+	alice := `{"userIdentity":{"arn":"arn:aws:iam::111111111111:user/alice"},"sourceIPAddress":"203.0.113.7","userAgent":"aws-cli/2.15"}`
+	ci := `{"userIdentity":{"arn":"arn:aws:sts::111111111111:assumed-role/Deploy/ci"},"sourceIPAddress":"198.51.100.1","userAgent":"terraform"}`
+
+	client := &fakeCloudTrail{events: map[string][]cttypes.Event{
+		// Newest first, as CloudTrail returns them
+		"i-new": {
+			event("StopInstances", now.Add(-time.Hour), alice),
+			event("RunInstances", now.Add(-24*time.Hour), ci),
+		},
+		"i-old": {
+			event("RunInstances", now.Add(-30*24*time.Hour), alice),
+		},
+		"arn:aws:lambda:us-east-1:111111111111:function:api": {
+			event("CreateFunction20150331", now.Add(-time.Hour), "not json"),
+		},
+		"table": {
+			event("CreateTable", now.Add(-time.Hour), alice),
+		},
+	}}
+	resources := []Resource{
+		{ID: "i-new", Type: TypeEC2Instance},
+		{ID: "i-old", Type: TypeEC2Instance},
+		{ID: "i-untracked", Type: TypeEC2Instance},
+		{ID: "api", ARN: "arn:aws:lambda:us-east-1:111111111111:function:api", Type: "Lambda Function"},
+		{ID: "table", Type: "dynamodb:table"},
+		{ID: "eni-1", Type: TypePublicIPv4},
+	}
+
+	f := newFakeCreatorFinder(client, now)
+	if err := f.Annotate(context.Background(), resources, since, 0); err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+
+	want := map[string]*Creator{
+		"i-new": {ARN: "arn:aws:sts::111111111111:assumed-role/Deploy/ci", SourceIP: "198.51.100.1", UserAgent: "terraform",
+			EventName: "RunInstances", Time: now.Add(-24 * time.Hour), InWindow: true},
+		"i-old": {ARN: "arn:aws:iam::111111111111:user/alice", SourceIP: "203.0.113.7", UserAgent: "aws-cli/2.15",
+			EventName: "RunInstances", Time: now.Add(-30 * 24 * time.Hour)},
+		"api": {ARN: "fallback", EventName: "CreateFunction20150331", Time: now.Add(-time.Hour), InWindow: true},
+		"table": {ARN: "arn:aws:iam::111111111111:user/alice", SourceIP: "203.0.113.7", UserAgent: "aws-cli/2.15",
+			EventName: "CreateTable", Time: now.Add(-time.Hour), InWindow: true},
+	}
+	for _, r := range resources {
+		w := want[r.ID]
+		switch {
+		case w == nil && r.Creator != nil:
+			t.Errorf("%s creator = %+v, want none", r.ID, r.Creator)
+		case w != nil && (r.Creator == nil || *r.Creator != *w):
+			t.Errorf("%s creator = %+v, want %+v", r.ID, r.Creator, w)
+		}
+	}
+
+	// Two pages for i-new, the ARN fallback for api, and none for the
+	// public IPv4 address
+	if client.calls != 7 {
+		t.Errorf("LookupEvents calls = %d, want 7", client.calls)
+	}
+}
+
+func TestCreatorFinder_AnnotateLimits(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	resources := []Resource{
+		{ID: "vol-1", Type: TypeEBSVolume},
+		{ID: "vol-2", Type: TypeEBSVolume},
+	}
+
+	f := newFakeCreatorFinder(&fakeCloudTrail{}, now)
+	if err := f.Annotate(context.Background(), resources, now, 1); err == nil {
+		t.Error("Annotate() error = nil, want the lookup limit")
+	}
+
+	// A failed lookup doesn't stop the others
+	client := &fakeCloudTrail{
+		events: map[string][]cttypes.Event{"vol-2": {event("CreateVolume", now, "{}")}},
+		errs:   map[string]error{"vol-1": errors.New("ThrottlingException")},
+	}
+	f = newFakeCreatorFinder(client, now)
+	err := f.Annotate(context.Background(), resources, now, 0)
+	if err == nil || !strings.Contains(err.Error(), "vol-1") {
+		t.Errorf("Annotate() error = %v, want the lookup error for vol-1", err)
+	}
+	if resources[0].Creator != nil || resources[1].Creator == nil {
+		t.Errorf("creators = %+v, %+v, want only vol-2's", resources[0].Creator, resources[1].Creator)
+	}
+}
+
+func TestCreatorFinder_AnnotateClusters(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	alice := `{"userIdentity":{"arn":"arn:aws:iam::111111111111:user/alice"}}`
+	ci := `{"userIdentity":{"arn":"arn:aws:sts::111111111111:assumed-role/Deploy/ci"}}`
+
+	// ECS and EKS both create clusters with CreateCluster
+	ecsEvent := event("CreateCluster", now.Add(-time.Hour), alice)
+	ecsEvent.EventSource = aws.String("ecs.amazonaws.com")
+	eksEvent := event("CreateCluster", now.Add(-24*time.Hour), ci)
+	eksEvent.EventSource = aws.String("eks.amazonaws.com")
+	client := &fakeCloudTrail{events: map[string][]cttypes.Event{"prod": {ecsEvent, eksEvent}}}
+	resources := []Resource{
+		{ID: "prod", Type: TypeECSCluster},
+		{ID: "prod", Type: TypeEKSCluster},
+	}
+
+	f := newFakeCreatorFinder(client, now)
+	if err := f.Annotate(context.Background(), resources, now, 0); err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+
+	want := []string{"arn:aws:iam::111111111111:user/alice", "arn:aws:sts::111111111111:assumed-role/Deploy/ci"}
+	for i, r := range resources {
+		if r.Creator == nil || r.Creator.ARN != want[i] {
+			t.Errorf("%s creator = %+v, want %s", r.Type, r.Creator, want[i])
+		}
+	}
+}
+
+func TestCreatorPrincipal(t *testing.T) {
+	tests := []struct {
+		arn  string
+		want string
+	}{
+		{"arn:aws:sts::111111111111:assumed-role/Admin/alice", "assumed-role/Admin/alice"},
+		{"arn:aws:iam::111111111111:root", "root"},
+		{"AutoScaling", "AutoScaling"},
+	}
+
+	for _, tt := range tests {
+		c := &Creator{ARN: tt.arn}
+		if got := c.Principal(); got != tt.want {
+			t.Errorf("Principal(%q) = %q, want %q", tt.arn, got, tt.want)
+		}
+	}
+}
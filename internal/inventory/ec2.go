@@ -0,0 +1,696 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+)
+
+// EC2API is the subset of the EC2 client used by EC2Provider
+type EC2API interface {
+	ec2.DescribeInstancesAPIClient
+	ec2.DescribeVolumesAPIClient
+	ec2.DescribeSnapshotsAPIClient
+	ec2.DescribeImagesAPIClient
+	ec2.DescribeNatGatewaysAPIClient
+	ec2.DescribeNetworkInterfacesAPIClient
+	ec2.DescribeVpcEndpointsAPIClient
+	ec2.DescribeTransitGatewayAttachmentsAPIClient
+	ec2.DescribeRouteTablesAPIClient
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+	DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error)
+}
+
+// ELBv2API is the subset of the Elastic Load Balancing v2 client used by
+// EC2Provider for application, network and gateway load balancers
+type ELBv2API interface {
+	elbv2.DescribeLoadBalancersAPIClient
+	elbv2.DescribeTargetGroupsAPIClient
+	DescribeTargetHealth(ctx context.Context, params *elbv2.DescribeTargetHealthInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTargetHealthOutput, error)
+	DescribeTags(ctx context.Context, params *elbv2.DescribeTagsInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTagsOutput, error)
+}
+
+// ELBAPI is the subset of the classic Elastic Load Balancing client used by
+// EC2Provider
+type ELBAPI interface {
+	elb.DescribeLoadBalancersAPIClient
+	DescribeTags(ctx context.Context, params *elb.DescribeTagsInput, optFns ...func(*elb.Options)) (*elb.DescribeTagsOutput, error)
+}
+
+// Resource types listed by EC2Provider
+const (
+	TypeEC2Instance   = "EC2 Instance"
+	TypeEBSVolume     = "EBS Volume"
+	TypeEBSSnapshot   = "EBS Snapshot"
+	TypeNATGateway    = "NAT Gateway"
+	TypeElasticIP     = "Elastic IP"
+	TypePublicIPv4    = "Public IPv4 Address"
+	TypeApplicationLB = "Application Load Balancer"
+	TypeNetworkLB     = "Network Load Balancer"
+	TypeGatewayLB     = "Gateway Load Balancer"
+	TypeClassicLB     = "Classic Load Balancer"
+	TypeVPCEndpoint   = "VPC Endpoint"
+	TypeTGWAttachment = "Transit Gateway Attachment"
+	TypeVPNConnection = "VPN Connection"
+)
+
+// elbTagBatch is the most load balancers DescribeTags accepts at once
+const elbTagBatch = 20
+
+// EC2Provider lists the resources billed under EC2, Elastic Load Balancing
+// and VPC: instances, EBS volumes and snapshots, NAT gateways, public IPv4
+// addresses, load balancers, VPC endpoints and Transit Gateway attachments
+type EC2Provider struct {
+	client  EC2API
+	elbv2   ELBv2API
+	classic ELBAPI
+}
+
+// NewEC2Provider creates a provider backed by EC2 and Elastic Load
+// Balancing clients
+func NewEC2Provider(client EC2API, lbs ELBv2API, classic ELBAPI) *EC2Provider {
+	return &EC2Provider{client: client, elbv2: lbs, classic: classic}
+}
+
+// Services returns the services EC2Provider lists
+func (p *EC2Provider) Services() []string {
+	return []string{"ec2", "elb", "vpc"}
+}
+
+// ec2Lister lists one kind of resource; name labels its errors
+type ec2Lister struct {
+	name string
+	list func(p *EC2Provider, ctx context.Context, region string) ([]Resource, error)
+}
+
+var (
+	listInstances      = ec2Lister{"EC2 instances", (*EC2Provider).listInstances}
+	listVolumes        = ec2Lister{"EBS volumes", (*EC2Provider).listVolumes}
+	listSnapshots      = ec2Lister{"EBS snapshots", (*EC2Provider).listSnapshots}
+	listNATGateways    = ec2Lister{"NAT gateways", (*EC2Provider).listNATGateways}
+	listElasticIPs     = ec2Lister{"Elastic IPs", (*EC2Provider).listElasticIPs}
+	listPublicIPv4     = ec2Lister{"public IPv4 addresses", (*EC2Provider).listPublicIPv4}
+	listLoadBalancers  = ec2Lister{"load balancers", (*EC2Provider).listLoadBalancers}
+	listClassicLBs     = ec2Lister{"classic load balancers", (*EC2Provider).listClassicLoadBalancers}
+	listVPCEndpoints   = ec2Lister{"VPC endpoints", (*EC2Provider).listVPCEndpoints}
+	listTGWAttachments = ec2Lister{"Transit Gateway attachments", (*EC2Provider).listTGWAttachments}
+	listVPNConnections = ec2Lister{"VPN connections", (*EC2Provider).listVPNConnections}
+)
+
+// ec2Listers are the kinds of resource searched for each service. EC2
+// covers everything "EC2 - Other" bills for, including load balancers and
+// VPC attachments billed under their own services, which the ec2 service
+// lists in AlsoBilledAs so their costs are matched.
+var ec2Listers = map[string][]ec2Lister{
+	"ec2": {
+		listInstances, listVolumes, listSnapshots, listNATGateways, listElasticIPs,
+		listPublicIPv4, listLoadBalancers, listClassicLBs, listVPCEndpoints, listTGWAttachments,
+	},
+	"elb": {listLoadBalancers, listClassicLBs},
+	"vpc": {listElasticIPs, listPublicIPv4, listVPCEndpoints, listTGWAttachments, listVPNConnections},
+}
+
+// ListResources lists every kind of resource of the service. Kinds that
+// can't be listed don't stop the others; their errors are returned
+// together with the resources that were found.
+func (p *EC2Provider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
+	var (
+		resources []Resource
+		errs      []error
+	)
+	for _, l := range ec2Listers[service] {
+		found, err := l.list(p, ctx, region)
+		resources = append(resources, found...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to describe %s: %w", l.name, err))
+		}
+	}
+	return resources, errors.Join(errs...)
+}
+
+func (p *EC2Provider) listInstances(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := ec2.NewDescribeInstancesPaginator(p.client, &ec2.DescribeInstancesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				attrs := map[string]string{"instance_type": string(instance.InstanceType)}
+				if instance.State != nil {
+					attrs["state"] = string(instance.State.Name)
+				}
+				if instance.InstanceLifecycle != "" {
+					attrs["lifecycle"] = string(instance.InstanceLifecycle)
+				}
+				resources = append(resources, Resource{
+					ARN:        ec2ARN(region, "instance", aws.ToString(instance.InstanceId)),
+					ID:         aws.ToString(instance.InstanceId),
+					Type:       TypeEC2Instance,
+					Tags:       extractEC2Tags(instance.Tags),
+					Region:     region,
+					Attributes: attrs,
+				})
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+func (p *EC2Provider) listVolumes(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := ec2.NewDescribeVolumesPaginator(p.client, &ec2.DescribeVolumesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, volume := range output.Volumes {
+			attrs := map[string]string{
+				"size_gib":    strconv.Itoa(int(aws.ToInt32(volume.Size))),
+				"volume_type": string(volume.VolumeType),
+				"state":       string(volume.State),
+			}
+			if len(volume.Attachments) > 0 {
+				attrs["instance_id"] = aws.ToString(volume.Attachments[0].InstanceId)
+			}
+			resources = append(resources, Resource{
+				ARN:        ec2ARN(region, "volume", aws.ToString(volume.VolumeId)),
+				ID:         aws.ToString(volume.VolumeId),
+				Type:       TypeEBSVolume,
+				Tags:       extractEC2Tags(volume.Tags),
+				Region:     region,
+				Attributes: attrs,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func (p *EC2Provider) listSnapshots(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	// Only the account's own snapshots are billed to it
+	paginator := ec2.NewDescribeSnapshotsPaginator(p.client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, snapshot := range output.Snapshots {
+			attrs := map[string]string{
+				"size_gib":  strconv.Itoa(int(aws.ToInt32(snapshot.VolumeSize))),
+				"state":     string(snapshot.State),
+				"volume_id": aws.ToString(snapshot.VolumeId),
+			}
+			if snapshot.StorageTier != "" {
+				attrs["storage_tier"] = string(snapshot.StorageTier)
+			}
+			if snapshot.StartTime != nil {
+				attrs["started"] = snapshot.StartTime.Format("2006-01-02")
+			}
+			resources = append(resources, Resource{
+				ARN:        ec2ARN(region, "snapshot", aws.ToString(snapshot.SnapshotId)),
+				ID:         aws.ToString(snapshot.SnapshotId),
+				Type:       TypeEBSSnapshot,
+				Tags:       extractEC2Tags(snapshot.Tags),
+				Region:     region,
+				Attributes: attrs,
+			})
+		}
+	}
+
+	if err := p.markAMISnapshots(ctx, resources); err != nil {
+		return resources, fmt.Errorf("failed to describe AMIs: %w", err)
+	}
+	return resources, nil
+}
+
+// markAMISnapshots sets the image_id attribute of each snapshot backing
+// one of the account's AMIs
+func (p *EC2Provider) markAMISnapshots(ctx context.Context, snapshots []Resource) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	images := make(map[string]string)
+	paginator := ec2.NewDescribeImagesPaginator(p.client, &ec2.DescribeImagesInput{
+		Owners: []string{"self"},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, image := range output.Images {
+			for _, mapping := range image.BlockDeviceMappings {
+				if mapping.Ebs != nil && mapping.Ebs.SnapshotId != nil {
+					images[aws.ToString(mapping.Ebs.SnapshotId)] = aws.ToString(image.ImageId)
+				}
+			}
+		}
+	}
+
+	for i := range snapshots {
+		if id, ok := images[snapshots[i].ID]; ok {
+			snapshots[i].Attributes["image_id"] = id
+		}
+	}
+	return nil
+}
+
+func (p *EC2Provider) listNATGateways(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := ec2.NewDescribeNatGatewaysPaginator(p.client, &ec2.DescribeNatGatewaysInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, nat := range output.NatGateways {
+			resources = append(resources, Resource{
+				ARN:    ec2ARN(region, "natgateway", aws.ToString(nat.NatGatewayId)),
+				ID:     aws.ToString(nat.NatGatewayId),
+				Type:   TypeNATGateway,
+				Tags:   extractEC2Tags(nat.Tags),
+				Region: region,
+				Attributes: map[string]string{
+					"state":        string(nat.State),
+					"connectivity": string(nat.ConnectivityType),
+					"vpc_id":       aws.ToString(nat.VpcId),
+				},
+			})
+		}
+	}
+
+	if err := p.countNATRoutes(ctx, resources); err != nil {
+		return resources, fmt.Errorf("failed to count routes: %w", err)
+	}
+	return resources, nil
+}
+
+// countNATRoutes sets the routes attribute of each NAT gateway to the
+// number of route tables sending traffic through it
+func (p *EC2Provider) countNATRoutes(ctx context.Context, nats []Resource) error {
+	if len(nats) == 0 {
+		return nil
+	}
+
+	routes := make(map[string]int)
+	ids := make([]string, len(nats))
+	for i, nat := range nats {
+		ids[i] = nat.ID
+	}
+
+	paginator := ec2.NewDescribeRouteTablesPaginator(p.client, &ec2.DescribeRouteTablesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("route.nat-gateway-id"), Values: ids}},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range output.RouteTables {
+			seen := make(map[string]bool)
+			for _, route := range table.Routes {
+				id := aws.ToString(route.NatGatewayId)
+				if id != "" && !seen[id] {
+					seen[id] = true
+					routes[id]++
+				}
+			}
+		}
+	}
+
+	for i := range nats {
+		nats[i].Attributes["routes"] = strconv.Itoa(routes[nats[i].ID])
+	}
+	return nil
+}
+
+// listElasticIPs lists every Elastic IP; DescribeAddresses isn't paginated
+func (p *EC2Provider) listElasticIPs(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	output, err := p.client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, addr := range output.Addresses {
+		attrs := map[string]string{
+			"public_ip": aws.ToString(addr.PublicIp),
+			"state":     "unassociated",
+		}
+		if addr.AssociationId != nil {
+			attrs["state"] = "associated"
+		}
+		if addr.InstanceId != nil {
+			attrs["instance_id"] = aws.ToString(addr.InstanceId)
+		}
+		if addr.NetworkInterfaceId != nil {
+			attrs["network_interface_id"] = aws.ToString(addr.NetworkInterfaceId)
+		}
+		resources = append(resources, Resource{
+			ARN:        ec2ARN(region, "elastic-ip", aws.ToString(addr.AllocationId)),
+			ID:         aws.ToString(addr.AllocationId),
+			Type:       TypeElasticIP,
+			Tags:       extractEC2Tags(addr.Tags),
+			Region:     region,
+			Attributes: attrs,
+		})
+	}
+
+	return resources, nil
+}
+
+// listPublicIPv4 lists the public IPv4 addresses AWS assigned to network
+// interfaces. Elastic IPs are listed by listElasticIPs instead.
+func (p *EC2Provider) listPublicIPv4(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(p.client, &ec2.DescribeNetworkInterfacesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, eni := range output.NetworkInterfaces {
+			if eni.Association == nil || eni.Association.PublicIp == nil || eni.Association.AllocationId != nil {
+				continue
+			}
+			attrs := map[string]string{
+				"network_interface_id": aws.ToString(eni.NetworkInterfaceId),
+				"interface_type":       string(eni.InterfaceType),
+			}
+			if eni.Attachment != nil && eni.Attachment.InstanceId != nil {
+				attrs["instance_id"] = aws.ToString(eni.Attachment.InstanceId)
+			}
+			resources = append(resources, Resource{
+				ID:         aws.ToString(eni.Association.PublicIp),
+				Type:       TypePublicIPv4,
+				Tags:       extractEC2Tags(eni.TagSet),
+				Region:     region,
+				Attributes: attrs,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// listLoadBalancers lists application, network and gateway load balancers.
+// Their tags are fetched by EnrichTags.
+func (p *EC2Provider) listLoadBalancers(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := elbv2.NewDescribeLoadBalancersPaginator(p.elbv2, &elbv2.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, lb := range output.LoadBalancers {
+			typ := TypeApplicationLB
+			switch lb.Type {
+			case "network":
+				typ = TypeNetworkLB
+			case "gateway":
+				typ = TypeGatewayLB
+			}
+			attrs := map[string]string{
+				"scheme": string(lb.Scheme),
+				"vpc_id": aws.ToString(lb.VpcId),
+			}
+			if lb.State != nil {
+				attrs["state"] = string(lb.State.Code)
+			}
+			resources = append(resources, Resource{
+				ARN:        aws.ToString(lb.LoadBalancerArn),
+				ID:         aws.ToString(lb.LoadBalancerName),
+				Type:       typ,
+				Tags:       make(map[string]string),
+				Region:     region,
+				Attributes: attrs,
+			})
+		}
+	}
+
+	for i := range resources {
+		targets, err := p.countTargets(ctx, resources[i].ARN)
+		if err != nil {
+			return resources, fmt.Errorf("failed to count targets of %s: %w", resources[i].ID, err)
+		}
+		resources[i].Attributes["targets"] = strconv.Itoa(targets)
+	}
+	return resources, nil
+}
+
+// countTargets counts the targets registered in the target groups of a
+// load balancer
+func (p *EC2Provider) countTargets(ctx context.Context, lbARN string) (int, error) {
+	targets := 0
+
+	paginator := elbv2.NewDescribeTargetGroupsPaginator(p.elbv2, &elbv2.DescribeTargetGroupsInput{
+		LoadBalancerArn: aws.String(lbARN),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, group := range output.TargetGroups {
+			health, err := p.elbv2.DescribeTargetHealth(ctx, &elbv2.DescribeTargetHealthInput{
+				TargetGroupArn: group.TargetGroupArn,
+			})
+			if err != nil {
+				return 0, err
+			}
+			targets += len(health.TargetHealthDescriptions)
+		}
+	}
+
+	return targets, nil
+}
+
+// listClassicLoadBalancers lists classic load balancers. Their tags are
+// fetched by EnrichTags.
+func (p *EC2Provider) listClassicLoadBalancers(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := elb.NewDescribeLoadBalancersPaginator(p.classic, &elb.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, lb := range output.LoadBalancerDescriptions {
+			resources = append(resources, Resource{
+				ID:     aws.ToString(lb.LoadBalancerName),
+				Type:   TypeClassicLB,
+				Tags:   make(map[string]string),
+				Region: region,
+				Attributes: map[string]string{
+					"scheme":    aws.ToString(lb.Scheme),
+					"instances": strconv.Itoa(len(lb.Instances)),
+				},
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func (p *EC2Provider) listVPCEndpoints(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := ec2.NewDescribeVpcEndpointsPaginator(p.client, &ec2.DescribeVpcEndpointsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, endpoint := range output.VpcEndpoints {
+			resources = append(resources, Resource{
+				ARN:    ec2ARN(region, "vpc-endpoint", aws.ToString(endpoint.VpcEndpointId)),
+				ID:     aws.ToString(endpoint.VpcEndpointId),
+				Type:   TypeVPCEndpoint,
+				Tags:   extractEC2Tags(endpoint.Tags),
+				Region: region,
+				Attributes: map[string]string{
+					"endpoint_type": string(endpoint.VpcEndpointType),
+					"service_name":  aws.ToString(endpoint.ServiceName),
+					"state":         string(endpoint.State),
+					"vpc_id":        aws.ToString(endpoint.VpcId),
+				},
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+func (p *EC2Provider) listTGWAttachments(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	paginator := ec2.NewDescribeTransitGatewayAttachmentsPaginator(p.client, &ec2.DescribeTransitGatewayAttachmentsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return resources, err
+		}
+
+		for _, attachment := range output.TransitGatewayAttachments {
+			resources = append(resources, Resource{
+				ARN:    ec2ARN(region, "transit-gateway-attachment", aws.ToString(attachment.TransitGatewayAttachmentId)),
+				ID:     aws.ToString(attachment.TransitGatewayAttachmentId),
+				Type:   TypeTGWAttachment,
+				Tags:   extractEC2Tags(attachment.Tags),
+				Region: region,
+				Attributes: map[string]string{
+					"transit_gateway_id": aws.ToString(attachment.TransitGatewayId),
+					"resource_type":      string(attachment.ResourceType),
+					"resource_id":        aws.ToString(attachment.ResourceId),
+					"state":              string(attachment.State),
+				},
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// listVPNConnections lists every VPN connection; DescribeVpnConnections
+// isn't paginated
+func (p *EC2Provider) listVPNConnections(ctx context.Context, region string) ([]Resource, error) {
+	var resources []Resource
+
+	output, err := p.client.DescribeVpnConnections(ctx, &ec2.DescribeVpnConnectionsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vpn := range output.VpnConnections {
+		resources = append(resources, Resource{
+			ARN:    ec2ARN(region, "vpn-connection", aws.ToString(vpn.VpnConnectionId)),
+			ID:     aws.ToString(vpn.VpnConnectionId),
+			Type:   TypeVPNConnection,
+			Tags:   extractEC2Tags(vpn.Tags),
+			Region: region,
+			Attributes: map[string]string{
+				"state": string(vpn.State),
+			},
+		})
+	}
+
+	return resources, nil
+}
+
+// EnrichTags fetches the tags of load balancers, in batches of 20; the
+// other resources are listed with their tags
+func (p *EC2Provider) EnrichTags(ctx context.Context, resources []Resource) error {
+	byARN := make(map[string]int)
+	byName := make(map[string]int)
+	for i, r := range resources {
+		switch r.Type {
+		case TypeApplicationLB, TypeNetworkLB, TypeGatewayLB:
+			byARN[r.ARN] = i
+		case TypeClassicLB:
+			byName[r.ID] = i
+		}
+	}
+
+	var errs []error
+	for _, batch := range batches(byARN) {
+		output, err := p.elbv2.DescribeTags(ctx, &elbv2.DescribeTagsInput{ResourceArns: batch})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, desc := range output.TagDescriptions {
+			i, ok := byARN[aws.ToString(desc.ResourceArn)]
+			if !ok {
+				continue
+			}
+			for _, tag := range desc.Tags {
+				resources[i].Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+	}
+	for _, batch := range batches(byName) {
+		output, err := p.classic.DescribeTags(ctx, &elb.DescribeTagsInput{LoadBalancerNames: batch})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, desc := range output.TagDescriptions {
+			i, ok := byName[aws.ToString(desc.LoadBalancerName)]
+			if !ok {
+				continue
+			}
+			for _, tag := range desc.Tags {
+				resources[i].Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// batches splits the keys of m into sorted groups DescribeTags accepts
+func batches(m map[string]int) [][]string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out [][]string
+	for len(keys) > 0 {
+		n := min(len(keys), elbTagBatch)
+		out = append(out, keys[:n])
+		keys = keys[n:]
+	}
+	return out
+}
+
+// ec2ARN builds the ARN of an EC2 resource. The account is left out, as
+// the describe calls don't all return it.
+func ec2ARN(region, resourceType, id string) string {
+	return fmt.Sprintf("arn:aws:ec2:%s::%s/%s", region, resourceType, id)
+}
+
+func extractEC2Tags(tags []ec2types.Tag) map[string]string {
+	result := make(map[string]string)
+	for _, tag := range tags {
+		result[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return result
+}
@@ -0,0 +1,406 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+// fakeEC2 answers each describe call with its output, or an error when the
+// output is nil. Snapshots are served in two pages.
+type fakeEC2 struct {
+	instances  *ec2.DescribeInstancesOutput
+	volumes    *ec2.DescribeVolumesOutput
+	snapshots  [2]*ec2.DescribeSnapshotsOutput
+	images     *ec2.DescribeImagesOutput
+	nats       *ec2.DescribeNatGatewaysOutput
+	addresses  *ec2.DescribeAddressesOutput
+	interfaces *ec2.DescribeNetworkInterfacesOutput
+	endpoints  *ec2.DescribeVpcEndpointsOutput
+	tgws       *ec2.DescribeTransitGatewayAttachmentsOutput
+	vpns       *ec2.DescribeVpnConnectionsOutput
+	routes     *ec2.DescribeRouteTablesOutput
+	owners     []string
+}
+
+var errUnauthorized = errors.New("UnauthorizedOperation")
+
+func orErr[T any](out *T) (*T, error) {
+	if out == nil {
+		return nil, errUnauthorized
+	}
+	return out, nil
+}
+
+func (f *fakeEC2) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return orErr(f.instances)
+}
+
+func (f *fakeEC2) DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	return orErr(f.volumes)
+}
+
+func (f *fakeEC2) DescribeSnapshots(ctx context.Context, params *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	f.owners = params.OwnerIds
+	if params.NextToken != nil {
+		return orErr(f.snapshots[1])
+	}
+	return orErr(f.snapshots[0])
+}
+
+func (f *fakeEC2) DescribeImages(ctx context.Context, params *ec2.DescribeImagesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	return orErr(f.images)
+}
+
+func (f *fakeEC2) DescribeNatGateways(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+	return orErr(f.nats)
+}
+
+func (f *fakeEC2) DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return orErr(f.addresses)
+}
+
+func (f *fakeEC2) DescribeNetworkInterfaces(ctx context.Context, params *ec2.DescribeNetworkInterfacesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	return orErr(f.interfaces)
+}
+
+func (f *fakeEC2) DescribeVpcEndpoints(ctx context.Context, params *ec2.DescribeVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error) {
+	return orErr(f.endpoints)
+}
+
+func (f *fakeEC2) DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	return orErr(f.tgws)
+}
+
+func (f *fakeEC2) DescribeVpnConnections(ctx context.Context, params *ec2.DescribeVpnConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpnConnectionsOutput, error) {
+	return orErr(f.vpns)
+}
+
+func (f *fakeEC2) DescribeRouteTables(ctx context.Context, params *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	return orErr(f.routes)
+}
+
+type fakeELBv2 struct {
+	lbs     *elbv2.DescribeLoadBalancersOutput
+	tags    map[string][]elbv2types.Tag
+	targets map[string]int // by load balancer ARN, one target group each
+	batches [][]string
+}
+
+func (f *fakeELBv2) DescribeTargetGroups(ctx context.Context, params *elbv2.DescribeTargetGroupsInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTargetGroupsOutput, error) {
+	return &elbv2.DescribeTargetGroupsOutput{
+		TargetGroups: []elbv2types.TargetGroup{{TargetGroupArn: params.LoadBalancerArn}},
+	}, nil
+}
+
+func (f *fakeELBv2) DescribeTargetHealth(ctx context.Context, params *elbv2.DescribeTargetHealthInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTargetHealthOutput, error) {
+	out := &elbv2.DescribeTargetHealthOutput{}
+	for i := 0; i < f.targets[aws.ToString(params.TargetGroupArn)]; i++ {
+		out.TargetHealthDescriptions = append(out.TargetHealthDescriptions, elbv2types.TargetHealthDescription{})
+	}
+	return out, nil
+}
+
+func (f *fakeELBv2) DescribeLoadBalancers(ctx context.Context, params *elbv2.DescribeLoadBalancersInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeLoadBalancersOutput, error) {
+	return orErr(f.lbs)
+}
+
+func (f *fakeELBv2) DescribeTags(ctx context.Context, params *elbv2.DescribeTagsInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTagsOutput, error) {
+	f.batches = append(f.batches, params.ResourceArns)
+	out := &elbv2.DescribeTagsOutput{}
+	for _, arn := range params.ResourceArns {
+		out.TagDescriptions = append(out.TagDescriptions, elbv2types.TagDescription{ResourceArn: aws.String(arn), Tags: f.tags[arn]})
+	}
+	return out, nil
+}
+
+type fakeELB struct {
+	lbs  *elb.DescribeLoadBalancersOutput
+	tags map[string][]elbtypes.Tag
+}
+
+func (f *fakeELB) DescribeLoadBalancers(ctx context.Context, params *elb.DescribeLoadBalancersInput, optFns ...func(*elb.Options)) (*elb.DescribeLoadBalancersOutput, error) {
+	return orErr(f.lbs)
+}
+
+func (f *fakeELB) DescribeTags(ctx context.Context, params *elb.DescribeTagsInput, optFns ...func(*elb.Options)) (*elb.DescribeTagsOutput, error) {
+	out := &elb.DescribeTagsOutput{}
+	for _, name := range params.LoadBalancerNames {
+		out.TagDescriptions = append(out.TagDescriptions, elbtypes.TagDescription{LoadBalancerName: aws.String(name), Tags: f.tags[name]})
+	}
+	return out, nil
+}
+
+func tag(key, value string) []ec2types.Tag {
+	return []ec2types.Tag{{Key: aws.String(key), Value: aws.String(value)}}
+}
+
+// newFakeEC2Provider describes one resource of every kind
+func newFakeEC2Provider() (*EC2Provider, *fakeEC2, *fakeELBv2) {
+	client := &fakeEC2{
+		instances: &ec2.DescribeInstancesOutput{
+			Reservations: []ec2types.Reservation{{
+				Instances: []ec2types.Instance{{
+					InstanceId:   aws.String("i-1"),
+					InstanceType: ec2types.InstanceTypeM5Large,
+					State:        &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+					Tags:         tag("team", "web"),
+				}},
+			}},
+		},
+		volumes: &ec2.DescribeVolumesOutput{
+			Volumes: []ec2types.Volume{{
+				VolumeId:   aws.String("vol-1"),
+				Size:       aws.Int32(500),
+				VolumeType: ec2types.VolumeTypeGp3,
+				State:      ec2types.VolumeStateAvailable,
+			}},
+		},
+		snapshots: [2]*ec2.DescribeSnapshotsOutput{
+			{
+				Snapshots: []ec2types.Snapshot{{SnapshotId: aws.String("snap-1"), VolumeSize: aws.Int32(100)}},
+				NextToken: aws.String("page-2"),
+			},
+			{
+				Snapshots: []ec2types.Snapshot{{SnapshotId: aws.String("snap-2"), VolumeSize: aws.Int32(200)}},
+			},
+		},
+		images: &ec2.DescribeImagesOutput{
+			Images: []ec2types.Image{{
+				ImageId: aws.String("ami-1"),
+				BlockDeviceMappings: []ec2types.BlockDeviceMapping{
+					{Ebs: &ec2types.EbsBlockDevice{SnapshotId: aws.String("snap-2")}},
+					{VirtualName: aws.String("ephemeral0")},
+				},
+			}},
+		},
+		nats: &ec2.DescribeNatGatewaysOutput{
+			NatGateways: []ec2types.NatGateway{{NatGatewayId: aws.String("nat-1"), State: ec2types.NatGatewayStateAvailable}},
+		},
+		addresses: &ec2.DescribeAddressesOutput{
+			Addresses: []ec2types.Address{
+				{AllocationId: aws.String("eipalloc-idle"), PublicIp: aws.String("203.0.113.1")},
+				{AllocationId: aws.String("eipalloc-used"), PublicIp: aws.String("203.0.113.2"), AssociationId: aws.String("eipassoc-1")},
+			},
+		},
+		interfaces: &ec2.DescribeNetworkInterfacesOutput{
+			NetworkInterfaces: []ec2types.NetworkInterface{
+				{
+					NetworkInterfaceId: aws.String("eni-1"),
+					Association:        &ec2types.NetworkInterfaceAssociation{PublicIp: aws.String("198.51.100.7")},
+					Attachment:         &ec2types.NetworkInterfaceAttachment{InstanceId: aws.String("i-1")},
+				},
+				// Elastic IPs are listed from DescribeAddresses
+				{
+					NetworkInterfaceId: aws.String("eni-2"),
+					Association:        &ec2types.NetworkInterfaceAssociation{PublicIp: aws.String("203.0.113.2"), AllocationId: aws.String("eipalloc-used")},
+				},
+				{NetworkInterfaceId: aws.String("eni-private")},
+			},
+		},
+		endpoints: &ec2.DescribeVpcEndpointsOutput{
+			VpcEndpoints: []ec2types.VpcEndpoint{{VpcEndpointId: aws.String("vpce-1"), VpcEndpointType: ec2types.VpcEndpointTypeInterface}},
+		},
+		tgws: &ec2.DescribeTransitGatewayAttachmentsOutput{
+			TransitGatewayAttachments: []ec2types.TransitGatewayAttachment{{TransitGatewayAttachmentId: aws.String("tgw-attach-1")}},
+		},
+		vpns: &ec2.DescribeVpnConnectionsOutput{
+			VpnConnections: []ec2types.VpnConnection{{VpnConnectionId: aws.String("vpn-1")}},
+		},
+		routes: &ec2.DescribeRouteTablesOutput{
+			RouteTables: []ec2types.RouteTable{{
+				Routes: []ec2types.Route{
+					{NatGatewayId: aws.String("nat-1")},
+					{NatGatewayId: aws.String("nat-1")},
+				},
+			}},
+		},
+	}
+	lbs := &fakeELBv2{
+		lbs: &elbv2.DescribeLoadBalancersOutput{
+			LoadBalancers: []elbv2types.LoadBalancer{
+				{LoadBalancerArn: aws.String("arn:alb"), LoadBalancerName: aws.String("web"), Type: elbv2types.LoadBalancerTypeEnumApplication},
+				{LoadBalancerArn: aws.String("arn:nlb"), LoadBalancerName: aws.String("tcp"), Type: elbv2types.LoadBalancerTypeEnumNetwork},
+			},
+		},
+		tags:    map[string][]elbv2types.Tag{"arn:alb": {{Key: aws.String("team"), Value: aws.String("web")}}},
+		targets: map[string]int{"arn:alb": 3},
+	}
+	classic := &fakeELB{
+		lbs: &elb.DescribeLoadBalancersOutput{
+			LoadBalancerDescriptions: []elbtypes.LoadBalancerDescription{{LoadBalancerName: aws.String("legacy")}},
+		},
+		tags: map[string][]elbtypes.Tag{"legacy": {{Key: aws.String("team"), Value: aws.String("ops")}}},
+	}
+	return NewEC2Provider(client, lbs, classic), client, lbs
+}
+
+func byID(resources []Resource) map[string]Resource {
+	m := make(map[string]Resource, len(resources))
+	for _, r := range resources {
+		m[r.ID] = r
+	}
+	return m
+}
+
+func TestEC2Provider_ListResources(t *testing.T) {
+	p, client, _ := newFakeEC2Provider()
+
+	got, err := NewFinder(p).FindByService(context.Background(), "EC2 - Other", "eu-west-1", "")
+	if err != nil {
+		t.Fatalf("FindByService() error = %v", err)
+	}
+	resources := byID(got)
+
+	wantTypes := map[string]string{
+		"i-1":           TypeEC2Instance,
+		"vol-1":         TypeEBSVolume,
+		"snap-1":        TypeEBSSnapshot,
+		"snap-2":        TypeEBSSnapshot,
+		"nat-1":         TypeNATGateway,
+		"eipalloc-idle": TypeElasticIP,
+		"eipalloc-used": TypeElasticIP,
+		"198.51.100.7":  TypePublicIPv4,
+		"web":           TypeApplicationLB,
+		"tcp":           TypeNetworkLB,
+		"legacy":        TypeClassicLB,
+		"vpce-1":        TypeVPCEndpoint,
+		"tgw-attach-1":  TypeTGWAttachment,
+	}
+	if len(got) != len(wantTypes) {
+		t.Errorf("FindByService() found %d resources, want %d: %+v", len(got), len(wantTypes), got)
+	}
+	for id, typ := range wantTypes {
+		if r, ok := resources[id]; !ok || r.Type != typ {
+			t.Errorf("resource %s = %+v, want type %s", id, r, typ)
+		}
+	}
+
+	if len(client.owners) != 1 || client.owners[0] != "self" {
+		t.Errorf("snapshots described for owners %v, want [self]", client.owners)
+	}
+
+	attrs := []struct {
+		id, key, want string
+	}{
+		{"i-1", "instance_type", "m5.large"},
+		{"i-1", "state", "running"},
+		{"vol-1", "size_gib", "500"},
+		{"vol-1", "state", "available"},
+		{"snap-2", "size_gib", "200"},
+		{"snap-2", "image_id", "ami-1"},
+		{"snap-1", "image_id", ""},
+		{"eipalloc-idle", "state", "unassociated"},
+		{"eipalloc-used", "state", "associated"},
+		{"198.51.100.7", "instance_id", "i-1"},
+		{"vpce-1", "endpoint_type", "Interface"},
+		{"nat-1", "routes", "1"},
+		{"web", "targets", "3"},
+		{"tcp", "targets", "0"},
+	}
+	for _, a := range attrs {
+		if got := resources[a.id].Attributes[a.key]; got != a.want {
+			t.Errorf("%s attribute %s = %q, want %q", a.id, a.key, got, a.want)
+		}
+	}
+
+	if resources["web"].Tags["team"] != "web" || resources["legacy"].Tags["team"] != "ops" {
+		t.Errorf("load balancer tags not fetched: web %v, legacy %v", resources["web"].Tags, resources["legacy"].Tags)
+	}
+}
+
+func TestEC2Provider_Services(t *testing.T) {
+	p, _, _ := newFakeEC2Provider()
+
+	tests := []struct {
+		service string
+		want    []string
+	}{
+		{"elb", []string{"web", "tcp", "legacy"}},
+		{"vpc", []string{"eipalloc-idle", "eipalloc-used", "198.51.100.7", "vpce-1", "tgw-attach-1", "vpn-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			got, err := p.ListResources(context.Background(), tt.service, "us-east-1")
+			if err != nil {
+				t.Fatalf("ListResources() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ListResources() = %+v, want %v", got, tt.want)
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("resource %d = %s, want %s", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestEC2Provider_AMIFailure(t *testing.T) {
+	p, client, _ := newFakeEC2Provider()
+	client.images = nil
+
+	got, err := p.ListResources(context.Background(), "ec2", "us-east-1")
+	if err == nil || !strings.Contains(err.Error(), "failed to describe AMIs") {
+		t.Fatalf("ListResources() error = %v, want the failed AMI lookup", err)
+	}
+	if _, ok := byID(got)["snap-2"]; !ok {
+		t.Error("snapshot missing after the AMI lookup failed")
+	}
+}
+
+func TestEC2Provider_PartialFailures(t *testing.T) {
+	p, client, _ := newFakeEC2Provider()
+	client.volumes = nil
+	client.snapshots[1] = nil
+
+	got, err := p.ListResources(context.Background(), "ec2", "us-east-1")
+	if err == nil {
+		t.Fatal("ListResources() error = nil, want the failed calls")
+	}
+	for _, want := range []string{"failed to describe EBS volumes", "failed to describe EBS snapshots"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+
+	// The other kinds, and the first page of snapshots, are still listed
+	resources := byID(got)
+	for _, id := range []string{"i-1", "snap-1", "nat-1", "web"} {
+		if _, ok := resources[id]; !ok {
+			t.Errorf("resource %s missing after partial failure", id)
+		}
+	}
+	if _, ok := resources["vol-1"]; ok {
+		t.Error("volume listed although DescribeVolumes failed")
+	}
+
+	// FindByService keeps the resources alongside the error
+	found, err := NewFinder(p).FindByService(context.Background(), "ec2", "us-east-1", "team")
+	if err == nil || len(found) != 3 {
+		t.Errorf("FindByService() = %d resources, %v; want the 3 tagged ones and an error", len(found), err)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	m := make(map[string]int)
+	for i := 0; i < 45; i++ {
+		m[strings.Repeat("a", i+1)] = i
+	}
+
+	got := batches(m)
+	if len(got) != 3 || len(got[0]) != elbTagBatch || len(got[2]) != 5 {
+		t.Errorf("batches() sizes = %d groups, want 20, 20 and 5", len(got))
+	}
+}
@@ -6,117 +6,6 @@ import (
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
-func TestNewFinder(t *testing.T) {
-	// Test that NewFinder creates a Finder with all clients
-	finder := NewFinder(nil, nil, nil, nil, nil, nil, nil, nil)
-	if finder == nil {
-		t.Fatal("NewFinder() returned nil")
-	}
-
-	// Verify struct fields exist (compile-time check)
-	_ = finder.taggingClient
-	_ = finder.ec2Client
-	_ = finder.rdsClient
-	_ = finder.lambdaClient
-	_ = finder.s3Client
-	_ = finder.cloudfrontClient
-	_ = finder.ecsClient
-	_ = finder.eksClient
-}
-
-func TestFindByService_Routing(t *testing.T) {
-	tests := []struct {
-		name            string
-		service         string
-		expectedRoute   string
-		requiresRegion  bool
-		requiresTagKey  bool
-	}{
-		{
-			name:           "EC2 service routes to EC2 finder",
-			service:        "AmazonEC2",
-			expectedRoute:  "ec2",
-			requiresRegion: true,
-			requiresTagKey: true,
-		},
-		{
-			name:           "ec2 lowercase routes to EC2 finder",
-			service:        "ec2",
-			expectedRoute:  "ec2",
-			requiresRegion: true,
-			requiresTagKey: true,
-		},
-		{
-			name:           "RDS service routes to RDS finder",
-			service:        "AmazonRDS",
-			expectedRoute:  "rds",
-			requiresRegion: true,
-			requiresTagKey: true,
-		},
-		{
-			name:           "Lambda service routes to Lambda finder",
-			service:        "AWSLambda",
-			expectedRoute:  "lambda",
-			requiresRegion: true,
-			requiresTagKey: false,
-		},
-		{
-			name:           "S3 service routes to S3 finder",
-			service:        "AmazonS3",
-			expectedRoute:  "s3",
-			requiresRegion: false,
-			requiresTagKey: false,
-		},
-		{
-			name:           "CloudFront service routes to CloudFront finder",
-			service:        "AmazonCloudFront",
-			expectedRoute:  "cloudfront",
-			requiresRegion: false,
-			requiresTagKey: false,
-		},
-		{
-			name:           "ECS service routes to ECS finder",
-			service:        "AmazonECS",
-			expectedRoute:  "ecs",
-			requiresRegion: true,
-			requiresTagKey: false,
-		},
-		{
-			name:           "EKS service routes to EKS finder",
-			service:        "AmazonEKS",
-			expectedRoute:  "eks",
-			requiresRegion: true,
-			requiresTagKey: false,
-		},
-		{
-			name:           "Unknown service routes to tagging API",
-			service:        "AmazonDynamoDB",
-			expectedRoute:  "tagging",
-			requiresRegion: false,
-			requiresTagKey: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Document expected routing behavior
-			// Actual AWS API calls would require mocking
-
-			// The FindByService method should:
-			// - Normalize service name to lowercase
-			// - Route to appropriate service-specific finder based on service name
-			// - Pass region and tagKey where applicable
-			// - Fall back to generic tagging API for unknown services
-
-			t.Logf("Service %q should route to %s finder", tt.service, tt.expectedRoute)
-			t.Logf("  Requires region: %v", tt.requiresRegion)
-			t.Logf("  Requires tagKey: %v", tt.requiresTagKey)
-
-			t.Skip("Requires AWS API mocking for full test")
-		})
-	}
-}
-
 func TestExtractEC2Tags(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -213,116 +102,39 @@ func TestResourceStruct(t *testing.T) {
 	}
 }
 
-func TestFindEC2Resources_Signature(t *testing.T) {
-	// This test documents the expected signature and behavior
-	// Actual testing would require AWS API mocking
-
-	// The findEC2Resources method should:
-	// - Accept context.Context, region string, tagKey string
-	// - Return ([]Resource, error)
-	// - Query EC2 instances, volumes, and NAT gateways
-	// - Extract tags using extractEC2Tags helper
-	// - Continue gracefully if any API call fails (not all-or-nothing)
-	// - Construct proper ARNs with region and resource IDs
-
-	t.Skip("Requires AWS API mocking for full test")
-}
-
-func TestFindRDSResources_Signature(t *testing.T) {
-	// This test documents the expected signature and behavior
-	// Actual testing would require AWS API mocking
-
-	// The findRDSResources method should:
-	// - Accept context.Context, region string, tagKey string
-	// - Return ([]Resource, error)
-	// - Query RDS instances with DescribeDBInstances
-	// - Fetch tags for each instance with ListTagsForResource
-	// - Return error if DescribeDBInstances fails
-	// - Continue with empty tags if ListTagsForResource fails for an instance
-
-	t.Skip("Requires AWS API mocking for full test")
-}
-
-func TestFindViaTaggingAPI_Signature(t *testing.T) {
-	// This test documents the expected signature and behavior
-	// Actual testing would require AWS API mocking
-
-	// The findViaTaggingAPI method should:
-	// - Accept context.Context, service string, tagKey string
-	// - Return ([]Resource, error)
-	// - Use ResourceTypeFilters with service parameter
-	// - Add TagFilters if tagKey is not empty
-	// - Handle pagination with GetResourcesPaginator
-	// - Extract tags from each resource in result
-	// - Return error if GetResources API call fails
-
-	t.Skip("Requires AWS API mocking for full test")
+// Helper function for tests
+func stringPtr(s string) *string {
+	return &s
 }
 
-func TestServiceSpecificFinders_ErrorHandling(t *testing.T) {
-	// Document error handling expectations
-	tests := []struct {
-		name                string
-		finderMethod        string
-		expectedBehavior    string
-		continuesOnError    bool
-	}{
-		{
-			name:             "findEC2Resources continues on partial failures",
-			finderMethod:     "findEC2Resources",
-			expectedBehavior: "Returns all successfully fetched resources even if some API calls fail",
-			continuesOnError: true,
-		},
-		{
-			name:             "findRDSResources returns error on main API failure",
-			finderMethod:     "findRDSResources",
-			expectedBehavior: "Returns error if DescribeDBInstances fails, but continues if tag fetching fails",
-			continuesOnError: false,
-		},
-		{
-			name:             "findLambdaResources returns error on failure",
-			finderMethod:     "findLambdaResources",
-			expectedBehavior: "Returns error if ListFunctions fails",
-			continuesOnError: false,
-		},
-		{
-			name:             "findS3Resources returns error on failure",
-			finderMethod:     "findS3Resources",
-			expectedBehavior: "Returns error if ListBuckets fails, but continues on tag/location fetch errors",
-			continuesOnError: false,
-		},
-		{
-			name:             "findCloudFrontResources returns error on failure",
-			finderMethod:     "findCloudFrontResources",
-			expectedBehavior: "Returns error if ListDistributions fails",
-			continuesOnError: false,
-		},
-		{
-			name:             "findECSResources returns error on failure",
-			finderMethod:     "findECSResources",
-			expectedBehavior: "Returns error if ListClusters fails, continues if DescribeClusters fails for some",
-			continuesOnError: false,
-		},
-		{
-			name:             "findEKSResources returns error on failure",
-			finderMethod:     "findEKSResources",
-			expectedBehavior: "Returns error if ListClusters fails, continues if DescribeCluster fails for individual clusters",
-			continuesOnError: false,
-		},
+func TestMergeResources(t *testing.T) {
+	resources := []Resource{
+		{ID: "i-2", Type: "EC2 Instance", Account: "222222222222", Region: "us-east-1"},
+		{ID: "bucket", Type: "S3 Bucket", Account: "111111111111", Region: "eu-west-1"},
+		{ID: "i-1", Type: "EC2 Instance", Account: "111111111111", Region: "us-west-2"},
+		{ID: "bucket", Type: "S3 Bucket", Account: "111111111111", Region: "eu-west-1"},
+		{ID: "bucket", Type: "S3 Bucket", Account: "222222222222", Region: "eu-west-1"},
+		// Same name, different regions: two functions
+		{ID: "api", Type: "Lambda Function", Account: "111111111111", Region: "us-east-1"},
+		{ID: "api", Type: "Lambda Function", Account: "111111111111", Region: "eu-west-1"},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Logf("Method: %s", tt.finderMethod)
-			t.Logf("Behavior: %s", tt.expectedBehavior)
-			t.Logf("Continues on error: %v", tt.continuesOnError)
-
-			t.Skip("Requires AWS API mocking for full test")
-		})
+	merged := Merge(resources)
+	want := []string{
+		"111111111111/eu-west-1/bucket",
+		"111111111111/eu-west-1/api",
+		"111111111111/us-east-1/api",
+		"111111111111/us-west-2/i-1",
+		"222222222222/eu-west-1/bucket",
+		"222222222222/us-east-1/i-2",
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("Merge() kept %d resources, want %d (one duplicate bucket dropped): %+v", len(merged), len(want), merged)
+	}
+	for i, w := range want {
+		r := merged[i]
+		if got := r.Account + "/" + r.Region + "/" + r.ID; got != w {
+			t.Errorf("resource %d = %s, want %s (ordered by account then region)", i, got, w)
+		}
 	}
-}
-
-// Helper function for tests
-func stringPtr(s string) *string {
-	return &s
 }
@@ -0,0 +1,153 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ResourceProvider lists the resources of the services it handles
+type ResourceProvider interface {
+	// Services returns the short names of the services the provider lists,
+	// as in the service registry (ec2, rds, ...)
+	Services() []string
+
+	// ListResources lists the resources of one of those services in a
+	// region. When only some calls fail, it returns the resources it found
+	// along with the error.
+	ListResources(ctx context.Context, service, region string) ([]Resource, error)
+}
+
+// TagEnricher is implemented by providers whose listings don't include
+// tags. The Finder calls it before filtering by tag key.
+type TagEnricher interface {
+	EnrichTags(ctx context.Context, resources []Resource) error
+}
+
+// CostEnricher is implemented by providers with their own cost data, such
+// as an internal chargeback system. The Finder calls it last.
+type CostEnricher interface {
+	EnrichCosts(ctx context.Context, resources []Resource) error
+}
+
+// ProviderFactory builds a provider for the account and region of an AWS
+// config
+type ProviderFactory func(cfg aws.Config) ResourceProvider
+
+var (
+	providersMu sync.Mutex
+
+	// providerFactories holds the registered providers. The Tagging API
+	// comes first so every dedicated provider takes over from it.
+	providerFactories = []ProviderFactory{
+		func(cfg aws.Config) ResourceProvider {
+			return NewTaggingProvider(resourcegroupstaggingapi.NewFromConfig(cfg))
+		},
+		func(cfg aws.Config) ResourceProvider {
+			return NewEC2Provider(ec2.NewFromConfig(cfg), elbv2.NewFromConfig(cfg), elb.NewFromConfig(cfg))
+		},
+		func(cfg aws.Config) ResourceProvider { return NewRDSProvider(rds.NewFromConfig(cfg)) },
+		func(cfg aws.Config) ResourceProvider { return NewLambdaProvider(lambda.NewFromConfig(cfg)) },
+		func(cfg aws.Config) ResourceProvider { return NewS3Provider(s3.NewFromConfig(cfg)) },
+		func(cfg aws.Config) ResourceProvider { return NewCloudFrontProvider(cloudfront.NewFromConfig(cfg)) },
+		func(cfg aws.Config) ResourceProvider { return NewECSProvider(ecs.NewFromConfig(cfg)) },
+		func(cfg aws.Config) ResourceProvider { return NewEKSProvider(eks.NewFromConfig(cfg)) },
+	}
+)
+
+// RegisterProvider adds a provider to every Finder built by
+// NewFinderFromConfig. A provider registered later takes over the services
+// it shares with earlier ones, so it can replace a built-in provider.
+func RegisterProvider(factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providerFactories = append(providerFactories, factory)
+}
+
+// Finder helps locate resources for cost attribution by dispatching each
+// service to its provider
+type Finder struct {
+	providers map[string]ResourceProvider
+}
+
+// NewFinder creates a finder over the providers. Later providers take over
+// the services they share with earlier ones.
+func NewFinder(providers ...ResourceProvider) *Finder {
+	f := &Finder{providers: make(map[string]ResourceProvider)}
+	for _, p := range providers {
+		for _, service := range p.Services() {
+			f.providers[service] = p
+		}
+	}
+	return f
+}
+
+// NewFinderFromConfig creates a finder with every registered provider,
+// acting in the account and region of cfg
+func NewFinderFromConfig(cfg aws.Config) *Finder {
+	providersMu.Lock()
+	factories := append([]ProviderFactory(nil), providerFactories...)
+	providersMu.Unlock()
+
+	providers := make([]ResourceProvider, len(factories))
+	for i, factory := range factories {
+		providers[i] = factory(cfg)
+	}
+	return NewFinder(providers...)
+}
+
+// FindByService finds resources for a service given by any name
+// LookupService accepts, keeping only those with tagKey when it is set.
+// Partial listing and enrichment errors are returned with the resources
+// found so far.
+func (f *Finder) FindByService(ctx context.Context, service, region, tagKey string) ([]Resource, error) {
+	svc, err := LookupService(service)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := f.providers[svc.Name]
+	if !ok {
+		return nil, fmt.Errorf("no resource provider for %s", svc.Name)
+	}
+
+	resources, listErr := p.ListResources(ctx, svc.Name, region)
+	if listErr != nil && len(resources) == 0 {
+		return nil, listErr
+	}
+
+	if e, ok := p.(TagEnricher); ok {
+		if err := e.EnrichTags(ctx, resources); err != nil {
+			return resources, errors.Join(listErr, fmt.Errorf("failed to fetch %s tags: %w", svc.Name, err))
+		}
+	}
+
+	if tagKey != "" {
+		tagged := resources[:0]
+		for _, r := range resources {
+			if _, ok := r.Tags[tagKey]; ok {
+				tagged = append(tagged, r)
+			}
+		}
+		resources = tagged
+	}
+
+	if e, ok := p.(CostEnricher); ok {
+		if err := e.EnrichCosts(ctx, resources); err != nil {
+			return resources, errors.Join(listErr, fmt.Errorf("failed to fetch %s costs: %w", svc.Name, err))
+		}
+	}
+	return resources, listErr
+}
@@ -0,0 +1,197 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// fakeProvider lists fixed resources and records the calls it gets
+type fakeProvider struct {
+	services  []string
+	resources []Resource
+	err       error
+	calls     []string
+}
+
+func (p *fakeProvider) Services() []string {
+	return p.services
+}
+
+func (p *fakeProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
+	p.calls = append(p.calls, service+"/"+region)
+	if p.err != nil {
+		return nil, p.err
+	}
+	resources := make([]Resource, len(p.resources))
+	for i, r := range p.resources {
+		r.Region = region
+		resources[i] = r
+	}
+	return resources, nil
+}
+
+// enrichingProvider adds tags and costs by resource ID
+type enrichingProvider struct {
+	fakeProvider
+	tags    map[string]map[string]string
+	tagErr  error
+	costs   map[string]Cost
+	costIDs []string
+}
+
+func (p *enrichingProvider) EnrichTags(ctx context.Context, resources []Resource) error {
+	for i := range resources {
+		if tags, ok := p.tags[resources[i].ID]; ok {
+			resources[i].Tags = tags
+		}
+	}
+	return p.tagErr
+}
+
+func (p *enrichingProvider) EnrichCosts(ctx context.Context, resources []Resource) error {
+	for i := range resources {
+		p.costIDs = append(p.costIDs, resources[i].ID)
+		if c, ok := p.costs[resources[i].ID]; ok {
+			resources[i].Cost = &c
+		}
+	}
+	return nil
+}
+
+func TestNewFinder_LaterProvidersTakeOver(t *testing.T) {
+	fallback := &fakeProvider{services: []string{"ec2", "dynamodb"}, resources: []Resource{{ID: "fallback"}}}
+	dedicated := &fakeProvider{services: []string{"ec2"}, resources: []Resource{{ID: "i-1"}}}
+	finder := NewFinder(fallback, dedicated)
+
+	got, err := finder.FindByService(context.Background(), "Amazon Elastic Compute Cloud - Compute", "us-west-2", "")
+	if err != nil {
+		t.Fatalf("FindByService() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "i-1" || got[0].Region != "us-west-2" {
+		t.Errorf("FindByService() = %+v, want i-1 in us-west-2 from the later provider", got)
+	}
+	if len(dedicated.calls) != 1 || dedicated.calls[0] != "ec2/us-west-2" {
+		t.Errorf("dedicated provider calls = %v, want [ec2/us-west-2]", dedicated.calls)
+	}
+	if len(fallback.calls) != 0 {
+		t.Errorf("fallback provider was called for ec2: %v", fallback.calls)
+	}
+
+	if _, err := finder.FindByService(context.Background(), "Amazon DynamoDB", "us-west-2", ""); err != nil {
+		t.Fatalf("FindByService(dynamodb) error = %v", err)
+	}
+	if len(fallback.calls) != 1 || fallback.calls[0] != "dynamodb/us-west-2" {
+		t.Errorf("fallback provider calls = %v, want [dynamodb/us-west-2]", fallback.calls)
+	}
+}
+
+func TestFindByService_Errors(t *testing.T) {
+	listErr := errors.New("access denied")
+	finder := NewFinder(&fakeProvider{services: []string{"rds"}, err: listErr})
+
+	tests := []struct {
+		name    string
+		service string
+		want    string
+	}{
+		{"unknown service", "Amazon Made Up Service", "unknown service"},
+		{"no provider", "AWS Lambda", "no resource provider for lambda"},
+		{"list error", "AmazonRDS", "access denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := finder.FindByService(context.Background(), tt.service, "us-east-1", "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("FindByService(%q) error = %v, want one containing %q", tt.service, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindByService_Enrichment(t *testing.T) {
+	p := &enrichingProvider{
+		fakeProvider: fakeProvider{
+			services:  []string{"lambda"},
+			resources: []Resource{{ID: "api"}, {ID: "worker"}, {ID: "cron"}},
+		},
+		tags: map[string]map[string]string{
+			"api":    {"team": "web"},
+			"worker": {"team": "jobs"},
+			"cron":   {"env": "prod"},
+		},
+		costs: map[string]Cost{"api": {Current: 10}},
+	}
+
+	got, err := NewFinder(p).FindByService(context.Background(), "lambda", "us-east-1", "team")
+	if err != nil {
+		t.Fatalf("FindByService() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "api" || got[1].ID != "worker" {
+		t.Fatalf("FindByService() = %+v, want api and worker (tagged with team)", got)
+	}
+	if got[0].Cost == nil || got[0].Cost.Current != 10 {
+		t.Errorf("api cost = %+v, want the enriched cost", got[0].Cost)
+	}
+	// Costs are only fetched for the resources left after filtering
+	if len(p.costIDs) != 2 {
+		t.Errorf("EnrichCosts saw %v, want the 2 filtered resources", p.costIDs)
+	}
+}
+
+func TestFindByService_TagEnrichmentError(t *testing.T) {
+	p := &enrichingProvider{
+		fakeProvider: fakeProvider{services: []string{"s3"}, resources: []Resource{{ID: "logs"}}},
+		tagErr:       errors.New("throttled"),
+	}
+
+	got, err := NewFinder(p).FindByService(context.Background(), "s3", "us-east-1", "")
+	if err == nil || !strings.Contains(err.Error(), "failed to fetch s3 tags") {
+		t.Errorf("FindByService() error = %v, want a tag error", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("FindByService() = %+v, want the listed resource alongside the error", got)
+	}
+}
+
+func TestFindByService_Routing(t *testing.T) {
+	tests := []struct {
+		name          string
+		service       string
+		expectedRoute string
+	}{
+		{"EC2 product code routes to EC2 finder", "AmazonEC2", "ec2"},
+		{"EC2 Cost Explorer name routes to EC2 finder", "Amazon Elastic Compute Cloud - Compute", "ec2"},
+		{"EC2 - Other routes to EC2 finder", "EC2 - Other", "ec2"},
+		{"RDS Cost Explorer name routes to RDS finder", "Amazon Relational Database Service", "rds"},
+		{"Lambda routes to Lambda finder", "AWS Lambda", "lambda"},
+		{"S3 Cost Explorer name routes to S3 finder", "Amazon Simple Storage Service", "s3"},
+		{"CloudFront routes to CloudFront finder", "Amazon CloudFront", "cloudfront"},
+		{"ECS routes to ECS finder", "AmazonECS", "ecs"},
+		{"EKS routes to EKS finder", "Amazon Elastic Container Service for Kubernetes", "eks"},
+		{"DynamoDB routes to tagging API", "Amazon DynamoDB", "tagging"},
+	}
+
+	finder := NewFinderFromConfig(aws.Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := LookupService(tt.service)
+			if err != nil {
+				t.Fatalf("LookupService(%q) error = %v", tt.service, err)
+			}
+
+			route := svc.Name
+			if _, ok := finder.providers[svc.Name].(*TaggingProvider); ok {
+				route = "tagging"
+			}
+			if route != tt.expectedRoute {
+				t.Errorf("service %q routes to %s, want %s", tt.service, route, tt.expectedRoute)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+)
+
+// RDSAPI is the subset of the RDS client used by RDSProvider
+type RDSAPI interface {
+	DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error)
+	ListTagsForResource(ctx context.Context, params *rds.ListTagsForResourceInput, optFns ...func(*rds.Options)) (*rds.ListTagsForResourceOutput, error)
+}
+
+// RDSProvider lists RDS instances
+type RDSProvider struct {
+	client RDSAPI
+}
+
+// NewRDSProvider creates a provider backed by an RDS client
+func NewRDSProvider(client RDSAPI) *RDSProvider {
+	return &RDSProvider{client: client}
+}
+
+// Services returns the services RDSProvider lists
+func (p *RDSProvider) Services() []string {
+	return []string{"rds"}
+}
+
+// ListResources lists the RDS instances of the region
+func (p *RDSProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
+	var resources []Resource
+
+	resp, err := p.client.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe RDS instances: %w", err)
+	}
+
+	for _, instance := range resp.DBInstances {
+		resources = append(resources, Resource{
+			ARN:    aws.ToString(instance.DBInstanceArn),
+			ID:     aws.ToString(instance.DBInstanceIdentifier),
+			Type:   "RDS Instance",
+			Tags:   make(map[string]string),
+			Region: region,
+		})
+	}
+
+	return resources, nil
+}
+
+// EnrichTags fetches the tags of each instance; instances whose tags can't
+// be fetched keep none
+func (p *RDSProvider) EnrichTags(ctx context.Context, resources []Resource) error {
+	for i := range resources {
+		if resources[i].ARN == "" {
+			continue
+		}
+		tagResp, err := p.client.ListTagsForResource(ctx, &rds.ListTagsForResourceInput{
+			ResourceName: aws.String(resources[i].ARN),
+		})
+		if err != nil {
+			continue
+		}
+		for _, tag := range tagResp.TagList {
+			resources[i].Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+type fakeRDS struct {
+	instances []rdstypes.DBInstance
+	err       error
+	tags      map[string][]rdstypes.Tag
+}
+
+func (f *fakeRDS) DescribeDBInstances(ctx context.Context, params *rds.DescribeDBInstancesInput, optFns ...func(*rds.Options)) (*rds.DescribeDBInstancesOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &rds.DescribeDBInstancesOutput{DBInstances: f.instances}, nil
+}
+
+func (f *fakeRDS) ListTagsForResource(ctx context.Context, params *rds.ListTagsForResourceInput, optFns ...func(*rds.Options)) (*rds.ListTagsForResourceOutput, error) {
+	tags, ok := f.tags[aws.ToString(params.ResourceName)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &rds.ListTagsForResourceOutput{TagList: tags}, nil
+}
+
+func TestRDSProvider(t *testing.T) {
+	client := &fakeRDS{
+		instances: []rdstypes.DBInstance{
+			{DBInstanceArn: aws.String("arn:aws:rds:us-east-1:111111111111:db:orders"), DBInstanceIdentifier: aws.String("orders")},
+			{DBInstanceArn: aws.String("arn:aws:rds:us-east-1:111111111111:db:users"), DBInstanceIdentifier: aws.String("users")},
+		},
+		tags: map[string][]rdstypes.Tag{
+			"arn:aws:rds:us-east-1:111111111111:db:orders": {{Key: aws.String("team"), Value: aws.String("shop")}},
+		},
+	}
+	p := NewRDSProvider(client)
+
+	got, err := p.ListResources(context.Background(), "rds", "us-east-1")
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if err := p.EnrichTags(context.Background(), got); err != nil {
+		t.Fatalf("EnrichTags() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Tags["team"] != "shop" || len(got[1].Tags) != 0 {
+		t.Errorf("resources = %+v, want orders tagged and users untagged", got)
+	}
+
+	client.err = errors.New("access denied")
+	if _, err := p.ListResources(context.Background(), "rds", "us-east-1"); err == nil {
+		t.Error("ListResources() error = nil, want the describe error")
+	}
+}
@@ -0,0 +1,177 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Service is a service whose resources can be searched, with every name it
+// goes by
+type Service struct {
+	Name          string   // short name, e.g. ec2
+	Billing       []string // Cost Explorer SERVICE values, as spike --group-by service prints them
+	AlsoBilledAs  []string // other services' SERVICE values billing for resources it lists
+	Aliases       []string // product codes and other accepted names
+	ResourceTypes []string // Tagging API resource type filters
+}
+
+// services is the registry of searchable services. Names and aliases are
+// matched ignoring case, and CUR product names are listed as aliases.
+var services = []Service{
+	{
+		Name:          "ec2",
+		Billing:       []string{"Amazon Elastic Compute Cloud - Compute", "EC2 - Other"},
+		AlsoBilledAs:  []string{"Amazon Elastic Load Balancing", "Amazon Virtual Private Cloud"},
+		Aliases:       []string{"AmazonEC2", "Amazon Elastic Compute Cloud"},
+		ResourceTypes: []string{"ec2:instance", "ec2:volume", "ec2:snapshot", "ec2:natgateway", "ec2:elastic-ip"},
+	},
+	{
+		Name:          "rds",
+		Billing:       []string{"Amazon Relational Database Service"},
+		Aliases:       []string{"AmazonRDS"},
+		ResourceTypes: []string{"rds:db"},
+	},
+	{
+		Name:          "lambda",
+		Billing:       []string{"AWS Lambda"},
+		Aliases:       []string{"AWSLambda"},
+		ResourceTypes: []string{"lambda:function"},
+	},
+	{
+		Name:          "s3",
+		Billing:       []string{"Amazon Simple Storage Service"},
+		Aliases:       []string{"AmazonS3"},
+		ResourceTypes: []string{"s3"},
+	},
+	{
+		Name:          "cloudfront",
+		Billing:       []string{"Amazon CloudFront"},
+		Aliases:       []string{"AmazonCloudFront"},
+		ResourceTypes: []string{"cloudfront:distribution"},
+	},
+	{
+		Name:          "ecs",
+		Billing:       []string{"Amazon Elastic Container Service"},
+		Aliases:       []string{"AmazonECS"},
+		ResourceTypes: []string{"ecs:cluster", "ecs:service"},
+	},
+	{
+		Name:          "eks",
+		Billing:       []string{"Amazon Elastic Container Service for Kubernetes"},
+		Aliases:       []string{"AmazonEKS", "Amazon Elastic Kubernetes Service"},
+		ResourceTypes: []string{"eks:cluster"},
+	},
+	{
+		Name:          "dynamodb",
+		Billing:       []string{"Amazon DynamoDB"},
+		Aliases:       []string{"AmazonDynamoDB"},
+		ResourceTypes: []string{"dynamodb:table"},
+	},
+	{
+		Name:          "elasticache",
+		Billing:       []string{"Amazon ElastiCache"},
+		Aliases:       []string{"AmazonElastiCache"},
+		ResourceTypes: []string{"elasticache:cluster", "elasticache:replicationgroup"},
+	},
+	{
+		Name:          "opensearch",
+		Billing:       []string{"Amazon OpenSearch Service", "Amazon Elasticsearch Service"},
+		Aliases:       []string{"AmazonES"},
+		ResourceTypes: []string{"es:domain"},
+	},
+	{
+		Name:          "efs",
+		Billing:       []string{"Amazon Elastic File System"},
+		Aliases:       []string{"AmazonEFS"},
+		ResourceTypes: []string{"elasticfilesystem:file-system"},
+	},
+	{
+		Name:          "redshift",
+		Billing:       []string{"Amazon Redshift"},
+		Aliases:       []string{"AmazonRedshift"},
+		ResourceTypes: []string{"redshift:cluster"},
+	},
+	{
+		Name:          "elb",
+		Billing:       []string{"Amazon Elastic Load Balancing"},
+		Aliases:       []string{"AWSELB", "Elastic Load Balancing"},
+		ResourceTypes: []string{"elasticloadbalancing:loadbalancer"},
+	},
+	{
+		Name:          "vpc",
+		Billing:       []string{"Amazon Virtual Private Cloud"},
+		Aliases:       []string{"AmazonVPC"},
+		ResourceTypes: []string{"ec2:vpc-endpoint", "ec2:transit-gateway-attachment", "ec2:vpn-connection"},
+	},
+	{
+		Name:          "kinesis",
+		Billing:       []string{"Amazon Kinesis"},
+		Aliases:       []string{"AmazonKinesis"},
+		ResourceTypes: []string{"kinesis:stream"},
+	},
+	{
+		Name:          "sqs",
+		Billing:       []string{"Amazon Simple Queue Service"},
+		Aliases:       []string{"AWSQueueService"},
+		ResourceTypes: []string{"sqs"},
+	},
+	{
+		Name:          "sns",
+		Billing:       []string{"Amazon Simple Notification Service"},
+		Aliases:       []string{"AmazonSNS"},
+		ResourceTypes: []string{"sns"},
+	},
+	{
+		Name:          "cloudwatch",
+		Billing:       []string{"AmazonCloudWatch"},
+		Aliases:       []string{"Amazon CloudWatch"},
+		ResourceTypes: []string{"logs:log-group", "cloudwatch:alarm"},
+	},
+	{
+		Name:          "sagemaker",
+		Billing:       []string{"Amazon SageMaker"},
+		Aliases:       []string{"AmazonSageMaker"},
+		ResourceTypes: []string{"sagemaker:endpoint", "sagemaker:notebook-instance"},
+	},
+}
+
+// CostServices returns the Cost Explorer SERVICE values billing for the
+// resources the service lists
+func (s *Service) CostServices() []string {
+	return append(append([]string(nil), s.Billing...), s.AlsoBilledAs...)
+}
+
+// serviceIndex maps every lowercased name of a service to its index in
+// services
+var serviceIndex = func() map[string]int {
+	index := make(map[string]int)
+	for i, s := range services {
+		for _, name := range append(append([]string{s.Name}, s.Billing...), s.Aliases...) {
+			index[strings.ToLower(name)] = i
+		}
+	}
+	return index
+}()
+
+// LookupService finds a service by its Cost Explorer name, alias or short
+// name, ignoring case. Unknown names are an error listing the supported
+// Cost Explorer names.
+func LookupService(name string) (*Service, error) {
+	i, ok := serviceIndex[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown service %q; supported services are:\n  %s", name, strings.Join(ServiceNames(), "\n  "))
+	}
+	return &services[i], nil
+}
+
+// ServiceNames returns the Cost Explorer names of every supported service,
+// sorted
+func ServiceNames() []string {
+	var names []string
+	for _, s := range services {
+		names = append(names, s.Billing...)
+	}
+	sort.Strings(names)
+	return names
+}
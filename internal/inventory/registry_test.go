@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupService(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Amazon Elastic Compute Cloud - Compute", "ec2"},
+		{"EC2 - Other", "ec2"},
+		{"amazon elastic compute cloud", "ec2"},
+		{"Amazon Simple Storage Service", "s3"},
+		{"Amazon Relational Database Service", "rds"},
+		{"Amazon Elastic Container Service for Kubernetes", "eks"},
+		{"Amazon Elastic Container Service", "ecs"},
+		{"AmazonCloudWatch", "cloudwatch"},
+		{" AWSLambda ", "lambda"},
+		{"dynamodb", "dynamodb"},
+	}
+
+	for _, tt := range tests {
+		svc, err := LookupService(tt.name)
+		if err != nil {
+			t.Errorf("LookupService(%q) error = %v", tt.name, err)
+			continue
+		}
+		if svc.Name != tt.want {
+			t.Errorf("LookupService(%q) = %s, want %s", tt.name, svc.Name, tt.want)
+		}
+	}
+
+	_, err := LookupService("AmazonNATGateway")
+	if err == nil || !strings.Contains(err.Error(), "Amazon Elastic Compute Cloud - Compute") {
+		t.Errorf("LookupService() of an unknown name error = %v, want the supported names", err)
+	}
+}
+
+func TestServiceRegistry(t *testing.T) {
+	seen := make(map[string]string)
+	for _, s := range services {
+		if len(s.Billing) == 0 || len(s.ResourceTypes) == 0 {
+			t.Errorf("%s: every service needs Cost Explorer names and Tagging API resource types", s.Name)
+		}
+		for _, name := range append(append([]string{s.Name}, s.Billing...), s.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := seen[key]; ok && other != s.Name {
+				t.Errorf("%q names both %s and %s", name, other, s.Name)
+			}
+			seen[key] = s.Name
+		}
+	}
+
+	// Resources listed under another service must be costed under it too
+	for _, s := range services {
+		for _, name := range s.AlsoBilledAs {
+			if _, err := LookupService(name); err != nil {
+				t.Errorf("%s: %q is not a supported service", s.Name, name)
+			}
+		}
+	}
+}
+
+func TestCostServices(t *testing.T) {
+	svc, err := LookupService("ec2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(svc.CostServices(), ",")
+	want := "Amazon Elastic Compute Cloud - Compute,EC2 - Other,Amazon Elastic Load Balancing,Amazon Virtual Private Cloud"
+	if got != want {
+		t.Errorf("CostServices() = %s, want %s", got, want)
+	}
+}
+
+func TestResourceFromARN(t *testing.T) {
+	tests := []struct {
+		arn  string
+		id   string
+		typ  string
+		zone string
+	}{
+		{"arn:aws:dynamodb:us-east-1:111111111111:table/orders", "orders", "dynamodb:table", "us-east-1"},
+		{"arn:aws:rds:eu-west-1:111111111111:db:legacy", "legacy", "rds:db", "eu-west-1"},
+		{"arn:aws:sqs:us-west-2:111111111111:jobs", "jobs", "sqs", "us-west-2"},
+		{"arn:aws:s3:::logs-bucket", "logs-bucket", "s3", ""},
+	}
+
+	for _, tt := range tests {
+		r := resourceFromARN(tt.arn)
+		if r.ID != tt.id || r.Type != tt.typ || r.Region != tt.zone || r.ARN != tt.arn {
+			t.Errorf("resourceFromARN(%q) = %+v, want ID %s, type %s, region %q", tt.arn, r, tt.id, tt.typ, tt.zone)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package inventory
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
@@ -13,10 +15,32 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-func (f *Finder) findLambdaResources(ctx context.Context, region string) ([]Resource, error) {
+// LambdaAPI is the subset of the Lambda client used by LambdaProvider
+type LambdaAPI interface {
+	lambda.ListFunctionsAPIClient
+	ListTags(ctx context.Context, params *lambda.ListTagsInput, optFns ...func(*lambda.Options)) (*lambda.ListTagsOutput, error)
+}
+
+// LambdaProvider lists Lambda functions
+type LambdaProvider struct {
+	client LambdaAPI
+}
+
+// NewLambdaProvider creates a provider backed by a Lambda client
+func NewLambdaProvider(client LambdaAPI) *LambdaProvider {
+	return &LambdaProvider{client: client}
+}
+
+// Services returns the services LambdaProvider lists
+func (p *LambdaProvider) Services() []string {
+	return []string{"lambda"}
+}
+
+// ListResources lists the Lambda functions of the region
+func (p *LambdaProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
 	var resources []Resource
 
-	paginator := lambda.NewListFunctionsPaginator(f.lambdaClient, &lambda.ListFunctionsInput{})
+	paginator := lambda.NewListFunctionsPaginator(p.client, &lambda.ListFunctionsInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
@@ -24,22 +48,11 @@ func (f *Finder) findLambdaResources(ctx context.Context, region string) ([]Reso
 		}
 
 		for _, function := range output.Functions {
-			// Fetch tags for this function
-			tags := make(map[string]string)
-			if function.FunctionArn != nil {
-				tagResp, err := f.lambdaClient.ListTags(ctx, &lambda.ListTagsInput{
-					Resource: function.FunctionArn,
-				})
-				if err == nil && tagResp.Tags != nil {
-					tags = tagResp.Tags
-				}
-			}
-
 			resources = append(resources, Resource{
 				ARN:    aws.ToString(function.FunctionArn),
 				ID:     aws.ToString(function.FunctionName),
 				Type:   "Lambda Function",
-				Tags:   tags,
+				Tags:   make(map[string]string),
 				Region: region,
 			})
 		}
@@ -48,10 +61,50 @@ func (f *Finder) findLambdaResources(ctx context.Context, region string) ([]Reso
 	return resources, nil
 }
 
-func (f *Finder) findS3Resources(ctx context.Context) ([]Resource, error) {
+// EnrichTags fetches the tags of each function; functions whose tags can't
+// be fetched keep none
+func (p *LambdaProvider) EnrichTags(ctx context.Context, resources []Resource) error {
+	for i := range resources {
+		if resources[i].ARN == "" {
+			continue
+		}
+		tagResp, err := p.client.ListTags(ctx, &lambda.ListTagsInput{
+			Resource: aws.String(resources[i].ARN),
+		})
+		if err == nil && tagResp.Tags != nil {
+			resources[i].Tags = tagResp.Tags
+		}
+	}
+	return nil
+}
+
+// S3API is the subset of the S3 client used by S3Provider
+type S3API interface {
+	ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+}
+
+// S3Provider lists S3 buckets
+type S3Provider struct {
+	client S3API
+}
+
+// NewS3Provider creates a provider backed by an S3 client
+func NewS3Provider(client S3API) *S3Provider {
+	return &S3Provider{client: client}
+}
+
+// Services returns the services S3Provider lists
+func (p *S3Provider) Services() []string {
+	return []string{"s3"}
+}
+
+// ListResources lists every bucket of the account, each in its own region
+func (p *S3Provider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
 	var resources []Resource
 
-	output, err := f.s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	output, err := p.client.ListBuckets(ctx, &s3.ListBucketsInput{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list S3 buckets: %w", err)
 	}
@@ -59,19 +112,8 @@ func (f *Finder) findS3Resources(ctx context.Context) ([]Resource, error) {
 	for _, bucket := range output.Buckets {
 		bucketName := aws.ToString(bucket.Name)
 
-		// Fetch tags for this bucket
-		tags := make(map[string]string)
-		tagResp, err := f.s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
-			Bucket: bucket.Name,
-		})
-		if err == nil {
-			for _, tag := range tagResp.TagSet {
-				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
-			}
-		}
-
 		// Get bucket region
-		locationResp, err := f.s3Client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+		locationResp, err := p.client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
 			Bucket: bucket.Name,
 		})
 		bucketRegion := "us-east-1" // Default
@@ -83,7 +125,7 @@ func (f *Finder) findS3Resources(ctx context.Context) ([]Resource, error) {
 			ARN:    fmt.Sprintf("arn:aws:s3:::%s", bucketName),
 			ID:     bucketName,
 			Type:   "S3 Bucket",
-			Tags:   tags,
+			Tags:   make(map[string]string),
 			Region: bucketRegion,
 		})
 	}
@@ -91,10 +133,50 @@ func (f *Finder) findS3Resources(ctx context.Context) ([]Resource, error) {
 	return resources, nil
 }
 
-func (f *Finder) findCloudFrontResources(ctx context.Context) ([]Resource, error) {
+// EnrichTags fetches the tags of each bucket; buckets without a tag set
+// keep none
+func (p *S3Provider) EnrichTags(ctx context.Context, resources []Resource) error {
+	for i := range resources {
+		tagResp, err := p.client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+			Bucket: aws.String(resources[i].ID),
+		})
+		if err != nil {
+			continue
+		}
+		for _, tag := range tagResp.TagSet {
+			resources[i].Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	return nil
+}
+
+// CloudFrontAPI is the subset of the CloudFront client used by
+// CloudFrontProvider
+type CloudFrontAPI interface {
+	cloudfront.ListDistributionsAPIClient
+	ListTagsForResource(ctx context.Context, params *cloudfront.ListTagsForResourceInput, optFns ...func(*cloudfront.Options)) (*cloudfront.ListTagsForResourceOutput, error)
+}
+
+// CloudFrontProvider lists CloudFront distributions
+type CloudFrontProvider struct {
+	client CloudFrontAPI
+}
+
+// NewCloudFrontProvider creates a provider backed by a CloudFront client
+func NewCloudFrontProvider(client CloudFrontAPI) *CloudFrontProvider {
+	return &CloudFrontProvider{client: client}
+}
+
+// Services returns the services CloudFrontProvider lists
+func (p *CloudFrontProvider) Services() []string {
+	return []string{"cloudfront"}
+}
+
+// ListResources lists every distribution of the account in region global
+func (p *CloudFrontProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
 	var resources []Resource
 
-	paginator := cloudfront.NewListDistributionsPaginator(f.cloudfrontClient, &cloudfront.ListDistributionsInput{})
+	paginator := cloudfront.NewListDistributionsPaginator(p.client, &cloudfront.ListDistributionsInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
@@ -106,24 +188,11 @@ func (f *Finder) findCloudFrontResources(ctx context.Context) ([]Resource, error
 		}
 
 		for _, dist := range output.DistributionList.Items {
-			// Fetch tags for this distribution
-			tags := make(map[string]string)
-			if dist.ARN != nil {
-				tagResp, err := f.cloudfrontClient.ListTagsForResource(ctx, &cloudfront.ListTagsForResourceInput{
-					Resource: dist.ARN,
-				})
-				if err == nil && tagResp.Tags != nil {
-					for _, tag := range tagResp.Tags.Items {
-						tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
-					}
-				}
-			}
-
 			resources = append(resources, Resource{
 				ARN:    aws.ToString(dist.ARN),
 				ID:     aws.ToString(dist.Id),
 				Type:   "CloudFront Distribution",
-				Tags:   tags,
+				Tags:   make(map[string]string),
 				Region: "global",
 			})
 		}
@@ -132,15 +201,65 @@ func (f *Finder) findCloudFrontResources(ctx context.Context) ([]Resource, error
 	return resources, nil
 }
 
-func (f *Finder) findECSResources(ctx context.Context, region string) ([]Resource, error) {
-	var resources []Resource
+// EnrichTags fetches the tags of each distribution; distributions whose
+// tags can't be fetched keep none
+func (p *CloudFrontProvider) EnrichTags(ctx context.Context, resources []Resource) error {
+	for i := range resources {
+		if resources[i].ARN == "" {
+			continue
+		}
+		tagResp, err := p.client.ListTagsForResource(ctx, &cloudfront.ListTagsForResourceInput{
+			Resource: aws.String(resources[i].ARN),
+		})
+		if err != nil || tagResp.Tags == nil {
+			continue
+		}
+		for _, tag := range tagResp.Tags.Items {
+			resources[i].Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	return nil
+}
+
+// TypeECSCluster is the resource type listed by ECSProvider
+const TypeECSCluster = "ECS Cluster"
+
+// ECSAPI is the subset of the ECS client used by ECSProvider
+type ECSAPI interface {
+	ecs.ListClustersAPIClient
+	DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error)
+}
+
+// ECSProvider lists ECS clusters with their tags
+type ECSProvider struct {
+	client ECSAPI
+}
+
+// NewECSProvider creates a provider backed by an ECS client
+func NewECSProvider(client ECSAPI) *ECSProvider {
+	return &ECSProvider{client: client}
+}
+
+// Services returns the services ECSProvider lists
+func (p *ECSProvider) Services() []string {
+	return []string{"ecs"}
+}
+
+// ListResources lists the ECS clusters of the region. Clusters that can't
+// be described are left out and their errors returned with the rest.
+func (p *ECSProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
+	var (
+		resources []Resource
+		errs      []error
+	)
 
 	// List clusters
-	clusterPaginator := ecs.NewListClustersPaginator(f.ecsClient, &ecs.ListClustersInput{})
+	clusterPaginator := ecs.NewListClustersPaginator(p.client, &ecs.ListClustersInput{})
 	for clusterPaginator.HasMorePages() {
 		output, err := clusterPaginator.NextPage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list ECS clusters: %w", err)
+			errs = append(errs, fmt.Errorf("failed to list ECS clusters: %w", err))
+			break
 		}
 
 		if len(output.ClusterArns) == 0 {
@@ -148,13 +267,18 @@ func (f *Finder) findECSResources(ctx context.Context, region string) ([]Resourc
 		}
 
 		// Describe clusters to get details
-		describeResp, err := f.ecsClient.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+		describeResp, err := p.client.DescribeClusters(ctx, &ecs.DescribeClustersInput{
 			Clusters: output.ClusterArns,
 			Include:  []ecsTypes.ClusterField{ecsTypes.ClusterFieldTags},
 		})
 		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to describe ECS clusters: %w", err))
 			continue
 		}
+		for _, failure := range describeResp.Failures {
+			errs = append(errs, fmt.Errorf("failed to describe ECS cluster %s: %s",
+				aws.ToString(failure.Arn), aws.ToString(failure.Reason)))
+		}
 
 		for _, cluster := range describeResp.Clusters {
 			tags := make(map[string]string)
@@ -165,49 +289,136 @@ func (f *Finder) findECSResources(ctx context.Context, region string) ([]Resourc
 			resources = append(resources, Resource{
 				ARN:    aws.ToString(cluster.ClusterArn),
 				ID:     aws.ToString(cluster.ClusterName),
-				Type:   "ECS Cluster",
+				Type:   TypeECSCluster,
 				Tags:   tags,
 				Region: region,
+				Attributes: map[string]string{
+					"active_services":     strconv.Itoa(int(cluster.ActiveServicesCount)),
+					"running_tasks":       strconv.Itoa(int(cluster.RunningTasksCount)),
+					"container_instances": strconv.Itoa(int(cluster.RegisteredContainerInstancesCount)),
+				},
 			})
 		}
 	}
 
-	return resources, nil
+	return resources, errors.Join(errs...)
 }
 
-func (f *Finder) findEKSResources(ctx context.Context, region string) ([]Resource, error) {
-	var resources []Resource
+// TypeEKSCluster is the resource type listed by EKSProvider
+const TypeEKSCluster = "EKS Cluster"
+
+// EKSAPI is the subset of the EKS client used by EKSProvider
+type EKSAPI interface {
+	eks.ListClustersAPIClient
+	eks.ListNodegroupsAPIClient
+	eks.ListFargateProfilesAPIClient
+	DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error)
+}
 
-	paginator := eks.NewListClustersPaginator(f.eksClient, &eks.ListClustersInput{})
+// EKSProvider lists EKS clusters with their tags
+type EKSProvider struct {
+	client EKSAPI
+}
+
+// NewEKSProvider creates a provider backed by an EKS client
+func NewEKSProvider(client EKSAPI) *EKSProvider {
+	return &EKSProvider{client: client}
+}
+
+// Services returns the services EKSProvider lists
+func (p *EKSProvider) Services() []string {
+	return []string{"eks"}
+}
+
+// ListResources lists the EKS clusters of the region. Clusters that can't
+// be described are left out, and clusters whose node groups or Fargate
+// profiles can't be counted lack those attributes; their errors are returned
+// with the rest.
+func (p *EKSProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
+	var (
+		resources []Resource
+		errs      []error
+	)
+
+	paginator := eks.NewListClustersPaginator(p.client, &eks.ListClustersInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list EKS clusters: %w", err)
+			errs = append(errs, fmt.Errorf("failed to list EKS clusters: %w", err))
+			break
 		}
 
 		for _, clusterName := range output.Clusters {
 			// Describe cluster to get ARN and tags
-			describeResp, err := f.eksClient.DescribeCluster(ctx, &eks.DescribeClusterInput{
+			describeResp, err := p.client.DescribeCluster(ctx, &eks.DescribeClusterInput{
 				Name: aws.String(clusterName),
 			})
 			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to describe EKS cluster %s: %w", clusterName, err))
+				continue
+			}
+			if describeResp.Cluster == nil {
 				continue
 			}
 
 			tags := make(map[string]string)
-			if describeResp.Cluster != nil && describeResp.Cluster.Tags != nil {
+			if describeResp.Cluster.Tags != nil {
 				tags = describeResp.Cluster.Tags
 			}
 
+			attrs := map[string]string{
+				"version": aws.ToString(describeResp.Cluster.Version),
+				"status":  string(describeResp.Cluster.Status),
+			}
+			if n, err := p.countNodegroups(ctx, clusterName); err == nil {
+				attrs["nodegroups"] = strconv.Itoa(n)
+			} else {
+				errs = append(errs, fmt.Errorf("failed to list node groups of EKS cluster %s: %w", clusterName, err))
+			}
+			if n, err := p.countFargateProfiles(ctx, clusterName); err == nil {
+				attrs["fargate_profiles"] = strconv.Itoa(n)
+			} else {
+				errs = append(errs, fmt.Errorf("failed to list Fargate profiles of EKS cluster %s: %w", clusterName, err))
+			}
+
 			resources = append(resources, Resource{
-				ARN:    aws.ToString(describeResp.Cluster.Arn),
-				ID:     clusterName,
-				Type:   "EKS Cluster",
-				Tags:   tags,
-				Region: region,
+				ARN:        aws.ToString(describeResp.Cluster.Arn),
+				ID:         clusterName,
+				Type:       TypeEKSCluster,
+				Tags:       tags,
+				Region:     region,
+				Attributes: attrs,
 			})
 		}
 	}
 
-	return resources, nil
+	return resources, errors.Join(errs...)
+}
+
+// countNodegroups counts the managed node groups of a cluster
+func (p *EKSProvider) countNodegroups(ctx context.Context, cluster string) (int, error) {
+	n := 0
+	paginator := eks.NewListNodegroupsPaginator(p.client, &eks.ListNodegroupsInput{ClusterName: aws.String(cluster)})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		n += len(output.Nodegroups)
+	}
+	return n, nil
+}
+
+// countFargateProfiles counts the Fargate profiles of a cluster
+func (p *EKSProvider) countFargateProfiles(ctx context.Context, cluster string) (int, error) {
+	n := 0
+	paginator := eks.NewListFargateProfilesPaginator(p.client, &eks.ListFargateProfilesInput{ClusterName: aws.String(cluster)})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		n += len(output.FargateProfileNames)
+	}
+	return n, nil
 }
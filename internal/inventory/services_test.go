@@ -0,0 +1,166 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type fakeS3 struct {
+	buckets   []string
+	locations map[string]s3types.BucketLocationConstraint
+	tags      map[string][]s3types.Tag
+}
+
+func (f *fakeS3) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	out := &s3.ListBucketsOutput{}
+	for _, name := range f.buckets {
+		out.Buckets = append(out.Buckets, s3types.Bucket{Name: aws.String(name)})
+	}
+	return out, nil
+}
+
+func (f *fakeS3) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	return &s3.GetBucketLocationOutput{LocationConstraint: f.locations[aws.ToString(params.Bucket)]}, nil
+}
+
+func (f *fakeS3) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	tags, ok := f.tags[aws.ToString(params.Bucket)]
+	if !ok {
+		return nil, errors.New("NoSuchTagSet")
+	}
+	return &s3.GetBucketTaggingOutput{TagSet: tags}, nil
+}
+
+func TestS3Provider(t *testing.T) {
+	client := &fakeS3{
+		buckets:   []string{"logs", "assets"},
+		locations: map[string]s3types.BucketLocationConstraint{"assets": "eu-west-1"},
+		tags: map[string][]s3types.Tag{
+			"assets": {{Key: aws.String("team"), Value: aws.String("web")}},
+		},
+	}
+
+	// The Finder fetches tags before filtering by tag key
+	got, err := NewFinder(NewS3Provider(client)).FindByService(context.Background(), "Amazon Simple Storage Service", "us-west-2", "team")
+	if err != nil {
+		t.Fatalf("FindByService() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("FindByService() = %+v, want only the tagged bucket", got)
+	}
+	if got[0].ID != "assets" || got[0].Region != "eu-west-1" || got[0].ARN != "arn:aws:s3:::assets" {
+		t.Errorf("bucket = %+v, want assets in its own region", got[0])
+	}
+
+	all, err := NewS3Provider(client).ListResources(context.Background(), "s3", "us-west-2")
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if all[0].Region != "us-east-1" {
+		t.Errorf("bucket without a location constraint is in %s, want us-east-1", all[0].Region)
+	}
+}
+
+type fakeECS struct {
+	arns     []string
+	clusters []ecstypes.Cluster
+	failures []ecstypes.Failure
+	err      error
+}
+
+func (f *fakeECS) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	return &ecs.ListClustersOutput{ClusterArns: f.arns}, nil
+}
+
+func (f *fakeECS) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ecs.DescribeClustersOutput{Clusters: f.clusters, Failures: f.failures}, nil
+}
+
+func TestECSProvider_Failures(t *testing.T) {
+	client := &fakeECS{
+		arns:     []string{"arn:aws:ecs:us-east-1:111111111111:cluster/web", "arn:aws:ecs:us-east-1:111111111111:cluster/gone"},
+		clusters: []ecstypes.Cluster{{ClusterName: aws.String("web"), ActiveServicesCount: 2}},
+		failures: []ecstypes.Failure{{Arn: aws.String("arn:aws:ecs:us-east-1:111111111111:cluster/gone"), Reason: aws.String("MISSING")}},
+	}
+	p := NewECSProvider(client)
+
+	got, err := p.ListResources(context.Background(), "ecs", "us-east-1")
+	if err == nil || !strings.Contains(err.Error(), "cluster/gone: MISSING") {
+		t.Errorf("ListResources() error = %v, want the failed cluster", err)
+	}
+	if len(got) != 1 || got[0].Attributes["active_services"] != "2" {
+		t.Errorf("ListResources() = %+v, want the described cluster", got)
+	}
+
+	client.err = errors.New("ThrottlingException")
+	if _, err := p.ListResources(context.Background(), "ecs", "us-east-1"); err == nil {
+		t.Error("ListResources() error = nil, want the describe error")
+	}
+}
+
+type fakeEKS struct {
+	clusters   []string
+	describe   map[string]error
+	nodegroups map[string][]string
+}
+
+func (f *fakeEKS) ListClusters(ctx context.Context, params *eks.ListClustersInput, optFns ...func(*eks.Options)) (*eks.ListClustersOutput, error) {
+	return &eks.ListClustersOutput{Clusters: f.clusters}, nil
+}
+
+func (f *fakeEKS) DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+	name := aws.ToString(params.Name)
+	if err := f.describe[name]; err != nil {
+		return nil, err
+	}
+	return &eks.DescribeClusterOutput{Cluster: &ekstypes.Cluster{Name: aws.String(name), Version: aws.String("1.30")}}, nil
+}
+
+func (f *fakeEKS) ListNodegroups(ctx context.Context, params *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error) {
+	groups, ok := f.nodegroups[aws.ToString(params.ClusterName)]
+	if !ok {
+		return nil, errors.New("AccessDeniedException")
+	}
+	return &eks.ListNodegroupsOutput{Nodegroups: groups}, nil
+}
+
+func (f *fakeEKS) ListFargateProfiles(ctx context.Context, params *eks.ListFargateProfilesInput, optFns ...func(*eks.Options)) (*eks.ListFargateProfilesOutput, error) {
+	return &eks.ListFargateProfilesOutput{}, nil
+}
+
+func TestEKSProvider_Failures(t *testing.T) {
+	client := &fakeEKS{
+		clusters:   []string{"prod", "locked", "broken"},
+		describe:   map[string]error{"broken": errors.New("AccessDeniedException")},
+		nodegroups: map[string][]string{"prod": {"general", "gpu"}},
+	}
+
+	got, err := NewEKSProvider(client).ListResources(context.Background(), "eks", "us-east-1")
+	for _, want := range []string{"failed to describe EKS cluster broken", "failed to list node groups of EKS cluster locked"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ListResources() error = %v, want it to mention %q", err, want)
+		}
+	}
+
+	clusters := byID(got)
+	if len(got) != 2 || clusters["prod"].Attributes["nodegroups"] != "2" {
+		t.Fatalf("ListResources() = %+v, want prod and locked", got)
+	}
+	// An uncounted cluster must not look like one without node groups
+	if n, ok := clusters["locked"].Attributes["nodegroups"]; ok {
+		t.Errorf("locked nodegroups = %q, want it unset", n)
+	}
+}
@@ -0,0 +1,70 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+)
+
+// TaggingProvider lists the resources of every service in the registry
+// through the Resource Groups Tagging API, by the service's resource types.
+// It is the fallback for services without a dedicated provider, and only
+// sees resources that have or had tags.
+type TaggingProvider struct {
+	client resourcegroupstaggingapi.GetResourcesAPIClient
+}
+
+// NewTaggingProvider creates a provider backed by a Tagging API client
+func NewTaggingProvider(client resourcegroupstaggingapi.GetResourcesAPIClient) *TaggingProvider {
+	return &TaggingProvider{client: client}
+}
+
+// Services returns every service in the registry
+func (p *TaggingProvider) Services() []string {
+	names := make([]string, len(services))
+	for i, s := range services {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// ListResources lists the resources of the service's resource types,
+// describing each from its ARN
+func (p *TaggingProvider) ListResources(ctx context.Context, service, region string) ([]Resource, error) {
+	svc, err := LookupService(service)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	input := &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: svc.ResourceTypes,
+	}
+
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(p.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get resources: %w", err)
+		}
+
+		for _, resource := range output.ResourceTagMappingList {
+			tags := make(map[string]string)
+			for _, tag := range resource.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+
+			r := resourceFromARN(aws.ToString(resource.ResourceARN))
+			r.Tags = tags
+			if r.Region == "" {
+				r.Region = region
+			}
+			resources = append(resources, r)
+		}
+	}
+
+	return resources, nil
+}
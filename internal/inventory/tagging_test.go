@@ -0,0 +1,70 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+)
+
+type fakeTagging struct {
+	pages   [][]taggingtypes.ResourceTagMapping
+	err     error
+	filters []string
+}
+
+func (f *fakeTagging) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.filters = params.ResourceTypeFilters
+
+	page := 0
+	if params.PaginationToken != nil {
+		page = len(aws.ToString(params.PaginationToken))
+	}
+	out := &resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: f.pages[page]}
+	if page+1 < len(f.pages) {
+		out.PaginationToken = aws.String(string(make([]byte, page+1)))
+	}
+	return out, nil
+}
+
+func TestTaggingProvider_ListResources(t *testing.T) {
+	client := &fakeTagging{pages: [][]taggingtypes.ResourceTagMapping{
+		{{
+			ResourceARN: aws.String("arn:aws:dynamodb:us-west-2:111111111111:table/orders"),
+			Tags:        []taggingtypes.Tag{{Key: aws.String("team"), Value: aws.String("shop")}},
+		}},
+		{{ResourceARN: aws.String("arn:aws:sqs:us-west-2:111111111111:jobs")}},
+	}}
+
+	got, err := NewTaggingProvider(client).ListResources(context.Background(), "dynamodb", "us-east-1")
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if len(client.filters) != 1 || client.filters[0] != "dynamodb:table" {
+		t.Errorf("resource type filters = %v, want [dynamodb:table]", client.filters)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ListResources() = %+v, want one resource from each page", got)
+	}
+	if got[0].ID != "orders" || got[0].Type != "dynamodb:table" || got[0].Region != "us-west-2" || got[0].Tags["team"] != "shop" {
+		t.Errorf("first resource = %+v", got[0])
+	}
+
+	client.err = errors.New("throttled")
+	if _, err := NewTaggingProvider(client).ListResources(context.Background(), "dynamodb", "us-east-1"); err == nil {
+		t.Error("ListResources() error = nil, want the API error")
+	}
+}
+
+func TestTaggingProvider_Services(t *testing.T) {
+	got := NewTaggingProvider(&fakeTagging{}).Services()
+	if len(got) != len(services) {
+		t.Errorf("Services() lists %d services, want every one of the %d in the registry", len(got), len(services))
+	}
+}
@@ -0,0 +1,265 @@
+package investigate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+)
+
+// Target is one spike to search for resources: the service of a cost delta,
+// narrowed by the other dimension of the spike's grouping
+type Target struct {
+	Delta     cost.Delta
+	Service   string // Cost Explorer service name
+	Region    string // empty when the spike doesn't say
+	Account   string
+	UsageType string
+}
+
+// Targets turns the top N deltas at or above threshold into targets.
+// groupBy must include service; a region, linked account or usage type in
+// it narrows each target, and a usage type's prefix also gives its region.
+func Targets(deltas []cost.Delta, groupBy string, threshold float64, topN int) ([]Target, error) {
+	dims := strings.Split(groupBy, ",")
+	for i := range dims {
+		dims[i] = strings.TrimSpace(dims[i])
+	}
+	hasService := false
+	for _, dim := range dims {
+		if dim == "service" {
+			hasService = true
+		}
+	}
+	if !hasService {
+		return nil, fmt.Errorf("group-by %s: investigating needs service as one of the groupings", groupBy)
+	}
+
+	var targets []Target
+	for _, d := range deltas {
+		if d.AbsoluteDelta < threshold {
+			continue
+		}
+		if topN > 0 && len(targets) == topN {
+			break
+		}
+
+		t := Target{Delta: d}
+		keys := strings.Split(d.Key, " | ")
+		for i, dim := range dims {
+			if i >= len(keys) {
+				break
+			}
+			switch dim {
+			case "service":
+				t.Service = keys[i]
+			case "region":
+				t.Region = keys[i]
+			case "linked_account":
+				t.Account = keys[i]
+			case "usage_type":
+				t.UsageType = keys[i]
+			}
+		}
+		if t.Region == "" {
+			t.Region = UsageRegion(t.UsageType)
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// usageRegionPrefixes maps the region prefixes of usage types to regions.
+// Most us-east-1 usage types have no prefix.
+var usageRegionPrefixes = map[string]string{
+	"USE1": "us-east-1",
+	"USE2": "us-east-2",
+	"USW1": "us-west-1",
+	"USW2": "us-west-2",
+	"CAN1": "ca-central-1",
+	"SAE1": "sa-east-1",
+	"EUC1": "eu-central-1",
+	"EUC2": "eu-central-2",
+	"EU":   "eu-west-1",
+	"EUW1": "eu-west-1",
+	"EUW2": "eu-west-2",
+	"EUW3": "eu-west-3",
+	"EUN1": "eu-north-1",
+	"EUS1": "eu-south-1",
+	"APE1": "ap-east-1",
+	"APN1": "ap-northeast-1",
+	"APN2": "ap-northeast-2",
+	"APN3": "ap-northeast-3",
+	"APS1": "ap-southeast-1",
+	"APS2": "ap-southeast-2",
+	"APS3": "ap-south-1",
+	"APS4": "ap-southeast-3",
+	"MES1": "me-south-1",
+	"MEC1": "me-central-1",
+	"AFS1": "af-south-1",
+}
+
+// UsageRegion returns the region a usage type is billed in, or "" when it
+// has no known region prefix
+func UsageRegion(usageType string) string {
+	prefix, _, ok := strings.Cut(usageType, "-")
+	if !ok {
+		return ""
+	}
+	return usageRegionPrefixes[prefix]
+}
+
+// usageResourceTypes maps usage type fragments to the resource types they
+// bill for, most specific first
+var usageResourceTypes = []struct {
+	fragment string
+	types    []string
+}{
+	{"EBS:Snapshot", []string{"EBS Snapshot"}},
+	{"EBS:", []string{"EBS Volume"}},
+	{"NatGateway", []string{"NAT Gateway"}},
+	{"ElasticIP:", []string{"Elastic IP"}},
+	{"PublicIPv4:IdleAddress", []string{"Elastic IP"}},
+	{"PublicIPv4:", []string{"Elastic IP", "Public IPv4 Address"}},
+	{"LoadBalancerUsage", loadBalancerTypes},
+	{"LCUUsage", loadBalancerTypes},
+	{"VpcEndpoint", []string{"VPC Endpoint"}},
+	{"TransitGateway", []string{"Transit Gateway Attachment"}},
+	{"BoxUsage", []string{"EC2 Instance"}},
+	{"SpotUsage", []string{"EC2 Instance"}},
+	{"DedicatedUsage", []string{"EC2 Instance"}},
+	{"InstanceUsage", []string{"RDS Instance"}},
+	{"RDS:", []string{"RDS Instance"}},
+	{"Lambda-GB-Second", []string{"Lambda Function"}},
+	{"TimedStorage", []string{"S3 Bucket"}},
+}
+
+var loadBalancerTypes = []string{
+	"Application Load Balancer",
+	"Network Load Balancer",
+	"Gateway Load Balancer",
+	"Classic Load Balancer",
+}
+
+// ResourceTypes returns the resource types a usage type bills for, or nil
+// when it doesn't say
+func ResourceTypes(usageType string) []string {
+	for _, u := range usageResourceTypes {
+		if strings.Contains(usageType, u.fragment) {
+			return u.types
+		}
+	}
+	return nil
+}
+
+// Narrow keeps the resources of the types the target's usage type bills
+// for, or all of them when it doesn't say
+func Narrow(resources []inventory.Resource, t Target) []inventory.Resource {
+	types := ResourceTypes(t.UsageType)
+	if types == nil {
+		return resources
+	}
+
+	var narrowed []inventory.Resource
+	for _, r := range resources {
+		for _, typ := range types {
+			if r.Type == typ {
+				narrowed = append(narrowed, r)
+				break
+			}
+		}
+	}
+	return narrowed
+}
+
+// Finding is the outcome of searching for one target
+type Finding struct {
+	Target
+	Resources []inventory.Resource
+	Err       error
+}
+
+// Report nests the resources found for each spike by service and region
+type Report struct {
+	Metric   string          `json:"metric"`
+	Services []ServiceReport `json:"services"`
+}
+
+// ServiceReport is the spike of one service, summed over its targets
+type ServiceReport struct {
+	Service     string         `json:"service"`
+	CurrentCost float64        `json:"current_cost"`
+	PriorCost   float64        `json:"prior_cost"`
+	Delta       float64        `json:"delta"`
+	Regions     []RegionReport `json:"regions"`
+}
+
+// RegionReport is the part of a service's spike in one region and the
+// resources found there
+type RegionReport struct {
+	Region      string               `json:"region"`
+	Spikes      []string             `json:"spikes"` // keys of the deltas searched here
+	CurrentCost float64              `json:"current_cost"`
+	PriorCost   float64              `json:"prior_cost"`
+	Delta       float64              `json:"delta"`
+	Resources   []inventory.Resource `json:"resources"`
+	Errors      []string             `json:"errors,omitempty"`
+}
+
+// NewReport groups findings by service and by region within a service,
+// largest delta first
+func NewReport(metric string, findings []Finding) *Report {
+	report := &Report{Metric: metric, Services: []ServiceReport{}}
+	services := make(map[string]int)
+	regions := make(map[[2]string]int)
+
+	for _, f := range findings {
+		si, ok := services[f.Service]
+		if !ok {
+			si = len(report.Services)
+			services[f.Service] = si
+			report.Services = append(report.Services, ServiceReport{Service: f.Service})
+		}
+		svc := &report.Services[si]
+		svc.CurrentCost += f.Delta.CurrentCost
+		svc.PriorCost += f.Delta.PriorCost
+		svc.Delta += f.Delta.AbsoluteDelta
+
+		rk := [2]string{f.Service, f.Region}
+		ri, ok := regions[rk]
+		if !ok {
+			ri = len(svc.Regions)
+			regions[rk] = ri
+			svc.Regions = append(svc.Regions, RegionReport{Region: f.Region})
+		}
+		reg := &svc.Regions[ri]
+		reg.Spikes = append(reg.Spikes, f.Delta.Key)
+		reg.CurrentCost += f.Delta.CurrentCost
+		reg.PriorCost += f.Delta.PriorCost
+		reg.Delta += f.Delta.AbsoluteDelta
+		reg.Resources = append(reg.Resources, f.Resources...)
+		if f.Err != nil {
+			reg.Errors = append(reg.Errors, f.Err.Error())
+		}
+	}
+
+	for i := range report.Services {
+		svc := &report.Services[i]
+		for j := range svc.Regions {
+			reg := &svc.Regions[j]
+			reg.Resources = inventory.Merge(reg.Resources)
+			if reg.Resources == nil {
+				reg.Resources = []inventory.Resource{}
+			}
+		}
+		sort.SliceStable(svc.Regions, func(a, b int) bool {
+			return svc.Regions[a].Delta > svc.Regions[b].Delta
+		})
+	}
+	sort.SliceStable(report.Services, func(a, b int) bool {
+		return report.Services[a].Delta > report.Services[b].Delta
+	})
+	return report
+}
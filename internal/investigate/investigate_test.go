@@ -0,0 +1,145 @@
+package investigate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+)
+
+func TestTargets(t *testing.T) {
+	deltas := []cost.Delta{
+		{Key: "Amazon Elastic Compute Cloud - Compute | EUW2-BoxUsage:m5.large", AbsoluteDelta: 500},
+		{Key: "EC2 - Other | EBS:VolumeUsage.gp3", AbsoluteDelta: 200},
+		{Key: "AWS Lambda | USE2-Lambda-GB-Second", AbsoluteDelta: 150},
+		{Key: "Amazon Simple Storage Service | TimedStorage-ByteHrs", AbsoluteDelta: 50},
+	}
+
+	targets, err := Targets(deltas, "service,usage_type", 100, 2)
+	if err != nil {
+		t.Fatalf("Targets() error = %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("Targets() returned %d targets, want the top 2", len(targets))
+	}
+
+	first := targets[0]
+	if first.Service != "Amazon Elastic Compute Cloud - Compute" || first.UsageType != "EUW2-BoxUsage:m5.large" || first.Region != "eu-west-2" {
+		t.Errorf("first target = %+v, want EC2 in eu-west-2 from the usage type prefix", first)
+	}
+	if targets[1].Region != "" {
+		t.Errorf("usage type without a prefix should leave the region empty, got %q", targets[1].Region)
+	}
+
+	targets, err = Targets([]cost.Delta{{Key: "AWS Lambda | 111111111111", AbsoluteDelta: 10}}, "service, linked_account", 0, 0)
+	if err != nil {
+		t.Fatalf("Targets() error = %v", err)
+	}
+	if targets[0].Service != "AWS Lambda" || targets[0].Account != "111111111111" {
+		t.Errorf("target = %+v, want Lambda in account 111111111111", targets[0])
+	}
+
+	if _, err := Targets(deltas, "region,usage_type", 0, 0); err == nil || !strings.Contains(err.Error(), "service") {
+		t.Errorf("Targets() without service error = %v, want a group-by error", err)
+	}
+}
+
+func TestUsageRegion(t *testing.T) {
+	tests := []struct {
+		usageType string
+		want      string
+	}{
+		{"USW2-BoxUsage:c5.xlarge", "us-west-2"},
+		{"APS3-NatGateway-Hours", "ap-south-1"},
+		{"EU-DataTransfer-Out-Bytes", "eu-west-1"},
+		{"BoxUsage:m5.large", ""},
+		{"DataTransfer-Out-Bytes", ""},
+	}
+
+	for _, tt := range tests {
+		if got := UsageRegion(tt.usageType); got != tt.want {
+			t.Errorf("UsageRegion(%q) = %q, want %q", tt.usageType, got, tt.want)
+		}
+	}
+}
+
+func TestNarrow(t *testing.T) {
+	resources := []inventory.Resource{
+		{ID: "i-111", Type: "EC2 Instance"},
+		{ID: "vol-222", Type: "EBS Volume"},
+		{ID: "nat-333", Type: "NAT Gateway"},
+		{ID: "snap-444", Type: "EBS Snapshot"},
+		{ID: "eipalloc-555", Type: "Elastic IP"},
+		{ID: "web", Type: "Application Load Balancer"},
+	}
+
+	tests := []struct {
+		usageType string
+		want      []string
+	}{
+		{"USE2-EBS:VolumeUsage.gp3", []string{"vol-222"}},
+		{"NatGateway-Bytes", []string{"nat-333"}},
+		{"SpotUsage:c5.large", []string{"i-111"}},
+		{"EBS:SnapshotUsage", []string{"snap-444"}},
+		{"USE1-PublicIPv4:IdleAddress", []string{"eipalloc-555"}},
+		{"EU-LoadBalancerUsage", []string{"web"}},
+		{"DataTransfer-Regional-Bytes", []string{"i-111", "vol-222", "nat-333", "snap-444", "eipalloc-555", "web"}},
+	}
+
+	for _, tt := range tests {
+		got := Narrow(resources, Target{UsageType: tt.usageType})
+		var ids []string
+		for _, r := range got {
+			ids = append(ids, r.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Narrow(%q) = %v, want %v", tt.usageType, ids, tt.want)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	ec2 := "Amazon Elastic Compute Cloud - Compute"
+	findings := []Finding{
+		{
+			Target:    Target{Service: ec2, Region: "us-east-1", Delta: cost.Delta{Key: ec2 + " | BoxUsage", CurrentCost: 300, PriorCost: 100, AbsoluteDelta: 200}},
+			Resources: []inventory.Resource{{ID: "i-111", Type: "EC2 Instance"}},
+		},
+		{
+			Target: Target{Service: "Amazon DynamoDB", Region: "us-east-1", Delta: cost.Delta{Key: "Amazon DynamoDB", CurrentCost: 400, PriorCost: 50, AbsoluteDelta: 350}},
+			Err:    errors.New("no resource inventory for Amazon DynamoDB"),
+		},
+		{
+			Target:    Target{Service: ec2, Region: "eu-west-1", Delta: cost.Delta{Key: ec2 + " | EU-BoxUsage", CurrentCost: 250, PriorCost: 0, AbsoluteDelta: 250}},
+			Resources: []inventory.Resource{{ID: "i-222", Type: "EC2 Instance"}},
+		},
+		{
+			Target:    Target{Service: ec2, Region: "us-east-1", Delta: cost.Delta{Key: ec2 + " | SpotUsage", CurrentCost: 100, PriorCost: 0, AbsoluteDelta: 100}},
+			Resources: []inventory.Resource{{ID: "i-111", Type: "EC2 Instance"}},
+		},
+	}
+
+	report := NewReport("UnblendedCost", findings)
+
+	if len(report.Services) != 2 {
+		t.Fatalf("NewReport() has %d services, want 2", len(report.Services))
+	}
+
+	svc := report.Services[0]
+	if svc.Service != ec2 || svc.Delta != 550 || svc.CurrentCost != 650 || svc.PriorCost != 100 {
+		t.Errorf("first service = %s %.0f, want EC2 with the summed delta 550", svc.Service, svc.Delta)
+	}
+	if len(svc.Regions) != 2 || svc.Regions[0].Region != "us-east-1" || svc.Regions[0].Delta != 300 {
+		t.Fatalf("EC2 regions = %+v, want us-east-1 (300) before eu-west-1 (250)", svc.Regions)
+	}
+	if len(svc.Regions[0].Spikes) != 2 || len(svc.Regions[0].Resources) != 1 {
+		t.Errorf("us-east-1 should hold both spikes and i-111 once, got %+v", svc.Regions[0])
+	}
+
+	dynamo := report.Services[1].Regions[0]
+	if len(dynamo.Errors) != 1 || dynamo.Resources == nil {
+		t.Errorf("DynamoDB region = %+v, want its error and an empty resource list", dynamo)
+	}
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pfrederiksen/cost-blame/internal/cost"
+	"github.com/pfrederiksen/cost-blame/internal/investigate"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
+)
+
+// InvestigationOutput formats an investigation for JSON output
+type InvestigationOutput struct {
+	Period *PeriodOutput `json:"period,omitempty"`
+	*investigate.Report
+}
+
+// PrintInvestigation outputs an investigation report as a table, JSON or
+// Markdown. The window labels the compared periods and may be nil.
+func PrintInvestigation(report *investigate.Report, window *timewin.Window, asJSON, asMarkdown bool) error {
+	switch {
+	case asJSON:
+		return PrintJSON(os.Stdout, InvestigationOutput{Period: NewPeriodOutput(window), Report: report})
+	case asMarkdown:
+		return WriteInvestigationMarkdown(os.Stdout, report, window)
+	default:
+		return printInvestigationTable(report, window)
+	}
+}
+
+func printInvestigationTable(report *investigate.Report, window *timewin.Window) error {
+	if len(report.Services) == 0 {
+		fmt.Println("No cost changes found matching criteria")
+		return nil
+	}
+
+	fmt.Printf("Metric: %s\n", report.Metric)
+	if window != nil {
+		fmt.Println(window.Describe())
+	}
+
+	for _, svc := range report.Services {
+		fmt.Printf("\n%s: %s → %s (%s)\n", svc.Service,
+			cost.FormatAmount(report.Metric, svc.PriorCost),
+			cost.FormatAmount(report.Metric, svc.CurrentCost),
+			signedAmount(report.Metric, svc.Delta))
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Region", "Account", "ID", "Type", "Tags"})
+		table.SetBorder(true)
+		table.SetAutoWrapText(false)
+		table.SetAutoMergeCellsByColumnIndex([]int{0})
+
+		for _, reg := range svc.Regions {
+			label := fmt.Sprintf("%s (%s)", reg.Region, signedAmount(report.Metric, reg.Delta))
+			for _, err := range reg.Errors {
+				table.Append([]string{label, "-", "⚠️  " + err, "-", "-"})
+			}
+			if len(reg.Resources) == 0 && len(reg.Errors) == 0 {
+				table.Append([]string{label, "-", "No resources found", "-", "-"})
+			}
+			for _, r := range reg.Resources {
+				account := r.Account
+				if account == "" {
+					account = "-"
+				}
+				table.Append([]string{label, account, r.ID, r.Type, formatTags(r.Tags)})
+			}
+		}
+
+		table.Render()
+	}
+	return nil
+}
+
+// WriteInvestigationMarkdown writes an investigation report as Markdown,
+// ready to paste into a ticket or pull request
+func WriteInvestigationMarkdown(w io.Writer, report *investigate.Report, window *timewin.Window) error {
+	var b strings.Builder
+
+	b.WriteString("# Cost investigation\n\n")
+	fmt.Fprintf(&b, "Metric: %s\n", report.Metric)
+	if window != nil {
+		fmt.Fprintf(&b, "\n%s\n", window.Describe())
+	}
+	if len(report.Services) == 0 {
+		b.WriteString("\nNo cost changes found matching criteria\n")
+	}
+
+	for _, svc := range report.Services {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", markdownEscape(svc.Service), signedAmount(report.Metric, svc.Delta))
+		fmt.Fprintf(&b, "Prior %s, current %s.\n",
+			cost.FormatAmount(report.Metric, svc.PriorCost),
+			cost.FormatAmount(report.Metric, svc.CurrentCost))
+
+		for _, reg := range svc.Regions {
+			fmt.Fprintf(&b, "\n### %s (%s)\n\n", reg.Region, signedAmount(report.Metric, reg.Delta))
+			for _, spike := range reg.Spikes {
+				fmt.Fprintf(&b, "- Spike: `%s`\n", spike)
+			}
+			for _, err := range reg.Errors {
+				fmt.Fprintf(&b, "- ⚠️ %s\n", markdownEscape(err))
+			}
+
+			if len(reg.Resources) == 0 {
+				b.WriteString("\nNo resources found.\n")
+				continue
+			}
+
+			b.WriteString("\n| Account | ID | Type | Tags |\n|---|---|---|---|\n")
+			for _, r := range reg.Resources {
+				account := r.Account
+				if account == "" {
+					account = "-"
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+					markdownEscape(account),
+					markdownEscape(r.ID),
+					markdownEscape(r.Type),
+					markdownEscape(formatTags(r.Tags)))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// signedAmount formats a delta with an explicit sign
+func signedAmount(metric string, amount float64) string {
+	if amount < 0 {
+		return "-" + cost.FormatAmount(metric, -amount)
+	}
+	return "+" + cost.FormatAmount(metric, amount)
+}
+
+// markdownEscape keeps a value from breaking a Markdown table row
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
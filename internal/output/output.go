@@ -4,28 +4,68 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/pfrederiksen/cost-blame/internal/cache"
 	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/inventory"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
 )
 
 // DeltaOutput formats cost deltas for output
 type DeltaOutput struct {
-	Deltas    []cost.Delta `json:"deltas"`
-	Threshold float64      `json:"threshold,omitempty"`
-	TopN      int          `json:"top_n,omitempty"`
+	Metric    string        `json:"metric"`
+	Period    *PeriodOutput `json:"period,omitempty"`
+	Deltas    []cost.Delta  `json:"deltas"`
+	Threshold float64       `json:"threshold,omitempty"`
+	TopN      int           `json:"top_n,omitempty"`
+}
+
+// PeriodOutput describes the compared periods, with times in the reporting
+// timezone
+type PeriodOutput struct {
+	CurrentStart time.Time `json:"current_start"`
+	CurrentEnd   time.Time `json:"current_end"`
+	PriorStart   time.Time `json:"prior_start"`
+	PriorEnd     time.Time `json:"prior_end"`
+	Timezone     string    `json:"timezone"`
+	Bucketing    string    `json:"bucketing"`
+}
+
+// NewPeriodOutput converts a window for JSON output; nil stays nil
+func NewPeriodOutput(window *timewin.Window) *PeriodOutput {
+	if window == nil {
+		return nil
+	}
+	loc := window.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &PeriodOutput{
+		CurrentStart: window.CurrentStart.In(loc),
+		CurrentEnd:   window.CurrentEnd.In(loc),
+		PriorStart:   window.PriorStart.In(loc),
+		PriorEnd:     window.PriorEnd.In(loc),
+		Timezone:     loc.String(),
+		Bucketing:    window.Bucketing(),
+	}
 }
 
 // ResourceOutput formats resources for output
 type ResourceOutput struct {
 	Resources []inventory.Resource `json:"resources"`
 	Service   string               `json:"service"`
+	Errors    []string             `json:"errors,omitempty"` // accounts or regions that could not be searched
 }
 
-// PrintDeltas outputs cost deltas as table or JSON
-func PrintDeltas(deltas []cost.Delta, threshold float64, topN int, asJSON bool, includeToday bool) error {
+// PrintDeltas outputs cost deltas as table or JSON. The window labels the
+// compared periods and may be nil.
+func PrintDeltas(deltas []cost.Delta, threshold float64, topN int, asJSON bool, window *timewin.Window) error {
 	// Filter by threshold
 	filtered := make([]cost.Delta, 0)
 	for _, d := range deltas {
@@ -40,14 +80,14 @@ func PrintDeltas(deltas []cost.Delta, threshold float64, topN int, asJSON bool,
 	}
 
 	if asJSON {
-		return printDeltasJSON(filtered, threshold, topN)
+		return printDeltasJSON(filtered, threshold, topN, window)
 	}
 
-	return printDeltasTable(filtered, includeToday)
+	return printDeltasTable(filtered, window)
 }
 
-func printDeltasTable(deltas []cost.Delta, includeToday bool) error {
-	if includeToday {
+func printDeltasTable(deltas []cost.Delta, window *timewin.Window) error {
+	if window != nil && window.IncludesToday() {
 		fmt.Fprintf(os.Stderr, "⚠️  Warning: Current period includes today; costs are not final\n\n")
 	}
 
@@ -56,18 +96,42 @@ func printDeltasTable(deltas []cost.Delta, includeToday bool) error {
 		return nil
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Key", "Current", "Prior", "Delta", "Change %", "New?"})
-	table.SetBorder(true)
-	table.SetAutoWrapText(false)
-	table.SetColumnAlignment([]int{
+	metric := cost.MetricOf(deltas)
+	fmt.Printf("Metric: %s\n", metric)
+	if window != nil {
+		fmt.Println(window.Describe())
+	}
+	if deltas[0].PerDay {
+		fmt.Println("Note: the periods have different numbers of days; costs are per-day averages")
+	}
+
+	// Price/volume columns are shown when spike --explain-rate filled them in
+	explained := deltas[0].Decomposed
+	trend := len(deltas[0].CurrentSeries) > 0
+
+	header := []string{"Key", "Current", "Prior", "Delta", "Change %", "New?"}
+	alignment := []int{
 		tablewriter.ALIGN_LEFT,
 		tablewriter.ALIGN_RIGHT,
 		tablewriter.ALIGN_RIGHT,
 		tablewriter.ALIGN_RIGHT,
 		tablewriter.ALIGN_RIGHT,
 		tablewriter.ALIGN_CENTER,
-	})
+	}
+	if explained {
+		header = append(header, "Volume", "Rate", "Mix")
+		alignment = append(alignment, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT)
+	}
+	if trend {
+		header = append(header, "Prior │ Current")
+		alignment = append(alignment, tablewriter.ALIGN_LEFT)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetBorder(true)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(alignment)
 
 	for _, d := range deltas {
 		newSpender := ""
@@ -80,22 +144,34 @@ func printDeltasTable(deltas []cost.Delta, includeToday bool) error {
 			pctStr = "NEW"
 		}
 
-		table.Append([]string{
+		row := []string{
 			d.Key,
-			fmt.Sprintf("$%.2f", d.CurrentCost),
-			fmt.Sprintf("$%.2f", d.PriorCost),
-			fmt.Sprintf("$%.2f", d.AbsoluteDelta),
+			cost.FormatAmount(metric, d.CurrentCost),
+			cost.FormatAmount(metric, d.PriorCost),
+			cost.FormatAmount(metric, d.AbsoluteDelta),
 			pctStr,
 			newSpender,
-		})
+		}
+		if explained {
+			row = append(row,
+				cost.FormatAmount(metric, d.VolumeEffect),
+				cost.FormatAmount(metric, d.RateEffect),
+				cost.FormatAmount(metric, d.MixEffect))
+		}
+		if trend {
+			row = append(row, trendLine(d))
+		}
+		table.Append(row)
 	}
 
 	table.Render()
 	return nil
 }
 
-func printDeltasJSON(deltas []cost.Delta, threshold float64, topN int) error {
+func printDeltasJSON(deltas []cost.Delta, threshold float64, topN int, window *timewin.Window) error {
 	output := DeltaOutput{
+		Metric:    cost.MetricOf(deltas),
+		Period:    NewPeriodOutput(window),
 		Deltas:    deltas,
 		Threshold: threshold,
 		TopN:      topN,
@@ -106,12 +182,59 @@ func printDeltasJSON(deltas []cost.Delta, threshold float64, topN int) error {
 	return encoder.Encode(output)
 }
 
-// PrintResources outputs resources as table or JSON
-func PrintResources(resources []inventory.Resource, service string, asJSON bool) error {
+// sparkBars are the sparkline levels from lowest to highest
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// trendLine draws the prior and current series of a delta as sparklines on
+// a shared scale
+func trendLine(d cost.Delta) string {
+	values := make([]float64, 0, len(d.PriorSeries)+len(d.CurrentSeries))
+	for _, p := range d.PriorSeries {
+		values = append(values, p.Value)
+	}
+	for _, p := range d.CurrentSeries {
+		values = append(values, p.Value)
+	}
+
+	line := []rune(sparkline(values))
+	return string(line[:len(d.PriorSeries)]) + "│" + string(line[len(d.PriorSeries):])
+}
+
+// sparkline draws one bar per value, scaled from zero to the largest value
+// so a flat series reads as flat. Credits are drawn as zero.
+func sparkline(values []float64) string {
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	bars := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if max > 0 && v > 0 {
+			level = int(math.Round(v / max * float64(len(sparkBars)-1)))
+		}
+		bars[i] = sparkBars[level]
+	}
+	return string(bars)
+}
+
+// PrintResources outputs resources as table or JSON, along with the errors
+// of any accounts or regions that could not be searched
+func PrintResources(resources []inventory.Resource, service string, asJSON bool, errs ...error) error {
 	if asJSON {
-		return printResourcesJSON(resources, service)
+		return printResourcesJSON(resources, service, errs)
 	}
 
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: results are incomplete; %d lookups failed:\n", len(errs))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "  - %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
 	return printResourcesTable(resources)
 }
 
@@ -121,35 +244,211 @@ func printResourcesTable(resources []inventory.Resource) error {
 		return nil
 	}
 
+	// Resources are labeled with their account when known, with their
+	// region when several were searched, with their details when the
+	// provider described them, with their costs when billing data was
+	// matched, and with their creators when CloudTrail was searched
+	withAccount := false
+	withDetails := false
+	withCreator := false
+	created := 0
+	metric := ""
+	withCost := false
+	regions := make(map[string]bool)
+	for _, r := range resources {
+		if r.Account != "" {
+			withAccount = true
+		}
+		if len(r.Attributes) > 0 {
+			withDetails = true
+		}
+		if r.Cost != nil || r.Billing != "" {
+			withCost = true
+		}
+		if r.Creator != nil {
+			withCreator = true
+			if r.Creator.InWindow {
+				created++
+			}
+		}
+		if r.Cost != nil && metric == "" {
+			metric = r.Cost.Metric
+		}
+		regions[r.Region] = true
+	}
+	withRegion := len(regions) > 1
+
+	header := []string{"ID", "Type", "Tags"}
+	if withDetails {
+		header = append(header, "Details")
+	}
+	if withCost {
+		header = append(header, "Current", "Prior", "Delta", "Billing")
+	}
+	if withCreator {
+		header = append(header, "Created By", "Created")
+	}
+	if withRegion {
+		header = append([]string{"Region"}, header...)
+	}
+	if withAccount {
+		header = append([]string{"Account"}, header...)
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Type", "Tags"})
+	table.SetHeader(header)
 	table.SetBorder(true)
 	table.SetAutoWrapText(false)
 
 	for _, r := range resources {
 		tagsStr := formatTags(r.Tags)
-		table.Append([]string{
+		row := []string{
 			r.ID,
 			r.Type,
 			tagsStr,
-		})
+		}
+		if withDetails {
+			row = append(row, formatAttributes(r.Attributes))
+		}
+		if withCost {
+			row = append(row, resourceCostColumns(r, metric)...)
+		}
+		if withCreator {
+			row = append(row, creatorColumns(r.Creator)...)
+		}
+		if withRegion {
+			row = append([]string{r.Region}, row...)
+		}
+		if withAccount {
+			row = append([]string{r.Account}, row...)
+		}
+		table.Append(row)
 	}
 
 	table.Render()
+
+	if created > 0 {
+		fmt.Printf("\n🆕 %d of %d resources were created in the current period\n", created, len(resources))
+	}
 	return nil
 }
 
-func printResourcesJSON(resources []inventory.Resource, service string) error {
+// creatorColumns formats who created a resource and when, flagging
+// resources created in the current period
+func creatorColumns(c *inventory.Creator) []string {
+	if c == nil {
+		return []string{"-", "-"}
+	}
+	when := c.Time.UTC().Format("2006-01-02 15:04")
+	if c.InWindow {
+		when = "🆕 " + when
+	}
+	return []string{c.Principal(), when}
+}
+
+// resourceCostColumns formats the current, prior, delta and billing state
+// columns of a resource
+func resourceCostColumns(r inventory.Resource, metric string) []string {
+	billing := r.Billing
+	if billing == "" {
+		billing = "-"
+	}
+	if r.Cost == nil {
+		return []string{"-", "-", "-", billing}
+	}
+	return []string{
+		cost.FormatAmount(metric, r.Cost.Current),
+		cost.FormatAmount(metric, r.Cost.Prior),
+		cost.FormatAmount(metric, r.Cost.Delta),
+		billing,
+	}
+}
+
+func printResourcesJSON(resources []inventory.Resource, service string, errs []error) error {
 	output := ResourceOutput{
 		Resources: resources,
 		Service:   service,
 	}
+	for _, err := range errs {
+		output.Errors = append(output.Errors, err.Error())
+	}
 
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(output)
 }
 
+// CacheOutput formats cache entries for output
+type CacheOutput struct {
+	Dir     string        `json:"dir"`
+	Entries []cache.Entry `json:"entries"`
+}
+
+// PrintCacheEntries outputs cache entries as table or JSON
+func PrintCacheEntries(entries []cache.Entry, dir string, asJSON bool) error {
+	if asJSON {
+		return PrintJSON(os.Stdout, CacheOutput{Dir: dir, Entries: entries})
+	}
+
+	fmt.Printf("Cache directory: %s\n", dir)
+	if len(entries) == 0 {
+		fmt.Println("Cache is empty")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Key", "Account", "Period", "Granularity", "Size", "Created", "Expires"})
+	table.SetBorder(true)
+	table.SetAutoWrapText(false)
+
+	var totalSize int64
+	for _, e := range entries {
+		totalSize += e.Size
+
+		expires := "never"
+		if e.Expired {
+			expires = "expired"
+		} else if !e.ExpiresAt.IsZero() {
+			expires = e.ExpiresAt.Local().Format("2006-01-02 15:04")
+		}
+
+		period := "-"
+		if !e.Start.IsZero() {
+			period = fmt.Sprintf("%s → %s", e.Start.Format("2006-01-02"), e.End.Format("2006-01-02"))
+		}
+
+		key := e.Key
+		if len(key) > 12 {
+			key = key[:12]
+		}
+
+		table.Append([]string{
+			key,
+			e.Namespace,
+			period,
+			e.Granularity,
+			formatBytes(e.Size),
+			e.CreatedAt.Local().Format("2006-01-02 15:04"),
+			expires,
+		})
+	}
+
+	table.Render()
+	fmt.Printf("%d entries, %s\n", len(entries), formatBytes(totalSize))
+	return nil
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
 func formatTags(tags map[string]string) string {
 	if len(tags) == 0 {
 		return "-"
@@ -171,6 +470,26 @@ func formatTags(tags map[string]string) string {
 	return result
 }
 
+// formatAttributes lists resource attributes as key=value pairs, sorted by
+// key
+func formatAttributes(attrs map[string]string) string {
+	if len(attrs) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, attrs[k])
+	}
+	return strings.Join(pairs, ", ")
+}
+
 // PrintJSON prints any value as JSON
 func PrintJSON(w io.Writer, v interface{}) error {
 	encoder := json.NewEncoder(w)
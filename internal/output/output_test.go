@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pfrederiksen/cost-blame/internal/cache"
 	"github.com/pfrederiksen/cost-blame/internal/cost"
 	"github.com/pfrederiksen/cost-blame/internal/inventory"
+	"github.com/pfrederiksen/cost-blame/internal/investigate"
+	"github.com/pfrederiksen/cost-blame/internal/timewin"
 )
 
 func TestFormatTags(t *testing.T) {
@@ -68,6 +73,25 @@ func TestFormatTags(t *testing.T) {
 	}
 }
 
+func TestFormatAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]string
+		want  string
+	}{
+		{"none", nil, "-"},
+		{"sorted by key", map[string]string{"state": "available", "size_gib": "500"}, "size_gib=500, state=available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAttributes(tt.attrs); got != tt.want {
+				t.Errorf("formatAttributes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPrintResources_Empty(t *testing.T) {
 	// Test that empty resource list doesn't crash
 	err := PrintResources([]inventory.Resource{}, "AmazonEC2", false)
@@ -78,7 +102,7 @@ func TestPrintResources_Empty(t *testing.T) {
 
 func TestPrintDeltas_Empty(t *testing.T) {
 	// Test that empty delta list doesn't crash
-	err := PrintDeltas(nil, 0, 10, false, false)
+	err := PrintDeltas(nil, 0, 10, false, nil)
 	if err != nil {
 		t.Errorf("PrintDeltas() error = %v", err)
 	}
@@ -152,7 +176,7 @@ func TestPrintDeltas_ThresholdFiltering(t *testing.T) {
 	// Should filter out Service3 and Service4
 	// Note: This test just verifies the function doesn't crash
 	// Actual filtering is tested by checking the function runs without error
-	err := PrintDeltas(deltas, 100.0, 0, true, false)
+	err := PrintDeltas(deltas, 100.0, 0, true, nil)
 	if err != nil {
 		t.Errorf("PrintDeltas() error = %v", err)
 	}
@@ -169,7 +193,7 @@ func TestPrintDeltas_TopNLimit(t *testing.T) {
 
 	// Test with topN = 5
 	// Should only output 5 deltas
-	err := PrintDeltas(deltas, 0, 5, true, false)
+	err := PrintDeltas(deltas, 0, 5, true, nil)
 	if err != nil {
 		t.Errorf("PrintDeltas() error = %v", err)
 	}
@@ -187,7 +211,7 @@ func TestPrintDeltas_JSON(t *testing.T) {
 	}
 
 	// Test JSON output (outputs to stdout, so we just verify no error)
-	err := PrintDeltas(deltas, 0, 10, true, false)
+	err := PrintDeltas(deltas, 0, 10, true, nil)
 	if err != nil {
 		t.Errorf("PrintDeltas() with JSON error = %v", err)
 	}
@@ -260,3 +284,274 @@ func TestResourceOutput_Struct(t *testing.T) {
 		t.Errorf("Service = %v, want AmazonEC2", decoded.Service)
 	}
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KiB"},
+		{3 << 20, "3.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPrintCacheEntries(t *testing.T) {
+	entries := []cache.Entry{
+		{Key: "0123456789abcdef", Namespace: "111111111111", Size: 2048},
+		{Key: "fedcba9876543210", Expired: true},
+	}
+
+	if err := PrintCacheEntries(entries, "/tmp/cache", false); err != nil {
+		t.Errorf("PrintCacheEntries() error = %v", err)
+	}
+	if err := PrintCacheEntries(nil, "/tmp/cache", true); err != nil {
+		t.Errorf("PrintCacheEntries() with JSON error = %v", err)
+	}
+}
+
+func TestPrintDeltas_ExplainRate(t *testing.T) {
+	deltas := []cost.Delta{
+		{Key: "AmazonEC2", CurrentCost: 330, PriorCost: 100, AbsoluteDelta: 230, PercentChange: 230,
+			Decomposed: true, VolumeEffect: 100, RateEffect: 100, MixEffect: 30},
+	}
+
+	if err := PrintDeltas(deltas, 0, 10, false, nil); err != nil {
+		t.Errorf("PrintDeltas() with decomposition error = %v", err)
+	}
+}
+
+func TestPrintDeltas_PerDay(t *testing.T) {
+	deltas := []cost.Delta{
+		{Key: "AmazonEC2", CurrentCost: 10, PriorCost: 9, AbsoluteDelta: 1, PercentChange: 11.1, PerDay: true},
+	}
+
+	if err := PrintDeltas(deltas, 0, 10, false, nil); err != nil {
+		t.Errorf("PrintDeltas() with per-day costs error = %v", err)
+	}
+}
+
+func TestNewPeriodOutput(t *testing.T) {
+	if NewPeriodOutput(nil) != nil {
+		t.Error("NewPeriodOutput(nil) should be nil")
+	}
+
+	window := &timewin.Window{
+		CurrentStart: time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC),
+		CurrentEnd:   time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		PriorStart:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		PriorEnd:     time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC),
+		Location:     time.FixedZone("PDT", -7*3600),
+	}
+	p := NewPeriodOutput(window)
+	if p.Timezone != "PDT" || p.CurrentEnd.Format(time.RFC3339) != "2026-10-14T17:00:00-07:00" {
+		t.Errorf("unexpected period output: %+v", p)
+	}
+	if !strings.Contains(p.Bucketing, "UTC days") {
+		t.Errorf("Bucketing = %q, want UTC days label", p.Bucketing)
+	}
+
+	if err := PrintDeltas([]cost.Delta{{Key: "AmazonEC2", AbsoluteDelta: 1}}, 0, 10, false, window); err != nil {
+		t.Errorf("PrintDeltas() with window error = %v", err)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"ramp", []float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{"flat stays full", []float64{5, 5, 5}, "███"},
+		{"one bad day", []float64{1, 1, 10, 1}, "▂▂█▂"},
+		{"credits and zeros", []float64{-5, 0, 0}, "▁▁▁"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values); got != tt.want {
+				t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+
+	d := cost.Delta{
+		PriorSeries:   []cost.SeriesPoint{{Value: 1}, {Value: 1}},
+		CurrentSeries: []cost.SeriesPoint{{Value: 1}, {Value: 8}},
+	}
+	if got := trendLine(d); got != "▂▂│▂█" {
+		t.Errorf("trendLine() = %q, want prior and current on one scale", got)
+	}
+	if err := PrintDeltas([]cost.Delta{d}, 0, 10, false, nil); err != nil {
+		t.Errorf("PrintDeltas() with series error = %v", err)
+	}
+}
+
+func TestPrintResources_Accounts(t *testing.T) {
+	resources := []inventory.Resource{
+		{ID: "i-111", Type: "EC2 Instance", Account: "111111111111"},
+		{ID: "i-222", Type: "EC2 Instance", Account: "222222222222"},
+	}
+
+	if err := PrintResources(resources, "AmazonEC2", false); err != nil {
+		t.Errorf("PrintResources() with accounts error = %v", err)
+	}
+}
+
+func TestPrintResources_Errors(t *testing.T) {
+	resources := []inventory.Resource{
+		{ID: "i-111", Type: "EC2 Instance", Region: "us-east-1"},
+		{ID: "i-222", Type: "EC2 Instance", Region: "eu-west-1"},
+	}
+	errs := []error{fmt.Errorf("region ap-south-1: opt-in region disabled")}
+
+	if err := PrintResources(resources, "AmazonEC2", false, errs...); err != nil {
+		t.Errorf("PrintResources() with errors error = %v", err)
+	}
+	if err := PrintResources(resources, "AmazonEC2", true, errs...); err != nil {
+		t.Errorf("PrintResources() with JSON errors error = %v", err)
+	}
+}
+
+func TestResourceCostColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource inventory.Resource
+		want     []string
+	}{
+		{
+			name:     "matched",
+			resource: inventory.Resource{ID: "i-111", Cost: &inventory.Cost{Current: 70, Prior: 20, Delta: 50}},
+			want:     []string{"$70.00", "$20.00", "$50.00", "-"},
+		},
+		{
+			name:     "billing only",
+			resource: inventory.Resource{ID: "i-gone", Cost: &inventory.Cost{Prior: 25, Delta: -25}, Billing: inventory.BillingOnly},
+			want:     []string{"$0.00", "$25.00", "$-25.00", "billing-only"},
+		},
+		{
+			name:     "inventory only",
+			resource: inventory.Resource{ID: "i-idle", Billing: inventory.InventoryOnly},
+			want:     []string{"-", "-", "-", "inventory-only"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resourceCostColumns(tt.resource, "UnblendedCost")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("resourceCostColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatorColumns(t *testing.T) {
+	created := time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		creator *inventory.Creator
+		want    []string
+	}{
+		{
+			name:    "unknown",
+			creator: nil,
+			want:    []string{"-", "-"},
+		},
+		{
+			name:    "before the period",
+			creator: &inventory.Creator{ARN: "arn:aws:iam::123456789012:user/alice", Time: created},
+			want:    []string{"user/alice", "2024-03-14 09:30"},
+		},
+		{
+			name:    "in the period",
+			creator: &inventory.Creator{ARN: "arn:aws:sts::123456789012:assumed-role/Deploy/ci", Time: created, InWindow: true},
+			want:    []string{"assumed-role/Deploy/ci", "🆕 2024-03-14 09:30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := creatorColumns(tt.creator)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("creatorColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteInvestigationMarkdown(t *testing.T) {
+	report := &investigate.Report{
+		Metric: "UnblendedCost",
+		Services: []investigate.ServiceReport{{
+			Service:     "Amazon Elastic Compute Cloud - Compute",
+			CurrentCost: 300,
+			PriorCost:   100,
+			Delta:       200,
+			Regions: []investigate.RegionReport{
+				{
+					Region:    "us-east-1",
+					Spikes:    []string{"Amazon Elastic Compute Cloud - Compute | us-east-1"},
+					Delta:     200,
+					Resources: []inventory.Resource{{ID: "i-111", Type: "EC2 Instance", Tags: map[string]string{"team": "a|b"}}},
+				},
+				{
+					Region: "eu-west-1",
+					Delta:  -20,
+					Errors: []string{"region eu-west-1: access denied"},
+				},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteInvestigationMarkdown(&buf, report, nil); err != nil {
+		t.Fatalf("WriteInvestigationMarkdown() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"## Amazon Elastic Compute Cloud - Compute (+$200.00)",
+		"### us-east-1 (+$200.00)",
+		"| - | i-111 | EC2 Instance | team=a\\|b |",
+		"### eu-west-1 (-$20.00)",
+		"- ⚠️ region eu-west-1: access denied",
+		"No resources found.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in Markdown, got:\n%s", want, out)
+		}
+	}
+
+	if err := PrintInvestigation(report, nil, false, false); err != nil {
+		t.Errorf("PrintInvestigation() table error = %v", err)
+	}
+	if err := PrintInvestigation(report, nil, true, false); err != nil {
+		t.Errorf("PrintInvestigation() JSON error = %v", err)
+	}
+}
+
+func TestWasteCost(t *testing.T) {
+	tests := []struct {
+		cost       float64
+		upperBound bool
+		want       string
+	}{
+		{12.5, false, "$12.50"},
+		{12.5, true, "≤ $12.50"},
+	}
+
+	for _, tt := range tests {
+		if got := wasteCost(tt.cost, tt.upperBound); got != tt.want {
+			t.Errorf("wasteCost(%v, %v) = %q, want %q", tt.cost, tt.upperBound, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pfrederiksen/cost-blame/internal/waste"
+)
+
+// WasteOutput formats waste items for JSON output
+type WasteOutput struct {
+	Items            []waste.Item `json:"items"`
+	TotalMonthlyCost float64      `json:"total_monthly_cost"`
+	Errors           []string     `json:"errors,omitempty"`
+}
+
+// PrintWaste outputs waste items as a table or JSON. Errors from accounts
+// or regions that couldn't be searched are reported alongside.
+func PrintWaste(items []waste.Item, asJSON bool, errs ...error) error {
+	if asJSON {
+		out := WasteOutput{Items: items, TotalMonthlyCost: waste.Total(items)}
+		if out.Items == nil {
+			out.Items = []waste.Item{}
+		}
+		for _, err := range errs {
+			out.Errors = append(out.Errors, err.Error())
+		}
+		return PrintJSON(os.Stdout, out)
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: results are incomplete; %d lookups failed:\n", len(errs))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "  - %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No idle or orphaned resources found")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Account", "Region", "ID", "Type", "Reason", "Monthly Cost", "Detail"})
+	table.SetBorder(true)
+	table.SetAutoWrapText(false)
+
+	upperBound := false
+	for _, item := range items {
+		account := item.Resource.Account
+		if account == "" {
+			account = "-"
+		}
+		table.Append([]string{
+			account,
+			item.Resource.Region,
+			item.Resource.ID,
+			item.Resource.Type,
+			item.Reason,
+			wasteCost(item.MonthlyCost, item.UpperBound),
+			item.Detail,
+		})
+		upperBound = upperBound || item.UpperBound
+	}
+
+	table.SetFooter([]string{"", "", "", "", "Total", wasteCost(waste.Total(items), upperBound), ""})
+	table.Render()
+
+	fmt.Println("\nMonthly costs are estimated from us-east-1 list prices, before usage charges")
+	if upperBound {
+		fmt.Println("Costs marked ≤ are upper bounds: snapshots are billed only for the blocks they store")
+	}
+	return nil
+}
+
+// wasteCost formats an estimated monthly cost, marking upper bounds
+func wasteCost(cost float64, upperBound bool) string {
+	if upperBound {
+		return fmt.Sprintf("≤ $%.2f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -0,0 +1,254 @@
+package timewin
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// dateLayout is the format of absolute dates in a Spec
+const dateLayout = "2006-01-02"
+
+// Periods lists the calendar periods accepted by Spec.Period
+var Periods = []string{"last-month", "mtd", "qtd", "ytd"}
+
+// Comparisons lists the calendar-aligned prior periods accepted by
+// Spec.Compare:
+//
+//	wow           the same days one week earlier
+//	mom           the same days of the month one month earlier
+//	yoy           the same months one year earlier for periods starting on the
+//	              first of a month, otherwise the same weekdays 52 weeks earlier
+//	same-weekday  the closest earlier days starting on the same weekday
+var Comparisons = []string{"wow", "mom", "yoy", "same-weekday"}
+
+// Spec selects the current period and the prior period it is compared with.
+// At most one of Last, From/To and Period may be set; with none, Last
+// defaults to 7d. Absolute dates are whole days and To is inclusive.
+//
+// Days are UTC days, as Cost Explorer buckets daily costs, but "today" is
+// taken from Location so that "yesterday" matches the reporting timezone.
+// With Hourly set, day boundaries fall on Location midnights instead.
+type Spec struct {
+	Last        string    // relative window: 48h, 7d, 30d
+	From        string    // first day of the current period
+	To          string    // last day of the current period (default: yesterday)
+	Period      string    // last-month, mtd, qtd or ytd
+	CompareFrom string    // first day of the prior period (default: derived from the current period)
+	CompareTo   string    // last day of the prior period
+	Compare     string    // calendar-aligned prior period: wow, mom, yoy or same-weekday
+	Now         time.Time // reference time (default: now UTC)
+
+	Location *time.Location // reporting timezone (default: UTC)
+	Hourly   bool           // the window is queried at HOURLY granularity
+}
+
+// Build resolves a Spec into current and prior periods. Relative and
+// absolute windows are compared with the same length of time immediately
+// before them; calendar periods with the previous month, quarter or year.
+func Build(spec Spec) (*Window, error) {
+	now := spec.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	loc := spec.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// Local midnight has always passed, but the UTC day carrying its date
+	// may not have when the timezone is ahead of UTC
+	today := Today(now, loc)
+	if spec.Hourly {
+		today = localDate(now, loc)
+	}
+
+	forms := 0
+	for _, set := range []bool{spec.Last != "", spec.From != "" || spec.To != "", spec.Period != ""} {
+		if set {
+			forms++
+		}
+	}
+	if forms > 1 {
+		return nil, fmt.Errorf("use only one of --last, --from/--to and --period")
+	}
+
+	var w *Window
+	var err error
+	switch {
+	case spec.Period != "":
+		w, err = calendarPeriod(spec.Period, today)
+	case spec.From != "" || spec.To != "":
+		w, err = absolute(spec.From, spec.To, today)
+	case spec.Last != "":
+		w, err = parseRelative(spec.Last, now, today)
+	default:
+		w, err = parseRelative("7d", now, today)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Compare != "" {
+		if spec.CompareFrom != "" || spec.CompareTo != "" {
+			return nil, fmt.Errorf("--compare cannot be combined with --compare-from/--compare-to")
+		}
+		if err := alignPrior(w, spec.Compare); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.CompareFrom != "" || spec.CompareTo != "" {
+		if spec.CompareFrom == "" || spec.CompareTo == "" {
+			return nil, fmt.Errorf("--compare-from and --compare-to must be set together")
+		}
+		w.PriorStart, w.PriorEnd, err = dateRange(spec.CompareFrom, spec.CompareTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid comparison period: %w", err)
+		}
+	}
+
+	w.Location = loc
+	if spec.Hourly && loc != time.UTC && !strings.HasSuffix(spec.Last, "h") {
+		localize(w, loc)
+	}
+	return w, nil
+}
+
+// absolute builds a window from inclusive dates, compared with the same
+// number of days before it
+func absolute(from, to string, today time.Time) (*Window, error) {
+	if from == "" {
+		return nil, fmt.Errorf("--to requires --from")
+	}
+	if to == "" {
+		to = today.AddDate(0, 0, -1).Format(dateLayout)
+	}
+
+	start, end, err := dateRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := end.Sub(start)
+	return &Window{
+		CurrentStart: start,
+		CurrentEnd:   end,
+		PriorStart:   start.Add(-duration),
+		PriorEnd:     start,
+		Duration:     duration,
+	}, nil
+}
+
+// calendarPeriod builds a calendar window. To-date periods end at the start
+// of today and are compared with the same number of days into the previous
+// month, quarter or year.
+func calendarPeriod(name string, today time.Time) (*Window, error) {
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var start, priorStart time.Time
+	switch strings.ToLower(name) {
+	case "last-month":
+		start = monthStart.AddDate(0, -1, 0)
+		return &Window{
+			CurrentStart: start,
+			CurrentEnd:   monthStart,
+			PriorStart:   start.AddDate(0, -1, 0),
+			PriorEnd:     start,
+			Duration:     monthStart.Sub(start),
+		}, nil
+	case "mtd":
+		start = monthStart
+		priorStart = start.AddDate(0, -1, 0)
+	case "qtd":
+		start = time.Date(today.Year(), time.Month((int(today.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+		priorStart = start.AddDate(0, -3, 0)
+	case "ytd":
+		start = time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		priorStart = start.AddDate(-1, 0, 0)
+	default:
+		return nil, fmt.Errorf("unsupported period: %s (expected one of %s)", name, strings.Join(Periods, ", "))
+	}
+
+	if !today.After(start) {
+		return nil, fmt.Errorf("%s has no complete days yet on %s", strings.ToLower(name), today.Format(dateLayout))
+	}
+
+	// Shorter prior months (e.g. March 30 vs February) stop at the period boundary
+	duration := today.Sub(start)
+	priorEnd := priorStart.Add(duration)
+	if priorEnd.After(start) {
+		priorEnd = start
+	}
+
+	return &Window{
+		CurrentStart: start,
+		CurrentEnd:   today,
+		PriorStart:   priorStart,
+		PriorEnd:     priorEnd,
+		Duration:     duration,
+	}, nil
+}
+
+// alignPrior replaces the prior period with the current period shifted back
+// by a calendar-aligned comparison mode
+func alignPrior(w *Window, mode string) error {
+	var shift func(time.Time) time.Time
+	switch strings.ToLower(mode) {
+	case "wow":
+		shift = func(t time.Time) time.Time { return t.AddDate(0, 0, -7) }
+	case "mom":
+		shift = func(t time.Time) time.Time { return addMonths(t, -1) }
+	case "yoy":
+		if w.CurrentStart.Equal(time.Date(w.CurrentStart.Year(), w.CurrentStart.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+			shift = func(t time.Time) time.Time { return addMonths(t, -12) }
+		} else {
+			shift = func(t time.Time) time.Time { return t.AddDate(0, 0, -7*52) }
+		}
+	case "same-weekday":
+		weeks := int(math.Ceil(w.CurrentDays() / 7))
+		shift = func(t time.Time) time.Time { return t.AddDate(0, 0, -7*weeks) }
+	default:
+		return fmt.Errorf("unsupported comparison: %s (expected one of %s)", mode, strings.Join(Comparisons, ", "))
+	}
+
+	w.PriorStart, w.PriorEnd = shift(w.CurrentStart), shift(w.CurrentEnd)
+	if w.PriorEnd.After(w.CurrentStart) {
+		return fmt.Errorf("--compare %s overlaps the %.0f-day current period; use a shorter window", strings.ToLower(mode), w.CurrentDays())
+	}
+	return nil
+}
+
+// addMonths moves t by months, clamping the day to the end of shorter
+// months (March 31 minus one month is February 28, not March 3)
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), 0, 0, time.UTC).AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// dateRange parses inclusive from/to dates into a [start, end) range
+func dateRange(from, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", from)
+	}
+	last, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", to)
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s is before %s", to, from)
+	}
+	return start, last.AddDate(0, 0, 1), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
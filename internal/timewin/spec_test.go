@@ -0,0 +1,158 @@
+package timewin
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse(dateLayout, s)
+	return t
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                                   string
+		spec                                   Spec
+		curStart, curEnd, priorStart, priorEnd string
+	}{
+		{"default last 7d", Spec{}, "2026-10-09", "2026-10-16", "2026-10-02", "2026-10-09"},
+		{"relative", Spec{Last: "30d"}, "2026-09-16", "2026-10-16", "2026-08-17", "2026-09-16"},
+		{"absolute inclusive", Spec{From: "2026-09-01", To: "2026-09-30"}, "2026-09-01", "2026-10-01", "2026-08-02", "2026-09-01"},
+		{"absolute open end", Spec{From: "2026-10-01"}, "2026-10-01", "2026-10-16", "2026-09-16", "2026-10-01"},
+		{"last month", Spec{Period: "last-month"}, "2026-09-01", "2026-10-01", "2026-08-01", "2026-09-01"},
+		{"month to date", Spec{Period: "mtd"}, "2026-10-01", "2026-10-16", "2026-09-01", "2026-09-16"},
+		{"quarter to date", Spec{Period: "QTD"}, "2026-10-01", "2026-10-16", "2026-07-01", "2026-07-16"},
+		{"year to date", Spec{Period: "ytd"}, "2026-01-01", "2026-10-16", "2025-01-01", "2025-10-16"},
+		{"explicit prior", Spec{Period: "last-month", CompareFrom: "2025-09-01", CompareTo: "2025-09-30"}, "2026-09-01", "2026-10-01", "2025-09-01", "2025-10-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Now = now
+			w, err := Build(tt.spec)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			got := []time.Time{w.CurrentStart, w.CurrentEnd, w.PriorStart, w.PriorEnd}
+			want := []string{tt.curStart, tt.curEnd, tt.priorStart, tt.priorEnd}
+			for i := range got {
+				if !got[i].Equal(date(want[i])) {
+					t.Errorf("Build() = %v - %v vs %v - %v, want %v", w.CurrentStart, w.CurrentEnd, w.PriorStart, w.PriorEnd, want)
+					break
+				}
+			}
+			if w.Duration != w.CurrentEnd.Sub(w.CurrentStart) {
+				t.Errorf("Duration = %v, want current period length", w.Duration)
+			}
+		})
+	}
+}
+
+func TestBuild_PriorClampedToPeriodStart(t *testing.T) {
+	// March 31 month-to-date is 30 days; February only has 28
+	w, err := Build(Spec{Period: "mtd", Now: time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !w.PriorStart.Equal(date("2026-02-01")) || !w.PriorEnd.Equal(date("2026-03-01")) {
+		t.Errorf("prior = %v - %v, want all of February", w.PriorStart, w.PriorEnd)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	now := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec Spec
+	}{
+		{"conflicting forms", Spec{Last: "7d", Period: "mtd"}},
+		{"to without from", Spec{To: "2026-09-30"}},
+		{"reversed dates", Spec{From: "2026-09-30", To: "2026-09-01"}},
+		{"bad date", Spec{From: "09/01/2026"}},
+		{"unknown period", Spec{Period: "fortnight"}},
+		{"empty to-date period", Spec{Period: "mtd"}},
+		{"half a comparison", Spec{Last: "7d", CompareFrom: "2026-01-01"}},
+		{"bad comparison", Spec{Last: "7d", CompareFrom: "2026-01-31", CompareTo: "2026-01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Now = now
+			if _, err := Build(tt.spec); err == nil {
+				t.Error("Build() should fail")
+			}
+		})
+	}
+}
+
+func TestBuild_Compare(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC) // a Friday
+
+	tests := []struct {
+		name                 string
+		spec                 Spec
+		priorStart, priorEnd string
+		uneven               bool
+		sameWeekday          bool
+	}{
+		{"week over week", Spec{Last: "7d", Compare: "wow"}, "2026-10-02", "2026-10-09", false, true},
+		{"month over month", Spec{Period: "last-month", Compare: "mom"}, "2026-08-01", "2026-09-01", true, false},
+		{"month to date over month", Spec{Period: "mtd", Compare: "MoM"}, "2026-09-01", "2026-09-16", false, false},
+		{"year over year by month", Spec{Period: "mtd", Compare: "yoy"}, "2025-10-01", "2025-10-16", false, false},
+		{"year over year by weekday", Spec{Last: "10d", Compare: "yoy"}, "2025-10-07", "2025-10-17", false, true},
+		{"same weekday", Spec{Last: "10d", Compare: "same-weekday"}, "2026-09-22", "2026-10-02", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Now = now
+			w, err := Build(tt.spec)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !w.PriorStart.Equal(date(tt.priorStart)) || !w.PriorEnd.Equal(date(tt.priorEnd)) {
+				t.Errorf("prior = %v - %v, want %s - %s", w.PriorStart, w.PriorEnd, tt.priorStart, tt.priorEnd)
+			}
+			if tt.sameWeekday && w.PriorStart.Weekday() != w.CurrentStart.Weekday() {
+				t.Errorf("prior starts on %v, current on %v", w.PriorStart.Weekday(), w.CurrentStart.Weekday())
+			}
+			if w.Uneven() != tt.uneven {
+				t.Errorf("Uneven() = %v, want %v (%v vs %v days)", w.Uneven(), tt.uneven, w.CurrentDays(), w.PriorDays())
+			}
+		})
+	}
+
+	for _, spec := range []Spec{
+		{Last: "30d", Compare: "wow"},
+		{Last: "7d", Compare: "qoq"},
+		{Last: "7d", Compare: "wow", CompareFrom: "2026-01-01", CompareTo: "2026-01-07"},
+	} {
+		spec.Now = now
+		if _, err := Build(spec); err == nil {
+			t.Errorf("Build(%+v) should fail", spec)
+		}
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		in     string
+		months int
+		want   string
+	}{
+		{"2026-03-31", -1, "2026-02-28"},
+		{"2026-10-01", -1, "2026-09-01"},
+		{"2024-02-29", -12, "2023-02-28"},
+		{"2026-01-15", -1, "2025-12-15"},
+	}
+
+	for _, tt := range tests {
+		if got := addMonths(date(tt.in), tt.months); !got.Equal(date(tt.want)) {
+			t.Errorf("addMonths(%s, %d) = %v, want %s", tt.in, tt.months, got, tt.want)
+		}
+	}
+}
@@ -14,11 +14,24 @@ type Window struct {
 	PriorStart   time.Time
 	PriorEnd     time.Time
 	Duration     time.Duration
+
+	// Location is the timezone the window is reported in (nil means UTC).
+	// Boundaries are UTC days, as Cost Explorer buckets daily costs, unless
+	// LocalDays is set for hourly data.
+	Location  *time.Location
+	LocalDays bool
 }
 
 // Parse parses a duration string like "7d", "48h", "30d" and returns current/prior windows
 // The current period is the most recent duration, prior is the same duration before that
 func Parse(s string) (*Window, error) {
+	now := time.Now().UTC()
+	return parseRelative(s, now, startOfDay(now))
+}
+
+// parseRelative parses a duration string into windows ending at now, or at
+// the start of today for whole days
+func parseRelative(s string, now, today time.Time) (*Window, error) {
 	re := regexp.MustCompile(`^(\d+)([hd])$`)
 	matches := re.FindStringSubmatch(s)
 	if matches == nil {
@@ -43,14 +56,13 @@ func Parse(s string) (*Window, error) {
 
 	// Current period ends now (truncated to start of hour or day for consistency)
 	// Prior period is the same duration before current period
-	now := time.Now().UTC()
 
 	// Truncate based on unit for cleaner boundaries
 	var currentEnd time.Time
 	if unit == "h" {
 		currentEnd = now.Truncate(time.Hour)
 	} else {
-		currentEnd = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		currentEnd = today
 	}
 
 	currentStart := currentEnd.Add(-duration)
@@ -77,3 +89,19 @@ func (w *Window) IncludesToday() bool {
 	today := time.Now().UTC().Truncate(24 * time.Hour)
 	return w.CurrentEnd.After(today)
 }
+
+// CurrentDays returns the length of the current period in days
+func (w *Window) CurrentDays() float64 {
+	return w.CurrentEnd.Sub(w.CurrentStart).Hours() / 24
+}
+
+// PriorDays returns the length of the prior period in days
+func (w *Window) PriorDays() float64 {
+	return w.PriorEnd.Sub(w.PriorStart).Hours() / 24
+}
+
+// Uneven reports whether the current and prior periods differ in length, so
+// totals must be compared as per-day rates
+func (w *Window) Uneven() bool {
+	return w.CurrentEnd.Sub(w.CurrentStart) != w.PriorEnd.Sub(w.PriorStart)
+}
@@ -0,0 +1,87 @@
+package timewin
+
+import (
+	"fmt"
+	"time"
+)
+
+// Today returns the start of the current day in loc as a UTC day, which is
+// how Cost Explorer buckets daily costs. When loc is ahead of UTC and the UTC
+// day with that date has not started yet, the current UTC day is used.
+func Today(now time.Time, loc *time.Location) time.Time {
+	today := localDate(now, loc)
+	if utc := startOfDay(now); today.After(utc) {
+		return utc
+	}
+	return today
+}
+
+// localDate returns the date of now in loc as a UTC midnight
+func localDate(now time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// localize moves the window's UTC-day boundaries to midnight in loc. Cost
+// Explorer only has whole UTC hours, so zones with a half-hour offset start
+// at the hour before midnight.
+func localize(w *Window, loc *time.Location) {
+	shift := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).UTC().Truncate(time.Hour)
+	}
+	w.CurrentStart, w.CurrentEnd = shift(w.CurrentStart), shift(w.CurrentEnd)
+	w.PriorStart, w.PriorEnd = shift(w.PriorStart), shift(w.PriorEnd)
+	w.Duration = w.CurrentEnd.Sub(w.CurrentStart)
+	w.LocalDays = true
+}
+
+// location returns the reporting timezone of the window
+func (w *Window) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
+
+// Label formats a [start, end) range of the window for reports: inclusive
+// dates when it covers whole days, otherwise times in the reporting timezone
+func (w *Window) Label(start, end time.Time) string {
+	zone := time.UTC
+	if w.LocalDays {
+		zone = w.location()
+	}
+	s, e := start.In(zone), end.In(zone)
+	if isMidnight(s) && isMidnight(e) {
+		return fmt.Sprintf("%s → %s", s.Format(dateLayout), e.AddDate(0, 0, -1).Format(dateLayout))
+	}
+
+	loc := w.location()
+	return fmt.Sprintf("%s → %s", start.In(loc).Format("2006-01-02 15:04 MST"), end.In(loc).Format("2006-01-02 15:04 MST"))
+}
+
+// Bucketing says how the window's days line up with the reporting timezone
+func (w *Window) Bucketing() string {
+	if w.LocalDays {
+		return fmt.Sprintf("%s days from hourly data", w.location())
+	}
+	if !isMidnight(w.CurrentStart.UTC()) || !isMidnight(w.CurrentEnd.UTC()) {
+		return "hours"
+	}
+	if w.location() == time.UTC {
+		return "UTC days"
+	}
+	return fmt.Sprintf("UTC days, as bucketed by AWS; reporting timezone %s", w.location())
+}
+
+// Describe summarizes both periods and their bucketing in one line
+func (w *Window) Describe() string {
+	return fmt.Sprintf("Current: %s, prior: %s (%s)",
+		w.Label(w.CurrentStart, w.CurrentEnd), w.Label(w.PriorStart, w.PriorEnd), w.Bucketing())
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
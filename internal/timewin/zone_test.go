@@ -0,0 +1,99 @@
+package timewin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestToday(t *testing.T) {
+	la := mustLoad(t, "America/Los_Angeles")
+	tokyo := mustLoad(t, "Asia/Tokyo")
+
+	tests := []struct {
+		name string
+		now  time.Time
+		loc  *time.Location
+		want string
+	}{
+		{"utc", time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), time.UTC, "2026-10-16"},
+		{"behind utc, still yesterday locally", time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), la, "2026-10-15"},
+		{"behind utc, same day", time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC), la, "2026-10-16"},
+		{"ahead of utc, utc day not started", time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC), tokyo, "2026-10-15"},
+		{"nil location", time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), nil, "2026-10-16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Today(tt.now, tt.loc); !got.Equal(date(tt.want)) {
+				t.Errorf("Today() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild_Location(t *testing.T) {
+	la := mustLoad(t, "America/Los_Angeles")
+	// 19:00 PDT on October 15
+	now := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+
+	// Daily windows stay on UTC days but end before the local today
+	w, err := Build(Spec{Last: "7d", Now: now, Location: la})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !w.CurrentStart.Equal(date("2026-10-08")) || !w.CurrentEnd.Equal(date("2026-10-15")) || w.LocalDays {
+		t.Errorf("daily window = %v - %v (local days %v), want UTC days 2026-10-08 - 2026-10-15", w.CurrentStart, w.CurrentEnd, w.LocalDays)
+	}
+	if got := w.Describe(); !strings.Contains(got, "2026-10-08 → 2026-10-14") || !strings.Contains(got, "UTC days, as bucketed by AWS") {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	// Hourly windows run from local midnight to local midnight
+	w, err = Build(Spec{Last: "2d", Now: now, Location: la, Hourly: true})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	wantStart := time.Date(2026, 10, 13, 7, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC)
+	if !w.CurrentStart.Equal(wantStart) || !w.CurrentEnd.Equal(wantEnd) || !w.PriorEnd.Equal(wantStart) {
+		t.Errorf("hourly window = %v - %v, want %v - %v", w.CurrentStart, w.CurrentEnd, wantStart, wantEnd)
+	}
+	if got := w.Label(w.CurrentStart, w.CurrentEnd); got != "2026-10-13 → 2026-10-14" {
+		t.Errorf("Label() = %q, want local dates", got)
+	}
+
+	// Relative hours are absolute and only relabeled
+	w, err = Build(Spec{Last: "48h", Now: now, Location: la, Hourly: true})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !w.CurrentEnd.Equal(now) || w.LocalDays {
+		t.Errorf("48h window should end at %v, got %v", now, w.CurrentEnd)
+	}
+	if got := w.Label(w.CurrentStart, w.CurrentEnd); !strings.Contains(got, "2026-10-15 19:00 PDT") {
+		t.Errorf("Label() = %q, want local times", got)
+	}
+}
+
+func TestBuild_LocationDST(t *testing.T) {
+	la := mustLoad(t, "America/Los_Angeles")
+
+	// The week containing the November DST change has 169 hours
+	w, err := Build(Spec{From: "2026-11-01", To: "2026-11-07", Now: time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC), Location: la, Hourly: true})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if w.Duration != 169*time.Hour || !w.Uneven() {
+		t.Errorf("Duration = %v, want 169h compared per day with the prior week", w.Duration)
+	}
+}
@@ -0,0 +1,60 @@
+package waste
+
+import "github.com/pfrederiksen/cost-blame/internal/inventory"
+
+// HoursPerMonth is the month AWS bills hourly resources by
+const HoursPerMonth = 730
+
+// Price is a list price in USD, per hour or, for storage, per GiB-month
+type Price struct {
+	USD         float64
+	PerGiBMonth bool
+}
+
+// Prices is the bundled price table: us-east-1 on-demand list prices,
+// keyed by resource type and, for storage, by type/volume type or tier.
+// Other regions are usually within a few percent.
+var Prices = map[string]Price{
+	"EBS Volume/gp2":            {USD: 0.10, PerGiBMonth: true},
+	"EBS Volume/gp3":            {USD: 0.08, PerGiBMonth: true},
+	"EBS Volume/io1":            {USD: 0.125, PerGiBMonth: true},
+	"EBS Volume/io2":            {USD: 0.125, PerGiBMonth: true},
+	"EBS Volume/st1":            {USD: 0.045, PerGiBMonth: true},
+	"EBS Volume/sc1":            {USD: 0.015, PerGiBMonth: true},
+	"EBS Volume/standard":       {USD: 0.05, PerGiBMonth: true},
+	"EBS Snapshot/standard":     {USD: 0.05, PerGiBMonth: true},
+	"EBS Snapshot/archive":      {USD: 0.0125, PerGiBMonth: true},
+	inventory.TypeElasticIP:     {USD: 0.005},
+	inventory.TypeNATGateway:    {USD: 0.045},
+	inventory.TypeApplicationLB: {USD: 0.0225},
+	inventory.TypeNetworkLB:     {USD: 0.0225},
+	inventory.TypeGatewayLB:     {USD: 0.0125},
+	inventory.TypeClassicLB:     {USD: 0.025},
+	inventory.TypeEKSCluster:    {USD: 0.10},
+}
+
+// MonthlyCost estimates the monthly cost of a resource from the price
+// table, before usage charges such as data processed. ok is false when the
+// table has no price for it.
+func MonthlyCost(r inventory.Resource) (cost float64, ok bool) {
+	key := r.Type
+	switch r.Type {
+	case inventory.TypeEBSVolume:
+		key += "/" + r.Attributes["volume_type"]
+	case inventory.TypeEBSSnapshot:
+		tier := r.Attributes["storage_tier"]
+		if tier == "" {
+			tier = "standard"
+		}
+		key += "/" + tier
+	}
+
+	price, ok := Prices[key]
+	if !ok {
+		return 0, false
+	}
+	if price.PerGiBMonth {
+		return price.USD * float64(attrInt(r, "size_gib")), true
+	}
+	return price.USD * HoursPerMonth, true
+}
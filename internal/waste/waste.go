@@ -0,0 +1,207 @@
+package waste
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+)
+
+// Reason codes of waste items
+const (
+	UnattachedVolume = "unattached-volume"  // EBS volume not attached to any instance
+	StoppedInstance  = "stopped-instance"   // stopped instance whose volumes are still billed
+	UnassociatedEIP  = "unassociated-eip"   // Elastic IP not associated with anything
+	OrphanedSnapshot = "orphaned-snapshot"  // old snapshot whose source volume is gone and no AMI uses
+	IdleNATGateway   = "idle-nat-gateway"   // NAT gateway no route table uses
+	IdleLoadBalancer = "idle-load-balancer" // load balancer without targets
+	EmptyECSCluster  = "empty-ecs-cluster"  // ECS cluster without services or tasks
+	EmptyEKSCluster  = "empty-eks-cluster"  // EKS cluster without node groups, Fargate profiles or self-managed nodes
+)
+
+// copiedVolumeID is the placeholder volume ID of snapshots made by copying
+// another snapshot
+const copiedVolumeID = "vol-ffffffff"
+
+// DefaultSnapshotAge is how old a snapshot must be to be reported as
+// orphaned
+const DefaultSnapshotAge = 90 * 24 * time.Hour
+
+// Item is a resource paid for but not used
+type Item struct {
+	Reason      string             `json:"reason"`
+	Detail      string             `json:"detail"`
+	MonthlyCost float64            `json:"monthly_cost"`          // estimated from the price table, in USD
+	UpperBound  bool               `json:"upper_bound,omitempty"` // the actual cost is likely lower
+	Resource    inventory.Resource `json:"resource"`
+}
+
+// Options tune the rules
+type Options struct {
+	Now         time.Time
+	SnapshotAge time.Duration
+
+	// Partial marks resources from an incomplete listing. Rules relying on
+	// a resource being absent, such as orphaned snapshots and empty EKS
+	// clusters, are skipped.
+	Partial bool
+}
+
+// Find applies the waste rules to the resources of one account and region,
+// largest monthly cost first
+func Find(resources []inventory.Resource, opts Options) []Item {
+	volumes := make(map[string]bool)
+	attached := make(map[string][]inventory.Resource)
+	eksNodes := make(map[string]bool)
+	for _, r := range resources {
+		switch r.Type {
+		case inventory.TypeEBSVolume:
+			volumes[r.ID] = true
+			if id := r.Attributes["instance_id"]; id != "" {
+				attached[id] = append(attached[id], r)
+			}
+		case inventory.TypeEC2Instance:
+			if r.Attributes["state"] != "terminated" {
+				for _, cluster := range eksClusters(r.Tags) {
+					eksNodes[cluster] = true
+				}
+			}
+		}
+	}
+
+	var items []Item
+	add := func(r inventory.Resource, reason, detail string) {
+		cost, _ := MonthlyCost(r)
+		items = append(items, Item{Reason: reason, Detail: detail, MonthlyCost: cost, Resource: r})
+	}
+
+	for _, r := range resources {
+		attrs := r.Attributes
+		switch r.Type {
+		case inventory.TypeEBSVolume:
+			if attrs["state"] == "available" {
+				add(r, UnattachedVolume, fmt.Sprintf("%s GiB %s volume not attached to any instance", attrs["size_gib"], attrs["volume_type"]))
+			}
+
+		case inventory.TypeEC2Instance:
+			vols := attached[r.ID]
+			if attrs["state"] != "stopped" || len(vols) == 0 {
+				continue
+			}
+			cost, size := 0.0, 0
+			for _, v := range vols {
+				c, _ := MonthlyCost(v)
+				cost += c
+				size += attrInt(v, "size_gib")
+			}
+			items = append(items, Item{
+				Reason:      StoppedInstance,
+				Detail:      fmt.Sprintf("stopped with %d volumes (%d GiB) still billed", len(vols), size),
+				MonthlyCost: cost,
+				Resource:    r,
+			})
+
+		case inventory.TypeElasticIP:
+			if attrs["state"] == "unassociated" {
+				add(r, UnassociatedEIP, fmt.Sprintf("%s not associated with any instance or interface", attrs["public_ip"]))
+			}
+
+		case inventory.TypeEBSSnapshot:
+			// Snapshots backing an AMI can't be deleted before it
+			if opts.Partial || volumes[attrs["volume_id"]] || attrs["image_id"] != "" {
+				continue
+			}
+			started, err := time.Parse("2006-01-02", attrs["started"])
+			if err != nil || opts.Now.Sub(started) < opts.SnapshotAge {
+				continue
+			}
+			days := int(opts.Now.Sub(started).Hours() / 24)
+			detail := fmt.Sprintf("source volume %s no longer exists; %d days old", attrs["volume_id"], days)
+			if attrs["volume_id"] == copiedVolumeID {
+				detail = fmt.Sprintf("copied from another snapshot, possibly in another region; %d days old", days)
+			}
+
+			// Snapshots are billed for the blocks they store, which the
+			// volume size only bounds
+			add(r, OrphanedSnapshot, detail+"; cost assumes every block of the volume is stored")
+			items[len(items)-1].UpperBound = true
+
+		case inventory.TypeNATGateway:
+			if attrs["state"] == "available" && attrs["routes"] == "0" {
+				add(r, IdleNATGateway, "no route table sends traffic through it")
+			}
+
+		case inventory.TypeApplicationLB, inventory.TypeNetworkLB, inventory.TypeGatewayLB:
+			if attrs["targets"] == "0" {
+				add(r, IdleLoadBalancer, "no registered targets")
+			}
+
+		case inventory.TypeClassicLB:
+			if attrs["instances"] == "0" {
+				add(r, IdleLoadBalancer, "no registered instances")
+			}
+
+		case inventory.TypeECSCluster:
+			if attrs["active_services"] == "0" && attrs["running_tasks"] == "0" {
+				add(r, EmptyECSCluster, fmt.Sprintf("no services or running tasks; %s container instances", attrs["container_instances"]))
+			}
+
+		case inventory.TypeEKSCluster:
+			if opts.Partial || eksNodes[r.ID] {
+				continue
+			}
+			if attrs["nodegroups"] == "0" && attrs["fargate_profiles"] == "0" {
+				add(r, EmptyEKSCluster, "no node groups, Fargate profiles or instances tagged as its nodes")
+			}
+		}
+	}
+
+	Sort(items)
+	return items
+}
+
+// Sort orders items by monthly cost, largest first, then by reason and ID
+func Sort(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].MonthlyCost != items[j].MonthlyCost {
+			return items[i].MonthlyCost > items[j].MonthlyCost
+		}
+		if items[i].Reason != items[j].Reason {
+			return items[i].Reason < items[j].Reason
+		}
+		return items[i].Resource.ID < items[j].Resource.ID
+	})
+}
+
+// Total sums the monthly cost of items
+func Total(items []Item) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.MonthlyCost
+	}
+	return total
+}
+
+// eksClusters returns the EKS clusters an instance is a node of, from the
+// tags managed node groups, Karpenter and self-managed node groups set
+func eksClusters(tags map[string]string) []string {
+	var clusters []string
+	for k, v := range tags {
+		switch {
+		case strings.HasPrefix(k, "kubernetes.io/cluster/"):
+			clusters = append(clusters, strings.TrimPrefix(k, "kubernetes.io/cluster/"))
+		case k == "eks:cluster-name", k == "aws:eks:cluster-name", k == "eks:eks-cluster-name":
+			clusters = append(clusters, v)
+		}
+	}
+	return clusters
+}
+
+// attrInt reads a numeric attribute, 0 when missing
+func attrInt(r inventory.Resource, key string) int {
+	n, _ := strconv.Atoi(r.Attributes[key])
+	return n
+}
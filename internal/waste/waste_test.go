@@ -0,0 +1,132 @@
+package waste
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pfrederiksen/cost-blame/internal/inventory"
+)
+
+func res(id, typ string, attrs map[string]string) inventory.Resource {
+	return inventory.Resource{ID: id, Type: typ, Attributes: attrs}
+}
+
+func TestMonthlyCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource inventory.Resource
+		want     float64
+		ok       bool
+	}{
+		{"gp3 volume", res("vol-1", inventory.TypeEBSVolume, map[string]string{"volume_type": "gp3", "size_gib": "500"}), 40, true},
+		{"standard snapshot", res("snap-1", inventory.TypeEBSSnapshot, map[string]string{"size_gib": "100"}), 5, true},
+		{"archived snapshot", res("snap-2", inventory.TypeEBSSnapshot, map[string]string{"size_gib": "100", "storage_tier": "archive"}), 1.25, true},
+		{"NAT gateway", res("nat-1", inventory.TypeNATGateway, nil), 32.85, true},
+		{"Elastic IP", res("eipalloc-1", inventory.TypeElasticIP, nil), 3.65, true},
+		{"unpriced", res("i-1", inventory.TypeEC2Instance, nil), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MonthlyCost(tt.resource)
+			if ok != tt.ok || math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("MonthlyCost() = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	resources := []inventory.Resource{
+		res("vol-free", inventory.TypeEBSVolume, map[string]string{"state": "available", "volume_type": "gp2", "size_gib": "100"}),
+		res("vol-root", inventory.TypeEBSVolume, map[string]string{"state": "in-use", "volume_type": "gp3", "size_gib": "50", "instance_id": "i-stopped"}),
+		res("vol-data", inventory.TypeEBSVolume, map[string]string{"state": "in-use", "volume_type": "gp3", "size_gib": "150", "instance_id": "i-stopped"}),
+		res("vol-live", inventory.TypeEBSVolume, map[string]string{"state": "in-use", "volume_type": "gp3", "size_gib": "50", "instance_id": "i-running"}),
+		res("i-stopped", inventory.TypeEC2Instance, map[string]string{"state": "stopped"}),
+		res("i-running", inventory.TypeEC2Instance, map[string]string{"state": "running"}),
+		res("i-bare", inventory.TypeEC2Instance, map[string]string{"state": "stopped"}),
+		res("eipalloc-idle", inventory.TypeElasticIP, map[string]string{"state": "unassociated", "public_ip": "203.0.113.1"}),
+		res("eipalloc-used", inventory.TypeElasticIP, map[string]string{"state": "associated"}),
+		res("snap-orphan", inventory.TypeEBSSnapshot, map[string]string{"volume_id": "vol-gone", "size_gib": "200", "started": "2026-01-01"}),
+		res("snap-recent", inventory.TypeEBSSnapshot, map[string]string{"volume_id": "vol-gone", "size_gib": "200", "started": "2026-09-20"}),
+		res("snap-kept", inventory.TypeEBSSnapshot, map[string]string{"volume_id": "vol-root", "size_gib": "50", "started": "2025-01-01"}),
+		res("snap-ami", inventory.TypeEBSSnapshot, map[string]string{"volume_id": "vol-gone", "size_gib": "200", "started": "2026-01-01", "image_id": "ami-1"}),
+		res("snap-copy", inventory.TypeEBSSnapshot, map[string]string{"volume_id": "vol-ffffffff", "size_gib": "20", "started": "2026-01-01"}),
+		res("nat-idle", inventory.TypeNATGateway, map[string]string{"state": "available", "routes": "0"}),
+		res("nat-used", inventory.TypeNATGateway, map[string]string{"state": "available", "routes": "2"}),
+		res("nat-deleted", inventory.TypeNATGateway, map[string]string{"state": "deleted", "routes": "0"}),
+		res("alb-idle", inventory.TypeApplicationLB, map[string]string{"targets": "0"}),
+		res("nlb-unknown", inventory.TypeNetworkLB, map[string]string{}),
+		res("clb-idle", inventory.TypeClassicLB, map[string]string{"instances": "0"}),
+		res("ecs-empty", inventory.TypeECSCluster, map[string]string{"active_services": "0", "running_tasks": "0", "container_instances": "0"}),
+		res("ecs-busy", inventory.TypeECSCluster, map[string]string{"active_services": "3", "running_tasks": "9"}),
+		res("eks-empty", inventory.TypeEKSCluster, map[string]string{"nodegroups": "0", "fargate_profiles": "0"}),
+		res("eks-karpenter", inventory.TypeEKSCluster, map[string]string{"nodegroups": "0", "fargate_profiles": "0"}),
+		res("eks-self-managed", inventory.TypeEKSCluster, map[string]string{"nodegroups": "0", "fargate_profiles": "0"}),
+		res("eks-gone-nodes", inventory.TypeEKSCluster, map[string]string{"nodegroups": "0", "fargate_profiles": "0"}),
+		{ID: "i-karpenter", Type: inventory.TypeEC2Instance, Attributes: map[string]string{"state": "running"},
+			Tags: map[string]string{"eks:eks-cluster-name": "eks-karpenter"}},
+		{ID: "i-node", Type: inventory.TypeEC2Instance, Attributes: map[string]string{"state": "running"},
+			Tags: map[string]string{"kubernetes.io/cluster/eks-self-managed": "owned"}},
+		{ID: "i-terminated", Type: inventory.TypeEC2Instance, Attributes: map[string]string{"state": "terminated"},
+			Tags: map[string]string{"kubernetes.io/cluster/eks-gone-nodes": "owned"}},
+	}
+
+	got := Find(resources, Options{Now: now, SnapshotAge: DefaultSnapshotAge})
+
+	want := []struct {
+		id, reason string
+		cost       float64
+	}{
+		{"eks-empty", EmptyEKSCluster, 73},
+		{"eks-gone-nodes", EmptyEKSCluster, 73},
+		{"nat-idle", IdleNATGateway, 32.85},
+		{"clb-idle", IdleLoadBalancer, 18.25},
+		{"alb-idle", IdleLoadBalancer, 16.425},
+		{"i-stopped", StoppedInstance, 16},
+		{"snap-orphan", OrphanedSnapshot, 10},
+		{"vol-free", UnattachedVolume, 10},
+		{"eipalloc-idle", UnassociatedEIP, 3.65},
+		{"snap-copy", OrphanedSnapshot, 1},
+		{"ecs-empty", EmptyECSCluster, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Find() returned %d items, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Resource.ID != w.id || got[i].Reason != w.reason || math.Abs(got[i].MonthlyCost-w.cost) > 0.001 {
+			t.Errorf("item %d = %s %s %.3f, want %s %s %.3f", i,
+				got[i].Resource.ID, got[i].Reason, got[i].MonthlyCost, w.id, w.reason, w.cost)
+		}
+	}
+	for _, item := range got {
+		if item.Resource.ID == "snap-copy" && !strings.Contains(item.Detail, "copied") {
+			t.Errorf("snap-copy detail = %q, want it flagged as a copy", item.Detail)
+		}
+		if item.UpperBound != (item.Reason == OrphanedSnapshot) {
+			t.Errorf("%s upper bound = %v, want it only for snapshots", item.Resource.ID, item.UpperBound)
+		}
+	}
+	if total := Total(got); math.Abs(total-254.175) > 0.001 {
+		t.Errorf("Total() = %.3f, want 254.175", total)
+	}
+}
+
+func TestFind_Partial(t *testing.T) {
+	// Without a complete volume and instance listing every snapshot would
+	// look orphaned and every cluster without node groups empty
+	resources := []inventory.Resource{
+		res("snap-1", inventory.TypeEBSSnapshot, map[string]string{"volume_id": "vol-1", "size_gib": "10", "started": "2020-01-01"}),
+		res("eks-1", inventory.TypeEKSCluster, map[string]string{"nodegroups": "0", "fargate_profiles": "0"}),
+	}
+
+	if got := Find(resources, Options{Now: time.Now(), Partial: true}); len(got) != 0 {
+		t.Errorf("Find() with a partial listing = %+v, want no orphaned snapshots or empty clusters", got)
+	}
+	if got := Find(resources, Options{Now: time.Now()}); len(got) != 2 {
+		t.Errorf("Find() = %+v, want the orphaned snapshot and empty cluster", got)
+	}
+}
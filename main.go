@@ -1,6 +1,11 @@
 package main
 
-import "github.com/pfrederiksen/cost-blame/cmd"
+import (
+	// Embedded zone data keeps --timezone working where the OS has none
+	_ "time/tzdata"
+
+	"github.com/pfrederiksen/cost-blame/cmd"
+)
 
 func main() {
 	cmd.Execute()